@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/carpentry-hub/woodys-backend/config"
+	"github.com/carpentry-hub/woodys-backend/db"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/taxonomy"
+)
+
+const taxonomyUsage = `usage: woodys-backend taxonomy <command>
+
+commands:
+  backfill [--dry-run]  rewrite project tools, materials, styles and environments as taxonomy
+                        slugs and report the values that do not map to any term`
+
+// runTaxonomy ejecuta los subcomandos de "taxonomy" y termina el proceso
+func runTaxonomy(cfg *config.Config, args []string) {
+	if len(args) == 0 || args[0] != "backfill" || len(args) > 2 || len(args) == 2 && args[1] != "--dry-run" {
+		log.Fatal(taxonomyUsage)
+	}
+	dryRun := len(args) == 2
+
+	database, err := db.Connection(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	repos := repositories.NewGorm(database)
+
+	ctx := context.Background()
+	terms, err := repos.Taxonomy.List(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load taxonomy: %v", err)
+	}
+	if len(terms) == 0 {
+		log.Fatal("The taxonomy is empty, run the migrations first")
+	}
+
+	// los cambios del backfill quedan en el historial como revisiones sin autor
+	revision := repositories.Revision{
+		Retention: repositories.RevisionRetention{Keep: cfg.Revisions.Keep, MaxAge: cfg.Revisions.MaxAge},
+	}
+	report, err := taxonomy.Backfill(ctx, repos.Projects, taxonomy.NewIndex(terms), revision, dryRun)
+	report.Write(os.Stdout)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+	if dryRun {
+		fmt.Println("dry run: nothing was written")
+	}
+}
@@ -3,12 +3,22 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Auth      AuthConfig
+	Ratings   RatingsConfig
+	Paging    PagingConfig
+	Pricing   PricingConfig
+	Markdown  MarkdownConfig
+	Uploads   UploadsConfig
+	Revisions RevisionsConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -26,6 +36,74 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// AuthConfig holds Firebase authentication configuration
+type AuthConfig struct {
+	FirebaseProjectID string
+	CertsURL          string
+	CertsCacheTTL     time.Duration
+}
+
+// RatingsConfig holds the parameters used to rank projects by rating
+type RatingsConfig struct {
+	// PriorWeight is how many site-average votes are blended into each project's Bayesian score
+	PriorWeight float64
+}
+
+// PagingConfig holds list pagination configuration
+type PagingConfig struct {
+	// CursorSecret signs pagination cursors; when empty a random key is used and cursors
+	// stop working across restarts and between instances
+	CursorSecret string
+}
+
+// PricingConfig holds material cost estimation configuration
+type PricingConfig struct {
+	// DefaultCurrency is the ISO 4217 currency of project estimates when the request does not ask for one
+	DefaultCurrency string
+}
+
+// MarkdownConfig holds the rendering options of user-written Markdown
+type MarkdownConfig struct {
+	// ImageHosts are the hosts whose https images may appear in rendered Markdown, besides our own paths
+	ImageHosts []string
+}
+
+// UploadsConfig holds image upload limits and the storage backend
+type UploadsConfig struct {
+	// Storage is "filesystem" or "s3"
+	Storage string
+	// Dir is where the filesystem storage keeps the files
+	Dir string
+	// BaseURL prefixes upload ids in the URLs returned and accepted by the API
+	BaseURL   string
+	MaxBytes  int64
+	UserQuota int64
+	// Workers is how many images are resized at the same time in the background
+	Workers int
+	S3      S3Config
+}
+
+// RevisionsConfig holds how much project history is kept. Zero means no limit; the latest revision
+// of a project is always kept.
+type RevisionsConfig struct {
+	// Keep is how many revisions are kept per project
+	Keep int
+	// MaxAge is how long older revisions are kept, checked whenever a project is saved
+	MaxAge time.Duration
+}
+
+// S3Config holds the bucket used by the S3-compatible storage
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket in the path instead of the host name, as MinIO needs
+	PathStyle bool
+	Prefix    string
+}
+
 // Load loads configuration from environment variables
 func Load() *Config {
 	return &Config{
@@ -40,6 +118,47 @@ func Load() *Config {
 			Port:     getEnv("DB_PORT", "5432"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
+		Auth: AuthConfig{
+			FirebaseProjectID: getEnv("FIREBASE_PROJECT_ID", ""),
+			CertsURL: getEnv(
+				"FIREBASE_CERTS_URL",
+				"https://www.googleapis.com/robot/v1/metadata/x509/securetoken@system.gserviceaccount.com",
+			),
+			CertsCacheTTL: getEnvDuration("FIREBASE_CERTS_TTL", time.Hour),
+		},
+		Ratings: RatingsConfig{
+			PriorWeight: getEnvFloat("RATING_PRIOR_WEIGHT", 10),
+		},
+		Paging: PagingConfig{
+			CursorSecret: getEnv("PAGINATION_CURSOR_SECRET", ""),
+		},
+		Pricing: PricingConfig{
+			DefaultCurrency: getEnv("PRICING_DEFAULT_CURRENCY", "USD"),
+		},
+		Markdown: MarkdownConfig{
+			ImageHosts: getEnvList("MARKDOWN_IMAGE_HOSTS"),
+		},
+		Uploads: UploadsConfig{
+			Storage:   getEnv("UPLOAD_STORAGE", "filesystem"),
+			Dir:       getEnv("UPLOAD_DIR", "data/uploads"),
+			BaseURL:   getEnv("UPLOAD_BASE_URL", "/uploads"),
+			MaxBytes:  getEnvInt64("UPLOAD_MAX_BYTES", 10<<20),
+			UserQuota: getEnvInt64("UPLOAD_USER_QUOTA", 200<<20),
+			Workers:   int(getEnvInt64("UPLOAD_WORKERS", 2)),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", ""),
+				AccessKey: getEnv("S3_ACCESS_KEY_ID", ""),
+				SecretKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+				PathStyle: getEnvBool("S3_PATH_STYLE", false),
+				Prefix:    getEnv("S3_PREFIX", ""),
+			},
+		},
+		Revisions: RevisionsConfig{
+			Keep:   int(getEnvInt64("PROJECT_REVISIONS_KEEP", 50)),
+			MaxAge: getEnvDuration("PROJECT_REVISIONS_MAX_AGE", 0),
+		},
 	}
 }
 
@@ -60,3 +179,54 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration gets an environment variable parsed as a duration or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvInt64 gets an environment variable parsed as an integer or returns a default value
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool gets an environment variable parsed as a boolean or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated environment variable as a list, without empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvFloat gets an environment variable parsed as a float or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,168 @@
+// Package cutlist calcula la lista de corte de un proyecto: cuanta madera lleva cada material, en
+// pies tablares y en metros cubicos, y las medidas de cada pieza en el sistema de unidades pedido.
+package cutlist
+
+import (
+	"math"
+	"sort"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// Units es el sistema de unidades de las medidas de las piezas
+type Units string
+
+// Sistemas de unidades: milimetros o pulgadas
+const (
+	Metric   Units = "metric"
+	Imperial Units = "imperial"
+)
+
+const (
+	mmPerInch = 25.4
+	// un pie tablar es una tabla de 1" x 12" x 12" = 144 pulgadas cubicas
+	cubicInchesPerBoardFoot = 144
+	cubicMMPerCubicMetre    = 1e9
+)
+
+// ParseUnits lee el parametro units; vacio es metric
+func ParseUnits(raw string) (Units, bool) {
+	switch Units(raw) {
+	case "", Metric:
+		return Metric, true
+	case Imperial:
+		return Imperial, true
+	}
+	return "", false
+}
+
+// ToMM pasa una medida en las unidades indicadas a milimetros
+func ToMM(value float64, units Units) float64 {
+	if units == Imperial {
+		return value * mmPerInch
+	}
+	return value
+}
+
+// FromMM pasa una medida en milimetros a las unidades indicadas, redondeada a la centesima de
+// milimetro o a la milesima de pulgada
+func FromMM(mm float64, units Units) float64 {
+	if units == Imperial {
+		return round(mm/mmPerInch, 3)
+	}
+	return round(mm, 2)
+}
+
+// Part es una pieza con sus medidas en el sistema de unidades de la respuesta o del request
+type Part struct {
+	ID        int64   `json:"id,omitempty"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	Material  string  `json:"material"`
+	Thickness float64 `json:"thickness"`
+	Width     float64 `json:"width"`
+	Length    float64 `json:"length"`
+	Grain     string  `json:"grain"`
+}
+
+// NewPart pasa una pieza guardada (en milimetros) a las unidades indicadas
+func NewPart(part models.ProjectPart, units Units) Part {
+	return Part{
+		ID:        part.ID,
+		Name:      part.Name,
+		Quantity:  part.Quantity,
+		Material:  part.Material,
+		Thickness: FromMM(part.ThicknessMM, units),
+		Width:     FromMM(part.WidthMM, units),
+		Length:    FromMM(part.LengthMM, units),
+		Grain:     part.Grain,
+	}
+}
+
+// Model pasa una pieza recibida en las unidades indicadas a milimetros
+func (p Part) Model(units Units) models.ProjectPart {
+	return models.ProjectPart{
+		Name:        p.Name,
+		Quantity:    p.Quantity,
+		Material:    p.Material,
+		ThicknessMM: ToMM(p.Thickness, units),
+		WidthMM:     ToMM(p.Width, units),
+		LengthMM:    ToMM(p.Length, units),
+		Grain:       p.Grain,
+	}
+}
+
+// BoardFeet es el volumen de madera de una pieza, por la cantidad, en pies tablares
+func BoardFeet(part models.ProjectPart) float64 {
+	cubicInches := (part.ThicknessMM / mmPerInch) * (part.WidthMM / mmPerInch) * (part.LengthMM / mmPerInch)
+	return cubicInches / cubicInchesPerBoardFoot * float64(part.Quantity)
+}
+
+// CubicMetres es el volumen de madera de una pieza, por la cantidad, en metros cubicos
+func CubicMetres(part models.ProjectPart) float64 {
+	return part.ThicknessMM * part.WidthMM * part.LengthMM / cubicMMPerCubicMetre * float64(part.Quantity)
+}
+
+// Volume es un volumen de madera en las dos unidades con que se compra
+type Volume struct {
+	BoardFeet   float64 `json:"board_feet"`
+	CubicMetres float64 `json:"cubic_metres"`
+}
+
+func (v *Volume) add(part models.ProjectPart) {
+	v.BoardFeet += BoardFeet(part)
+	v.CubicMetres += CubicMetres(part)
+}
+
+func (v Volume) rounded() Volume {
+	return Volume{BoardFeet: round(v.BoardFeet, 3), CubicMetres: round(v.CubicMetres, 6)}
+}
+
+// MaterialTotal es el total de un material de la lista de corte
+type MaterialTotal struct {
+	Material string `json:"material"`
+	// Pieces es la cantidad de piezas, contando las repetidas
+	Pieces int `json:"pieces"`
+	Volume
+}
+
+// Summary es la lista de corte con los totales por material
+type Summary struct {
+	Units     Units           `json:"units"`
+	Parts     []Part          `json:"parts"`
+	Materials []MaterialTotal `json:"materials"`
+	Total     Volume          `json:"total"`
+}
+
+// Summarize arma la lista de corte. Las piezas se devuelven en las unidades pedidas y los
+// materiales de mayor a menor volumen.
+func Summarize(parts []models.ProjectPart, units Units) Summary {
+	summary := Summary{Units: units, Parts: make([]Part, 0, len(parts)), Materials: []MaterialTotal{}}
+	index := map[string]int{}
+	for _, part := range parts {
+		i, ok := index[part.Material]
+		if !ok {
+			i = len(summary.Materials)
+			index[part.Material] = i
+			summary.Materials = append(summary.Materials, MaterialTotal{Material: part.Material})
+		}
+		summary.Materials[i].Pieces += part.Quantity
+		summary.Materials[i].add(part)
+		summary.Total.add(part)
+		summary.Parts = append(summary.Parts, NewPart(part, units))
+	}
+
+	for i := range summary.Materials {
+		summary.Materials[i].Volume = summary.Materials[i].rounded()
+	}
+	summary.Total = summary.Total.rounded()
+	sort.SliceStable(summary.Materials, func(i, j int) bool {
+		return summary.Materials[i].CubicMetres > summary.Materials[j].CubicMetres
+	})
+	return summary
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
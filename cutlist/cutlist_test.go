@@ -0,0 +1,106 @@
+package cutlist
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+func part(material string, quantity int, thickness, width, length float64) models.ProjectPart {
+	return models.ProjectPart{Name: "pieza", Quantity: quantity, Material: material,
+		ThicknessMM: thickness, WidthMM: width, LengthMM: length, Grain: models.GrainLength}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestVolume(t *testing.T) {
+	tests := []struct {
+		name        string
+		part        models.ProjectPart
+		boardFeet   float64
+		cubicMetres float64
+	}{
+		// 1" x 12" x 12" es la definicion del pie tablar
+		{"one board foot", part("pine", 1, 25.4, 304.8, 304.8), 1, 0.0023597372},
+		{"two side panels", part("pine", 2, 18, 200, 900), 2.7460685, 0.00648},
+		{"quantity multiplies", part("pine", 12, 25.4, 304.8, 304.8), 12, 0.0283168466},
+	}
+	for _, tt := range tests {
+		if got := BoardFeet(tt.part); math.Abs(got-tt.boardFeet) > 1e-6 {
+			t.Errorf("%s: BoardFeet = %v, want %v", tt.name, got, tt.boardFeet)
+		}
+		if got := CubicMetres(tt.part); math.Abs(got-tt.cubicMetres) > 1e-9 {
+			t.Errorf("%s: CubicMetres = %v, want %v", tt.name, got, tt.cubicMetres)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	for raw, want := range map[string]Units{"": Metric, "metric": Metric, "imperial": Imperial} {
+		if got, ok := ParseUnits(raw); !ok || got != want {
+			t.Errorf("ParseUnits(%q) = %q, %v; want %q", raw, got, ok, want)
+		}
+	}
+	for _, raw := range []string{"cm", "Imperial", "inches"} {
+		if _, ok := ParseUnits(raw); ok {
+			t.Errorf("ParseUnits(%q) accepted", raw)
+		}
+	}
+
+	if got := ToMM(1.5, Imperial); !near(got, 38.1) {
+		t.Errorf("ToMM(1.5 in) = %v, want 38.1", got)
+	}
+	if got := ToMM(18, Metric); got != 18 {
+		t.Errorf("ToMM(18 mm) = %v, want 18", got)
+	}
+	// pulgadas a la milesima, milimetros a la centesima
+	if got := FromMM(900, Imperial); got != 35.433 {
+		t.Errorf("FromMM(900 mm) = %v in, want 35.433", got)
+	}
+	if got := FromMM(12.3456, Metric); got != 12.35 {
+		t.Errorf("FromMM(12.3456 mm) = %v, want 12.35", got)
+	}
+
+	// una pieza cargada en pulgadas vuelve con las mismas medidas
+	input := Part{Name: "tapa", Quantity: 1, Material: "pine", Thickness: 0.75, Width: 11.25, Length: 48, Grain: "length"}
+	saved := input.Model(Imperial)
+	if !near(saved.ThicknessMM, 19.05) || !near(saved.WidthMM, 285.75) || !near(saved.LengthMM, 1219.2) {
+		t.Errorf("saved = %+v, want 19.05 x 285.75 x 1219.2 mm", saved)
+	}
+	if got := NewPart(saved, Imperial); got != input {
+		t.Errorf("round trip = %+v, want %+v", got, input)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	parts := []models.ProjectPart{
+		part("mdf", 1, 15, 400, 500),
+		part("pine", 2, 18, 200, 900),
+		part("pine", 1, 18, 300, 600),
+	}
+
+	summary := Summarize(parts, Imperial)
+	// los materiales van de mayor a menor volumen
+	want := []MaterialTotal{
+		{Material: "pine", Pieces: 3, Volume: Volume{BoardFeet: 4.119, CubicMetres: 0.00972}},
+		{Material: "mdf", Pieces: 1, Volume: Volume{BoardFeet: 1.271, CubicMetres: 0.003}},
+	}
+	if !reflect.DeepEqual(summary.Materials, want) {
+		t.Errorf("materials = %+v, want %+v", summary.Materials, want)
+	}
+	if summary.Total != (Volume{BoardFeet: 5.39, CubicMetres: 0.01272}) {
+		t.Errorf("total = %+v, want 5.39 bd ft and 0.01272 m3", summary.Total)
+	}
+	if summary.Units != Imperial || len(summary.Parts) != 3 || summary.Parts[1].Length != 35.433 {
+		t.Errorf("parts = %+v, want the three parts in inches", summary.Parts)
+	}
+
+	empty := Summarize(nil, Metric)
+	if empty.Parts == nil || empty.Materials == nil || empty.Total != (Volume{}) {
+		t.Errorf("empty summary = %+v, want empty lists and no volume", empty)
+	}
+}
@@ -0,0 +1,432 @@
+// Package optimize acomoda las piezas de una lista de corte en placas o tablas comerciales, buscando
+// usar la menor cantidad posible. Usa cortes de guillotina (cada corte atraviesa la pieza de stock
+// o el resto que se esta cortando), como una sierra de mesa o una escuadradora, descuenta el ancho
+// del corte (kerf) y respeta la direccion de la veta.
+//
+// Es una heuristica (el problema exacto es NP-dificil) y es determinista: las mismas entradas dan
+// siempre el mismo resultado.
+package optimize
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Direcciones de veta, las mismas que models.ProjectPart
+const (
+	GrainLength = "length"
+	GrainWidth  = "width"
+	GrainAny    = "any"
+)
+
+// ThicknessTolerance es la diferencia de espesor (mm) con la que una pieza todavia sale de un stock
+const ThicknessTolerance = 0.5
+
+// epsilon absorbe los errores de redondeo al comparar medidas en mm
+const epsilon = 1e-6
+
+// ErrInvalidInput se devuelve ante medidas no positivas, cantidades invalidas o kerf negativo
+var ErrInvalidInput = errors.New("invalid optimizer input")
+
+// Part es una pieza a cortar. Las medidas estan en mm; Length es la medida a lo largo de la veta
+// cuando Grain es "length".
+type Part struct {
+	Name      string
+	Material  string
+	Quantity  int
+	Thickness float64
+	Width     float64
+	Length    float64
+	Grain     string
+}
+
+// Stock es una medida de placa o tabla que se puede comprar. Grain "length" indica que la veta corre
+// a lo largo; "any" que no tiene veta (MDF, melamina) y las piezas se pueden rotar. Thickness 0 sirve
+// para cualquier espesor.
+type Stock struct {
+	Material  string
+	Thickness float64
+	Width     float64
+	Length    float64
+	Grain     string
+}
+
+// Placement es una pieza ubicada en una placa. X corre a lo ancho del stock e Y a lo largo; Width y
+// Length son lo que ocupa la pieza en esos ejes, ya rotada si Rotated.
+type Placement struct {
+	// Part es el indice de la pieza en la entrada y Copy cual de sus copias es (desde 1)
+	Part    int
+	Copy    int
+	Name    string
+	X       float64
+	Y       float64
+	Width   float64
+	Length  float64
+	Rotated bool
+}
+
+// Sheet es una pieza de stock usada, con las piezas que salen de ella
+type Sheet struct {
+	// Stock es el indice del stock en la entrada
+	Stock      int
+	Placements []Placement
+	// Waste es el porcentaje de la superficie del stock que no se usa en piezas (incluye el kerf)
+	Waste float64
+}
+
+// Unplaced es una pieza que no entra en ningun stock de su material y espesor
+type Unplaced struct {
+	Part   int
+	Name   string
+	Copies int
+}
+
+// Purchase es cuantas piezas de un stock hay que comprar
+type Purchase struct {
+	Stock int
+	Count int
+}
+
+// Result es el plan de corte
+type Result struct {
+	Sheets    []Sheet
+	Purchases []Purchase
+	Unplaced  []Unplaced
+	// Waste es el porcentaje de la superficie comprada que no se usa en piezas
+	Waste float64
+}
+
+// piece es una copia de una pieza
+type piece struct {
+	part, copy    int
+	name          string
+	width, length float64
+	rotatable     bool
+	// across indica que la pieza va con su largo a lo ancho del stock (veta a lo ancho)
+	across bool
+}
+
+func (p piece) area() float64 { return p.width * p.length }
+
+// rect es un rectangulo libre de una placa
+type rect struct{ x, y, width, length float64 }
+
+// bin es una placa en uso
+type bin struct {
+	stock      int
+	free       []rect
+	placements []Placement
+	pieces     []piece
+}
+
+// Optimize acomoda las piezas en el stock. Las piezas se agrupan por material y espesor y cada grupo
+// se resuelve por separado con los stocks que le corresponden.
+func Optimize(parts []Part, stock []Stock, kerf float64) (*Result, error) {
+	if kerf < 0 || math.IsNaN(kerf) || math.IsInf(kerf, 0) {
+		return nil, ErrInvalidInput
+	}
+	for _, p := range parts {
+		if p.Quantity < 1 || !positive(p.Width) || !positive(p.Length) || p.Thickness < 0 {
+			return nil, ErrInvalidInput
+		}
+	}
+	for _, s := range stock {
+		if !positive(s.Width) || !positive(s.Length) || s.Thickness < 0 {
+			return nil, ErrInvalidInput
+		}
+	}
+
+	result := &Result{Sheets: []Sheet{}, Purchases: []Purchase{}, Unplaced: []Unplaced{}}
+	unplaced := map[int]int{}
+	for _, group := range groupParts(parts) {
+		candidates := matchingStock(parts[group[0]], stock)
+		bins, missing := packGroup(parts, group, stock, candidates, kerf)
+		for _, p := range missing {
+			unplaced[p.part]++
+		}
+		for _, b := range bins {
+			result.Sheets = append(result.Sheets, Sheet{Stock: b.stock, Placements: b.placements})
+		}
+	}
+
+	var stockArea, usedArea float64
+	counts := map[int]int{}
+	for i := range result.Sheets {
+		sheet := &result.Sheets[i]
+		s := stock[sheet.Stock]
+		var used float64
+		for _, p := range sheet.Placements {
+			used += p.Width * p.Length
+		}
+		sheet.Waste = wastePercent(s.Width*s.Length, used)
+		stockArea += s.Width * s.Length
+		usedArea += used
+		counts[sheet.Stock]++
+	}
+	result.Waste = wastePercent(stockArea, usedArea)
+
+	for i := range stock {
+		if counts[i] > 0 {
+			result.Purchases = append(result.Purchases, Purchase{Stock: i, Count: counts[i]})
+		}
+	}
+	for i, p := range parts {
+		if unplaced[i] > 0 {
+			result.Unplaced = append(result.Unplaced, Unplaced{Part: i, Name: p.Name, Copies: unplaced[i]})
+		}
+	}
+	return result, nil
+}
+
+// groupParts agrupa los indices de las piezas por material y espesor, en el orden de la entrada
+func groupParts(parts []Part) [][]int {
+	type key struct {
+		material  string
+		thickness float64
+	}
+	var groups [][]int
+	index := map[key]int{}
+	for i, p := range parts {
+		k := key{p.Material, p.Thickness}
+		g, ok := index[k]
+		if !ok {
+			g = len(groups)
+			index[k] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// matchingStock devuelve los indices del stock del mismo material y espesor que la pieza
+func matchingStock(part Part, stock []Stock) []int {
+	var out []int
+	for i, s := range stock {
+		if s.Material == part.Material && (s.Thickness == 0 || math.Abs(s.Thickness-part.Thickness) <= ThicknessTolerance) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// packGroup prueba cada stock candidato como medida principal y se queda con el plan que usa menos
+// piezas de stock; a igual cantidad, el que desperdicia menos superficie y despues el de menor indice
+func packGroup(parts []Part, group []int, stock []Stock, candidates []int, kerf float64) ([]*bin, []piece) {
+	var pieces []piece
+	for _, i := range group {
+		p := parts[i]
+		for c := 1; c <= p.Quantity; c++ {
+			pieces = append(pieces, piece{
+				part: i, copy: c, name: p.Name, width: p.Width, length: p.Length,
+				rotatable: p.Grain == GrainAny, across: p.Grain == GrainWidth,
+			})
+		}
+	}
+	// de mayor a menor superficie y lado mas largo; el desempate por pieza y copia lo hace determinista
+	sort.SliceStable(pieces, func(i, j int) bool {
+		a, b := pieces[i], pieces[j]
+		if a.area() != b.area() {
+			return a.area() > b.area()
+		}
+		if math.Max(a.width, a.length) != math.Max(b.width, b.length) {
+			return math.Max(a.width, a.length) > math.Max(b.width, b.length)
+		}
+		if a.part != b.part {
+			return a.part < b.part
+		}
+		return a.copy < b.copy
+	})
+
+	if len(candidates) == 0 {
+		return nil, pieces
+	}
+
+	var best []*bin
+	var bestMissing []piece
+	bestScore := [2]float64{math.Inf(1), math.Inf(1)}
+	for _, primary := range candidates {
+		bins, missing := pack(pieces, stock, candidates, primary, kerf)
+		shrink(bins, stock, candidates, kerf)
+
+		var area float64
+		for _, b := range bins {
+			area += stock[b.stock].Width * stock[b.stock].Length
+		}
+		// una pieza sin ubicar pesa mas que cualquier cantidad de placas
+		score := [2]float64{float64(len(missing)*1e6 + len(bins)), area}
+		if score[0] < bestScore[0] || score[0] == bestScore[0] && score[1] < bestScore[1]-epsilon {
+			best, bestMissing, bestScore = bins, missing, score
+		}
+	}
+	return best, bestMissing
+}
+
+// pack ubica las piezas en orden, cada una en la primera placa abierta donde entre. Si no entra en
+// ninguna abre una placa del stock principal, o del stock mas chico en que entre.
+func pack(pieces []piece, stock []Stock, candidates []int, primary int, kerf float64) ([]*bin, []piece) {
+	var bins []*bin
+	var missing []piece
+	for _, p := range pieces {
+		placed := false
+		for _, b := range bins {
+			if b.place(p, stock[b.stock], kerf) {
+				placed = true
+				break
+			}
+		}
+		if placed {
+			continue
+		}
+		for _, s := range openOrder(stock, candidates, primary) {
+			b := newBin(s, stock[s])
+			if b.place(p, stock[s], kerf) {
+				bins = append(bins, b)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			missing = append(missing, p)
+		}
+	}
+	return bins, missing
+}
+
+// openOrder es el orden en que se prueba abrir una placa nueva: primero el stock principal y
+// despues los demas de menor a mayor superficie
+func openOrder(stock []Stock, candidates []int, primary int) []int {
+	rest := make([]int, 0, len(candidates))
+	for _, c := range candidates {
+		if c != primary {
+			rest = append(rest, c)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return stock[rest[i]].Width*stock[rest[i]].Length < stock[rest[j]].Width*stock[rest[j]].Length
+	})
+	return append([]int{primary}, rest...)
+}
+
+// shrink pasa cada placa al stock mas chico donde todavia entran todas sus piezas
+func shrink(bins []*bin, stock []Stock, candidates []int, kerf float64) {
+	bySize := append([]int(nil), candidates...)
+	sort.SliceStable(bySize, func(i, j int) bool {
+		return stock[bySize[i]].Width*stock[bySize[i]].Length < stock[bySize[j]].Width*stock[bySize[j]].Length
+	})
+	for i, b := range bins {
+		current := stock[b.stock].Width * stock[b.stock].Length
+		for _, s := range bySize {
+			if stock[s].Width*stock[s].Length >= current-epsilon {
+				break
+			}
+			if smaller, ok := repack(b.pieces, s, stock[s], kerf); ok {
+				bins[i] = smaller
+				break
+			}
+		}
+	}
+}
+
+// repack intenta ubicar todas las piezas en una sola placa del stock indicado
+func repack(pieces []piece, index int, s Stock, kerf float64) (*bin, bool) {
+	b := newBin(index, s)
+	for _, p := range pieces {
+		if !b.place(p, s, kerf) {
+			return nil, false
+		}
+	}
+	return b, true
+}
+
+func newBin(index int, s Stock) *bin {
+	return &bin{stock: index, free: []rect{{0, 0, s.Width, s.Length}}}
+}
+
+// orientation es una forma de apoyar la pieza: lo que ocupa a lo ancho y a lo largo del stock
+type orientation struct {
+	width, length float64
+	rotated       bool
+}
+
+// orientations son las orientaciones que permite la veta de la pieza y del stock
+func (p piece) orientations(s Stock) []orientation {
+	straight := orientation{p.width, p.length, false}
+	turned := orientation{p.length, p.width, true}
+	switch {
+	case p.rotatable || s.Grain == GrainAny:
+		return []orientation{straight, turned}
+	case p.across:
+		return []orientation{turned}
+	}
+	return []orientation{straight}
+}
+
+// place ubica la pieza en el rectangulo libre donde el lado que sobra es mas corto (best short side
+// fit) y corta el resto en dos rectangulos por el eje donde sobra menos (shorter leftover axis)
+func (b *bin) place(p piece, s Stock, kerf float64) bool {
+	bestIndex, bestScore := -1, math.Inf(1)
+	var best orientation
+	for i, free := range b.free {
+		for _, o := range p.orientations(s) {
+			if o.width > free.width+epsilon || o.length > free.length+epsilon {
+				continue
+			}
+			score := math.Min(free.width-o.width, free.length-o.length)
+			if score < bestScore-epsilon {
+				bestIndex, bestScore, best = i, score, o
+			}
+		}
+	}
+	if bestIndex < 0 {
+		return false
+	}
+
+	free := b.free[bestIndex]
+	b.placements = append(b.placements, Placement{
+		Part: p.part, Copy: p.copy, Name: p.name,
+		X: free.x, Y: free.y, Width: best.width, Length: best.length, Rotated: best.rotated,
+	})
+	b.pieces = append(b.pieces, p)
+
+	// lo que queda despues de la pieza y del corte
+	usedWidth, usedLength := best.width+kerf, best.length+kerf
+	leftWidth, leftLength := free.width-usedWidth, free.length-usedLength
+	var right, above rect
+	if leftWidth < leftLength {
+		// corte a lo ancho primero: el resto de arriba ocupa todo el ancho
+		right = rect{free.x + usedWidth, free.y, leftWidth, best.length}
+		above = rect{free.x, free.y + usedLength, free.width, leftLength}
+	} else {
+		// corte a lo largo primero: el resto de la derecha ocupa todo el largo
+		right = rect{free.x + usedWidth, free.y, leftWidth, free.length}
+		above = rect{free.x, free.y + usedLength, best.width, leftLength}
+	}
+
+	b.free = append(b.free[:bestIndex], b.free[bestIndex+1:]...)
+	for _, r := range []rect{right, above} {
+		if r.width > epsilon && r.length > epsilon {
+			b.free = append(b.free, r)
+		}
+	}
+	// los rectangulos libres se recorren de abajo hacia arriba y de izquierda a derecha
+	sort.SliceStable(b.free, func(i, j int) bool {
+		if b.free[i].y != b.free[j].y {
+			return b.free[i].y < b.free[j].y
+		}
+		return b.free[i].x < b.free[j].x
+	})
+	return true
+}
+
+func positive(v float64) bool {
+	return v > 0 && !math.IsInf(v, 0)
+}
+
+func wastePercent(total, used float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round((total-used)/total*10000) / 100
+}
@@ -0,0 +1,239 @@
+package optimize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	board := Stock{Material: "pine", Thickness: 18, Width: 100, Length: 300, Grain: GrainLength}
+	mdf := Stock{Material: "mdf", Thickness: 18, Width: 100, Length: 300, Grain: GrainAny}
+	part := func(width, length float64, quantity int, grain string) Part {
+		return Part{Name: "pieza", Material: "pine", Thickness: 18, Width: width, Length: length,
+			Quantity: quantity, Grain: grain}
+	}
+
+	tests := []struct {
+		name         string
+		parts        []Part
+		stock        []Stock
+		kerf         float64
+		wantSheets   int
+		wantUnplaced []Unplaced
+		wantWaste    float64
+		check        func(t *testing.T, r *Result)
+	}{
+		{
+			name:  "two strips share a board without kerf",
+			parts: []Part{part(50, 300, 2, GrainLength)}, stock: []Stock{board},
+			wantSheets: 1, wantWaste: 0,
+		},
+		{
+			name:  "kerf pushes the second strip to another board",
+			parts: []Part{part(50, 300, 2, GrainLength)}, stock: []Stock{board}, kerf: 3,
+			wantSheets: 2, wantWaste: 50,
+		},
+		{
+			name:  "kerf is left between pieces",
+			parts: []Part{part(48.5, 300, 2, GrainLength)}, stock: []Stock{board}, kerf: 3,
+			wantSheets: 1, wantWaste: 3,
+			check: func(t *testing.T, r *Result) {
+				got := r.Sheets[0].Placements
+				if got[0].X != 0 || got[1].X != 51.5 {
+					t.Errorf("x = %v and %v, want 0 and 51.5 (48.5 plus the kerf)", got[0].X, got[1].X)
+				}
+			},
+		},
+		{
+			name:  "grain-locked part is not rotated to fit",
+			parts: []Part{part(250, 80, 1, GrainLength)}, stock: []Stock{board},
+			wantUnplaced: []Unplaced{{Part: 0, Name: "pieza", Copies: 1}},
+		},
+		{
+			name:  "free part is rotated to fit",
+			parts: []Part{part(250, 80, 1, GrainAny)}, stock: []Stock{board},
+			wantSheets: 1, wantWaste: 33.33,
+			check: func(t *testing.T, r *Result) {
+				if p := r.Sheets[0].Placements[0]; !p.Rotated || p.Width != 80 || p.Length != 250 {
+					t.Errorf("placement = %+v, want rotated to 80 x 250", p)
+				}
+			},
+		},
+		{
+			name:  "cross-grain part is turned so the grain runs across",
+			parts: []Part{part(250, 90, 1, GrainWidth)}, stock: []Stock{board},
+			wantSheets: 1, wantWaste: 25,
+			check: func(t *testing.T, r *Result) {
+				if p := r.Sheets[0].Placements[0]; !p.Rotated || p.Width != 90 {
+					t.Errorf("placement = %+v, want turned to 90 wide", p)
+				}
+			},
+		},
+		{
+			name: "stock without grain lets a grain-locked part rotate",
+			parts: []Part{{Name: "tapa", Material: "mdf", Thickness: 18, Width: 250, Length: 80, Quantity: 1,
+				Grain: GrainLength}},
+			stock:      []Stock{mdf},
+			wantSheets: 1, wantWaste: 33.33,
+		},
+		{
+			name:  "waste counts every board bought",
+			parts: []Part{part(100, 150, 3, GrainLength)}, stock: []Stock{board},
+			wantSheets: 2, wantWaste: 25,
+			check: func(t *testing.T, r *Result) {
+				if r.Sheets[0].Waste != 0 || r.Sheets[1].Waste != 50 {
+					t.Errorf("sheet waste = %v and %v, want 0 and 50", r.Sheets[0].Waste, r.Sheets[1].Waste)
+				}
+				if !reflect.DeepEqual(r.Purchases, []Purchase{{Stock: 0, Count: 2}}) {
+					t.Errorf("purchases = %+v, want two boards", r.Purchases)
+				}
+			},
+		},
+		{
+			name:  "oversize part is reported with all its copies",
+			parts: []Part{part(50, 100, 1, GrainLength), part(120, 400, 2, GrainAny)}, stock: []Stock{board},
+			wantSheets: 1, wantUnplaced: []Unplaced{{Part: 1, Name: "pieza", Copies: 2}}, wantWaste: 83.33,
+		},
+		{
+			name:  "part without matching stock is unplaced",
+			parts: []Part{{Name: "estante", Material: "oak", Thickness: 18, Width: 50, Length: 50, Quantity: 1}},
+			stock: []Stock{board}, wantUnplaced: []Unplaced{{Part: 0, Name: "estante", Copies: 1}},
+		},
+		{
+			name:  "thickness outside the tolerance does not use the stock",
+			parts: []Part{{Name: "listón", Material: "pine", Thickness: 25, Width: 50, Length: 50, Quantity: 1}},
+			stock: []Stock{board}, wantUnplaced: []Unplaced{{Part: 0, Name: "listón", Copies: 1}},
+		},
+		{
+			name:       "smallest stock that fits is bought",
+			parts:      []Part{part(50, 100, 1, GrainLength)},
+			stock:      []Stock{board, {Material: "pine", Width: 60, Length: 120, Grain: GrainLength}},
+			wantSheets: 1, wantWaste: 30.56,
+			check: func(t *testing.T, r *Result) {
+				if r.Sheets[0].Stock != 1 {
+					t.Errorf("stock = %d, want the 60 x 120 board", r.Sheets[0].Stock)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Optimize(tt.parts, tt.stock, tt.kerf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Sheets) != tt.wantSheets {
+				t.Fatalf("sheets = %d, want %d: %+v", len(result.Sheets), tt.wantSheets, result.Sheets)
+			}
+			wantUnplaced := tt.wantUnplaced
+			if wantUnplaced == nil {
+				wantUnplaced = []Unplaced{}
+			}
+			if !reflect.DeepEqual(result.Unplaced, wantUnplaced) {
+				t.Errorf("unplaced = %+v, want %+v", result.Unplaced, wantUnplaced)
+			}
+			if result.Waste != tt.wantWaste {
+				t.Errorf("waste = %v, want %v", result.Waste, tt.wantWaste)
+			}
+			checkLayout(t, tt.parts, tt.stock, tt.kerf, result)
+			if tt.check != nil {
+				tt.check(t, result)
+			}
+		})
+	}
+}
+
+// checkLayout controla que cada copia salga una sola vez, dentro de su placa y sin pisar a las demas
+// ni al kerf entre ellas
+func checkLayout(t *testing.T, parts []Part, stock []Stock, kerf float64, result *Result) {
+	t.Helper()
+	copies := map[[2]int]int{}
+	for _, sheet := range result.Sheets {
+		s := stock[sheet.Stock]
+		for i, p := range sheet.Placements {
+			copies[[2]int{p.Part, p.Copy}]++
+			if parts[p.Part].Material != s.Material {
+				t.Errorf("%s copy %d cut from %s", p.Name, p.Copy, s.Material)
+			}
+			if p.X < 0 || p.Y < 0 || p.X+p.Width > s.Width+epsilon || p.Y+p.Length > s.Length+epsilon {
+				t.Errorf("%s copy %d at %+v is outside the %v x %v stock", p.Name, p.Copy, p, s.Width, s.Length)
+			}
+			for _, q := range sheet.Placements[:i] {
+				if p.X < q.X+q.Width+kerf-epsilon && q.X < p.X+p.Width+kerf-epsilon &&
+					p.Y < q.Y+q.Length+kerf-epsilon && q.Y < p.Y+p.Length+kerf-epsilon {
+					t.Errorf("%+v and %+v overlap or share the kerf", p, q)
+				}
+			}
+		}
+	}
+	unplaced := map[int]int{}
+	for _, u := range result.Unplaced {
+		unplaced[u.Part] = u.Copies
+	}
+	for i, p := range parts {
+		placed := 0
+		for c := 1; c <= p.Quantity; c++ {
+			if n := copies[[2]int{i, c}]; n > 1 {
+				t.Errorf("part %d copy %d placed %d times", i, c, n)
+			} else {
+				placed += n
+			}
+		}
+		if placed+unplaced[i] != p.Quantity {
+			t.Errorf("part %d: %d placed and %d unplaced, want %d", i, placed, unplaced[i], p.Quantity)
+		}
+	}
+}
+
+func TestOptimizeIsDeterministic(t *testing.T) {
+	parts := []Part{
+		{Name: "lateral", Material: "pine", Thickness: 18, Width: 300, Length: 900, Quantity: 2, Grain: GrainLength},
+		{Name: "estante", Material: "pine", Thickness: 18, Width: 280, Length: 600, Quantity: 4, Grain: GrainAny},
+		{Name: "zócalo", Material: "pine", Thickness: 18, Width: 80, Length: 600, Quantity: 2, Grain: GrainWidth},
+		{Name: "fondo", Material: "mdf", Thickness: 3, Width: 600, Length: 900, Quantity: 1, Grain: GrainAny},
+		{Name: "taco", Material: "pine", Thickness: 18, Width: 40, Length: 40, Quantity: 8, Grain: GrainAny},
+	}
+	stock := []Stock{
+		{Material: "pine", Thickness: 18, Width: 1220, Length: 2440, Grain: GrainLength},
+		{Material: "pine", Thickness: 18, Width: 610, Length: 1220, Grain: GrainLength},
+		{Material: "mdf", Width: 1830, Length: 2600, Grain: GrainAny},
+	}
+	first, err := Optimize(parts, stock, 3.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLayout(t, parts, stock, 3.2, first)
+	for range 20 {
+		again, err := Optimize(parts, stock, 3.2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, first) {
+			t.Fatalf("results differ:\n%+v\n%+v", first, again)
+		}
+	}
+}
+
+func TestOptimizeInvalidInput(t *testing.T) {
+	board := Stock{Material: "pine", Width: 100, Length: 100}
+	valid := Part{Material: "pine", Width: 10, Length: 10, Quantity: 1}
+	tests := []struct {
+		name  string
+		parts []Part
+		stock []Stock
+		kerf  float64
+	}{
+		{"negative kerf", []Part{valid}, []Stock{board}, -1},
+		{"zero quantity", []Part{{Material: "pine", Width: 10, Length: 10}}, []Stock{board}, 0},
+		{"zero width", []Part{{Material: "pine", Length: 10, Quantity: 1}}, []Stock{board}, 0},
+		{"negative thickness", []Part{{Material: "pine", Thickness: -1, Width: 10, Length: 10, Quantity: 1}},
+			[]Stock{board}, 0},
+		{"zero stock length", []Part{valid}, []Stock{{Material: "pine", Width: 100}}, 0},
+	}
+	for _, tt := range tests {
+		if _, err := Optimize(tt.parts, tt.stock, tt.kerf); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
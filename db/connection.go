@@ -7,13 +7,7 @@ import (
 	"gorm.io/gorm"
 )
 
-// DB variable utilizada para referenciar a la base de datos
-var DB *gorm.DB
-
-// Connection realiza la conexion con la base de datos
-func Connection(cfg *config.Config) error {
-	dsn := cfg.GetDSN()
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	return err
+// Connection realiza la conexion con la base de datos y devuelve la referencia a la misma
+func Connection(cfg *config.Config) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(cfg.GetDSN()), &gorm.Config{})
 }
@@ -0,0 +1,62 @@
+package inventory
+
+import "sort"
+
+// Resolver pasa nombres libres a slugs de la taxonomia; lo implementa *taxonomy.Index
+type Resolver interface {
+	Resolve(kind, name string) (string, bool)
+	Ancestors(kind, slug string) []string
+}
+
+// Key devuelve la clave con la que se compara un nombre de herramienta o material: el slug del termino
+// de la taxonomia que le corresponde por slug, etiqueta o sinonimo, o el nombre normalizado con Fold si
+// no esta en la taxonomia. "Sierra caladora" y "jigsaw" dan "jigsaw".
+func Key(index Resolver, kind, name string) string {
+	if index != nil {
+		if slug, ok := index.Resolve(kind, name); ok {
+			return slug
+		}
+	}
+	return Fold(name)
+}
+
+// Set es un inventario ya resuelto a claves
+type Set map[string]bool
+
+// NewSet resuelve los nombres de un inventario de un tipo (herramientas o materiales). Cada item
+// cuenta tambien como sus padres en la taxonomia: quien tiene una caladora tiene una herramienta
+// electrica, pero no al reves.
+func NewSet(index Resolver, kind string, names ...string) Set {
+	set := Set{}
+	for _, name := range names {
+		key := Key(index, kind, name)
+		if key == "" {
+			continue
+		}
+		set[key] = true
+		if index != nil {
+			for _, parent := range index.Ancestors(kind, key) {
+				set[parent] = true
+			}
+		}
+	}
+	return set
+}
+
+// Keys devuelve las claves del inventario ordenadas
+func (s Set) Keys() []string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Coverage es cuanto de un proyecto cubre un inventario
+type Coverage struct {
+	// Ratio es la fraccion de herramientas y materiales distintos del proyecto que ya se tienen
+	Ratio            float64  `json:"coverage"`
+	MissingTools     []string `json:"missing_tools"`
+	MissingMaterials []string `json:"missing_materials"`
+}
@@ -0,0 +1,73 @@
+// Package inventory normaliza nombres de herramientas y materiales y los resuelve contra la taxonomia
+// para comparar el inventario de un usuario con lo que piden los proyectos.
+package inventory
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords son palabras que no cambian de que herramienta o material se habla
+var stopwords = map[string]bool{
+	"de": true, "del": true, "la": true, "el": true, "los": true, "las": true, "para": true, "con": true,
+	"y": true, "the": true, "a": true, "an": true, "of": true, "for": true, "and": true,
+}
+
+// qualifiers se descartan si queda alguna otra palabra: "madera de pino" y "pine wood" son "pino"
+var qualifiers = map[string]bool{"madera": true, "wood": true, "tabla": true, "board": true}
+
+// accents son los reemplazos de unaccent para castellano
+var accents = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+)
+
+// Fold normaliza un nombre: minusculas, sin acentos, signos, palabras vacias ni plurales.
+// "Madera de Pino" y "pinos" dan "pino".
+func Fold(name string) string {
+	return fold(words(name))
+}
+
+func fold(ws []string) string {
+	for i, w := range ws {
+		ws[i] = singular(w)
+	}
+	return strings.Join(ws, " ")
+}
+
+// words pasa a minusculas, saca acentos, signos y palabras vacias
+func words(name string) []string {
+	name = accents.Replace(strings.ToLower(name))
+	fields := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+
+	kept := make([]string, 0, len(fields))
+	for _, w := range fields {
+		if !stopwords[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) > 1 {
+		filtered := kept[:0:0]
+		for _, w := range kept {
+			if !qualifiers[w] {
+				filtered = append(filtered, w)
+			}
+		}
+		if len(filtered) > 0 {
+			kept = filtered
+		}
+	}
+	return kept
+}
+
+// singular saca el plural regular: "-es" despues de las consonantes con que terminan los singulares
+// en castellano ("destornilladores"), si no "-s"
+func singular(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "es") && strings.ContainsRune("rlndjxz", rune(w[len(w)-3])):
+		return w[:len(w)-2]
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return w[:len(w)-1]
+	}
+	return w
+}
@@ -2,13 +2,21 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/carpentry-hub/woodys-backend/config"
 	"github.com/carpentry-hub/woodys-backend/db"
+	"github.com/carpentry-hub/woodys-backend/markdown"
 	"github.com/carpentry-hub/woodys-backend/middlewares"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
 	"github.com/carpentry-hub/woodys-backend/routes"
+	"github.com/carpentry-hub/woodys-backend/storage"
+	"github.com/carpentry-hub/woodys-backend/uploads"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
@@ -21,66 +29,194 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
-	err := db.Connection(cfg)
-	// TODO : DB should return the ref to the db so it could be passed to routers
+	// Subcomando para administrar el esquema: woodys-backend migrate up|down|status|create
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
+
+	// Subcomando para normalizar los proyectos existentes: woodys-backend taxonomy backfill [--dry-run]
+	if len(os.Args) > 1 && os.Args[1] == "taxonomy" {
+		runTaxonomy(cfg, os.Args[2:])
+		return
+	}
+
+	// Subcomando para renderizar el Markdown guardado: woodys-backend markdown render [--dry-run]
+	if len(os.Args) > 1 && os.Args[1] == "markdown" {
+		runMarkdown(cfg, os.Args[2:])
+		return
+	}
+
+	database, err := db.Connection(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	repos := repositories.NewGorm(database)
+	h := routes.NewHandler(repos)
+	h.RatingPriorWeight = cfg.Ratings.PriorWeight
+	h.PriceCurrency = cfg.Pricing.DefaultCurrency
+	h.RevisionRetention = repositories.RevisionRetention{Keep: cfg.Revisions.Keep, MaxAge: cfg.Revisions.MaxAge}
+	h.Markdown = markdown.New(cfg.Markdown.ImageHosts)
+	if cfg.Paging.CursorSecret != "" {
+		h.Cursors = pagination.NewCodec([]byte(cfg.Paging.CursorSecret))
+	} else {
+		log.Printf("Warning: PAGINATION_CURSOR_SECRET is not set, pagination cursors will not survive a restart")
+	}
+	store, err := openStorage(cfg.Uploads)
+	if err != nil {
+		log.Fatalf("Failed to open upload storage: %v", err)
+	}
+	h.Storage = store
+	h.UploadMaxBytes = cfg.Uploads.MaxBytes
+	h.UploadQuota = cfg.Uploads.UserQuota
+	h.UploadBaseURL = cfg.Uploads.BaseURL
+	h.UploadWorker = uploads.NewWorker(repos.Uploads, store, uploads.DefaultFormats)
+	go h.UploadWorker.Run(context.Background(), cfg.Uploads.Workers)
+
 	r := mux.NewRouter()
 
-	// Usar el middleware para que el header "Content-Type" sea "application/json" y no "text/plain"
-	r.Use(middlewares.JsonContentType)
+	// Verificar el ID token de Firebase y dejar al usuario autenticado en el contexto
+	if cfg.Auth.FirebaseProjectID == "" {
+		log.Printf("Warning: FIREBASE_PROJECT_ID is not set, every bearer token will be rejected")
+	}
+	verifier := middlewares.NewTokenVerifier(
+		cfg.Auth.FirebaseProjectID,
+		middlewares.NewRemoteKeySource(cfg.Auth.CertsURL, cfg.Auth.CertsCacheTTL),
+	)
+	r.Use(middlewares.Authenticate(verifier, middlewares.UserLookupFrom(repos.Users)))
+
+	// Las rutas que devuelven imagenes ponen su propio Content-Type; se registran antes que las demas
+	media := r.NewRoute().Subrouter()
+	media.HandleFunc("/profile-picture/{id}", h.GetProfilePictureByID).Methods("GET")
+	media.HandleFunc("/uploads/{id}", h.GetUpload).Methods("GET")
+	media.HandleFunc("/uploads/{id}/{name}", h.GetUploadVariant).Methods("GET")
 
+	// Usar el middleware para que el header "Content-Type" sea "application/json" y no "text/plain"
+	api := r.NewRoute().Subrouter()
+	api.Use(middlewares.JsonContentType)
 
 	// stats route hanldes
-	r.HandleFunc("/stats", routes.GetStats).Methods("GET")
+	api.HandleFunc("/stats", h.GetStats).Methods("GET")
 
 	// profile picture routes handlers
-	r.HandleFunc("/profile-pictures", routes.GetProfilePictures).Methods("GET")
-	r.HandleFunc("/profile-picture/{id}", routes.GetProfilePictureByID).Methods("GET")
+	api.HandleFunc("/profile-pictures", h.GetProfilePictures).Methods("GET")
+	api.HandleFunc("/profile-pictures", h.PostProfilePicture).Methods("POST")
+	api.HandleFunc("/profile-pictures/order", h.ReorderProfilePictures).Methods("PUT")
+	api.HandleFunc("/profile-pictures/{id:[0-9]+}", h.RetireProfilePicture).Methods("DELETE")
+
+	// upload routes handlers
+	api.HandleFunc("/uploads", h.PostUpload).Methods("POST")
+	api.HandleFunc("/uploads/{id}", h.DeleteUpload).Methods("DELETE")
 
 	// user routes handlers
-	r.HandleFunc("/users/{id}", routes.GetUser).Methods("GET")
-	r.HandleFunc("/users/{id}/projects", routes.GetUserProjects).Methods("GET")
-	r.HandleFunc("/users", routes.PostUser).Methods("POST")
-	r.HandleFunc("/users/{id}", routes.PutUser).Methods("PUT")
-	r.HandleFunc("/users/{id}", routes.DeleteUser).Methods("DELETE")
-	r.HandleFunc("/users/uid/{firebase_uid}", routes.GetUserByUID).Methods("GET")
+	api.HandleFunc("/users/{id}", h.GetUser).Methods("GET")
+	api.HandleFunc("/users/{id}/projects", h.GetUserProjects).Methods("GET")
+	api.HandleFunc("/users", h.PostUser).Methods("POST")
+	api.HandleFunc("/users/{id}", h.PutUser).Methods("PUT")
+	api.HandleFunc("/users/{id}", h.DeleteUser).Methods("DELETE")
+	api.HandleFunc("/users/{id}/avatar", h.PutUserAvatar).Methods("PUT")
+	api.HandleFunc("/users/{id}/avatar", h.DeleteUserAvatar).Methods("DELETE")
+	api.HandleFunc("/users/uid/{firebase_uid}", h.GetUserByUID).Methods("GET")
+
+	// taxonomy routes handlers
+	api.HandleFunc("/taxonomy", h.GetTaxonomy).Methods("GET")
+	api.HandleFunc("/taxonomy/{kind}", h.GetTaxonomyKind).Methods("GET")
+	api.HandleFunc("/taxonomy/{kind}", h.PostTaxonomyTerm).Methods("POST")
+	api.HandleFunc("/taxonomy/{kind}/{slug}", h.PutTaxonomyTerm).Methods("PUT")
+
+	// price catalog routes handlers
+	api.HandleFunc("/prices", h.GetPrices).Methods("GET")
+	api.HandleFunc("/prices", h.PostPrice).Methods("POST")
+	api.HandleFunc("/prices/import", h.ImportPrices).Methods("POST")
+	api.HandleFunc("/prices/{id}", h.PutPrice).Methods("PUT")
+	api.HandleFunc("/prices/{id}", h.DeletePrice).Methods("DELETE")
 
 	// project routes handlers
-	r.HandleFunc("/projects/search", routes.SearchProjects).Methods("GET")
-	r.HandleFunc("/projects/{id:[0-9]+}", routes.GetProject).Methods("GET")
-	r.HandleFunc("/projects", routes.PostProject).Methods("POST")
-	r.HandleFunc("/projects/{id}", routes.PutProject).Methods("PUT")
-	r.HandleFunc("/projects/{id}", routes.DeleteProject).Methods("DELETE")
+	api.HandleFunc("/projects/search", h.SearchProjects).Methods("GET")
+	api.HandleFunc("/projects/buildable", h.GetBuildableProjects).Methods("GET")
+	api.HandleFunc("/projects/{id:[0-9]+}", h.GetProject).Methods("GET")
+	api.HandleFunc("/projects", h.PostProject).Methods("POST")
+	api.HandleFunc("/projects/{id}", h.PutProject).Methods("PUT")
+	api.HandleFunc("/projects/{id}", h.DeleteProject).Methods("DELETE")
+
+	// parts and cut list routes handlers
+	api.HandleFunc("/projects/{id}/parts", h.GetProjectParts).Methods("GET")
+	api.HandleFunc("/projects/{id}/parts", h.PutProjectParts).Methods("PUT")
+	api.HandleFunc("/projects/{id}/cutlist", h.GetProjectCutlist).Methods("GET")
+	api.HandleFunc("/projects/{id}/cutlist/optimize", h.OptimizeProjectCutlist).Methods("POST")
+	api.HandleFunc("/projects/{id}/estimate", h.GetProjectEstimate).Methods("GET")
+
+	// tutorial steps routes handlers
+	api.HandleFunc("/projects/{id}/steps", h.GetProjectSteps).Methods("GET")
+	api.HandleFunc("/projects/{id}/steps", h.PostProjectStep).Methods("POST")
+	api.HandleFunc("/projects/{id}/steps/order", h.ReorderProjectSteps).Methods("PUT")
+	api.HandleFunc("/projects/{id}/steps/{step_id:[0-9]+}", h.GetProjectStep).Methods("GET")
+	api.HandleFunc("/projects/{id}/steps/{step_id:[0-9]+}", h.PutProjectStep).Methods("PUT")
+	api.HandleFunc("/projects/{id}/steps/{step_id:[0-9]+}", h.DeleteProjectStep).Methods("DELETE")
+
+	// project revision routes handlers
+	api.HandleFunc("/projects/{id}/revisions", h.GetProjectRevisions).Methods("GET")
+	api.HandleFunc("/projects/{id}/revisions/{rev:[0-9]+}", h.GetProjectRevision).Methods("GET")
+	api.HandleFunc("/projects/{id}/revisions/{rev:[0-9]+}/diff", h.GetProjectRevisionDiff).Methods("GET")
+	api.HandleFunc("/projects/{id}/revisions/{rev:[0-9]+}/restore", h.RestoreProjectRevision).Methods("POST")
 
 	// comment routes handlers
-	r.HandleFunc("/projects/{id}/comments", routes.GetProjectComments).Methods("GET")
-	r.HandleFunc("/projects/{id}/comments", routes.PostProjectComment).Methods("POST")
-	r.HandleFunc("/comments/{id}", routes.DeleteComment).Methods("DELETE")
-	r.HandleFunc("/comments/{id}/reply", routes.PostCommentReply).Methods("POST")
-	r.HandleFunc("/comments/{id}/replies", routes.GetCommentReplies).Methods("GET")
+	api.HandleFunc("/projects/{id}/comments", h.GetProjectComments).Methods("GET")
+	api.HandleFunc("/projects/{id}/comments", h.PostProjectComment).Methods("POST")
+	api.HandleFunc("/comments/{id}", h.DeleteComment).Methods("DELETE")
+	api.HandleFunc("/comments/{id}/reply", h.PostCommentReply).Methods("POST")
+	api.HandleFunc("/comments/{id}/replies", h.GetCommentReplies).Methods("GET")
 
 	// rating routes handlers
-	r.HandleFunc("/projects/{id}/ratings", routes.PostRating).Methods("POST")
-	r.HandleFunc("/projects/{id}/ratings", routes.PutRating).Methods("PUT")
-	r.HandleFunc("/projects/{id}/ratings", routes.GetRating).Methods("GET")
+	api.HandleFunc("/projects/{id}/ratings", h.PostRating).Methods("POST")
+	api.HandleFunc("/projects/{id}/ratings", h.PutRating).Methods("PUT")
+	api.HandleFunc("/projects/{id}/ratings", h.GetRating).Methods("GET")
+	api.HandleFunc("/projects/{id}/ratings", h.DeleteRating).Methods("DELETE")
+	api.HandleFunc("/projects/{id}/ratings/me", h.GetMyRating).Methods("GET")
+	api.HandleFunc("/users/{id}/ratings", h.GetUserRatings).Methods("GET")
+
+	// inventory routes handlers
+	api.HandleFunc("/users/{id}/inventory", h.GetInventory).Methods("GET")
+	api.HandleFunc("/users/{id}/inventory", h.PostInventoryItem).Methods("POST")
+	api.HandleFunc("/users/{id}/inventory/{item_id}", h.PutInventoryItem).Methods("PUT")
+	api.HandleFunc("/users/{id}/inventory/{item_id}", h.DeleteInventoryItem).Methods("DELETE")
 
 	// project list routes handlers
-	r.HandleFunc("/users/{id}/project-lists", routes.GetUsersProjectLists).Methods("GET")
-	r.HandleFunc("/project-lists/{id}", routes.GetProjectLists).Methods("GET")
-	r.HandleFunc("/project-lists", routes.PostProjectLists).Methods("POST")
-	r.HandleFunc("/project-lists/{id}/projects", routes.AddProjectToList).Methods("POST")
-	r.HandleFunc("/project-lists/{id}/projects", routes.GetProjectsInList).Methods("GET")
-	r.HandleFunc("/project-lists/{id}", routes.PutProjectLists).Methods("PUT")
-	r.HandleFunc("/project-lists/{id}", routes.DeleteProjectList).Methods("DELETE")
-	r.HandleFunc(
+	api.HandleFunc("/users/{id}/project-lists", h.GetUsersProjectLists).Methods("GET")
+	api.HandleFunc("/project-lists/{id}", h.GetProjectLists).Methods("GET")
+	api.HandleFunc("/project-lists", h.PostProjectLists).Methods("POST")
+	api.HandleFunc("/project-lists/{id}/projects", h.AddProjectToList).Methods("POST")
+	api.HandleFunc("/project-lists/{id}/projects", h.GetProjectsInList).Methods("GET")
+	api.HandleFunc("/project-lists/{id}/shopping-list", h.GetShoppingList).Methods("GET")
+	api.HandleFunc("/project-lists/{id}", h.PutProjectLists).Methods("PUT")
+	api.HandleFunc("/project-lists/{id}", h.DeleteProjectList).Methods("DELETE")
+	api.HandleFunc(
 		"/project-lists/{list_id}/projects/{project_id}",
-		routes.DeleteProjectFromList,
+		h.DeleteProjectFromList,
 	).Methods("DELETE")
 
 	if err := http.ListenAndServe(":8080", middlewares.EnableCors(r)); err != nil {
 		log.Fatalf("server failed: %v", err)
 	}
 }
+
+// openStorage abre el almacenamiento de los uploads segun UPLOAD_STORAGE
+func openStorage(cfg config.UploadsConfig) (storage.Storage, error) {
+	switch cfg.Storage {
+	case "filesystem":
+		return storage.NewFilesystem(cfg.Dir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+			Prefix:    cfg.S3.Prefix,
+		})
+	default:
+		return nil, fmt.Errorf("unknown UPLOAD_STORAGE %q, expected filesystem or s3", cfg.Storage)
+	}
+}
@@ -0,0 +1,124 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// backfillBatch es la cantidad de proyectos o comentarios que Backfill lee por consulta
+const backfillBatch = 500
+
+// Count cuenta las filas que recorrio Backfill y las que cambiaron
+type Count struct {
+	Scanned int
+	Changed int
+}
+
+// BackfillReport resume una corrida de Backfill
+type BackfillReport struct {
+	Projects Count
+	Steps    Count
+	Comments Count
+}
+
+// Backfill vuelve a renderizar las descripciones, los pasos y los comentarios guardados y escribe el
+// HTML que cambio, junto con el tutorial de los proyectos cuyos pasos cambiaron. Sirve para los
+// textos anteriores al HTML y para cuando cambian los hosts de imagenes. Con dryRun no escribe nada.
+func Backfill(
+	ctx context.Context, repos *repositories.Repositories, renderer *Renderer, dryRun bool,
+) (*BackfillReport, error) {
+	report := &BackfillReport{}
+	var after int64
+	for {
+		batch, err := repos.Projects.ListAfterID(ctx, after, backfillBatch)
+		if err != nil {
+			return report, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for i := range batch {
+			if err := backfillProject(ctx, repos, renderer, &batch[i], report, dryRun); err != nil {
+				return report, fmt.Errorf("project %d: %w", batch[i].ID, err)
+			}
+		}
+		after = batch[len(batch)-1].ID
+	}
+
+	after = 0
+	for {
+		batch, err := repos.Comments.ListAfterID(ctx, after, backfillBatch)
+		if err != nil {
+			return report, err
+		}
+		if len(batch) == 0 {
+			return report, nil
+		}
+		for i := range batch {
+			comment := &batch[i]
+			report.Comments.Scanned++
+			rendered := renderer.Render(comment.Content)
+			if rendered == comment.ContentHTML {
+				continue
+			}
+			report.Comments.Changed++
+			comment.ContentHTML = rendered
+			if !dryRun {
+				if err := repos.Comments.UpdateRendered(ctx, comment); err != nil {
+					return report, fmt.Errorf("comment %d: %w", comment.ID, err)
+				}
+			}
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+// backfillProject renderiza la descripcion y los pasos de un proyecto
+func backfillProject(
+	ctx context.Context, repos *repositories.Repositories, renderer *Renderer, project *models.Project,
+	report *BackfillReport, dryRun bool,
+) error {
+	report.Projects.Scanned++
+	if rendered := renderer.Render(project.Description); rendered != project.DescriptionHTML {
+		report.Projects.Changed++
+		project.DescriptionHTML = rendered
+		if !dryRun {
+			if err := repos.Projects.UpdateRendered(ctx, project); err != nil {
+				return err
+			}
+		}
+	}
+
+	steps, err := repos.Steps.ListByProject(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range steps {
+		report.Steps.Scanned++
+		if rendered := renderer.Render(steps[i].Body); rendered != steps[i].BodyHTML {
+			report.Steps.Changed++
+			steps[i].BodyHTML = rendered
+			changed = true
+		}
+	}
+	// el tutorial de los proyectos migrados todavia es el texto original y no el de sus pasos
+	if len(steps) > 0 && (project.Tutorial != models.TutorialText(steps) || project.TutorialHTML != models.TutorialHTML(steps)) {
+		changed = true
+	}
+	if !changed || dryRun {
+		return nil
+	}
+	return repos.Steps.UpdateRendered(ctx, project.ID, steps)
+}
+
+// Write imprime el reporte
+func (r *BackfillReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "projects scanned: %d\nprojects changed: %d\n", r.Projects.Scanned, r.Projects.Changed)
+	fmt.Fprintf(w, "steps scanned: %d\nsteps changed: %d\n", r.Steps.Scanned, r.Steps.Changed)
+	fmt.Fprintf(w, "comments scanned: %d\ncomments changed: %d\n", r.Comments.Scanned, r.Comments.Changed)
+}
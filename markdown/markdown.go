@@ -0,0 +1,467 @@
+// Package markdown convierte a HTML el subconjunto de Markdown que aceptan las descripciones, los
+// tutoriales y los comentarios. No deja pasar HTML crudo: todo el texto se escapa y solo se emiten
+// las etiquetas del subconjunto, con enlaces e imagenes controlados, asi que la salida ya se puede
+// insertar en una pagina sin otro sanitizado.
+//
+// El subconjunto:
+//
+//   - parrafos separados por una linea en blanco; un salto de linea simple se respeta (<br>)
+//   - encabezados "#", "##" y "###", que salen como <h3>, <h4> y <h5>
+//   - **negrita**, *cursiva* o _cursiva_ y `codigo`
+//   - enlaces [texto](url) a http, https, mailto o rutas del sitio
+//   - imagenes ![alt](url), solo rutas del sitio o https de los hosts permitidos
+//   - listas con "-", "*" o "+" y numeradas con "1." o "1)", sin anidar
+//   - citas con ">", bloques de codigo entre ``` y separadores "---"
+//   - "\" escapa el caracter de puntuacion que le sigue
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Limites que acotan el trabajo sobre entradas armadas para ser lentas
+const (
+	// maxQuoteDepth es cuantas citas anidadas se interpretan; las siguientes quedan como texto
+	maxQuoteDepth = 8
+	// maxInlineDepth es cuantos enfasis o enlaces anidados se interpretan
+	maxInlineDepth = 16
+	// maxLinkSpan es el largo maximo del texto y del destino de un enlace
+	maxLinkSpan = 2048
+)
+
+// Renderer convierte Markdown a HTML. Las imagenes solo se muestran si son rutas del propio sitio
+// o vienen por https de uno de los hosts permitidos; si no, queda su texto alternativo.
+type Renderer struct {
+	imageHosts map[string]bool
+}
+
+// New crea un Renderer que acepta imagenes de imageHosts (sin esquema ni puerto, p. ej.
+// "firebasestorage.googleapis.com")
+func New(imageHosts []string) *Renderer {
+	hosts := map[string]bool{}
+	for _, host := range imageHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts[host] = true
+		}
+	}
+	return &Renderer{imageHosts: hosts}
+}
+
+// Render devuelve el HTML de source; "" si no hay nada que mostrar
+func (r *Renderer) Render(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\x00", "\uFFFD")
+	var b strings.Builder
+	r.blocks(&b, strings.Split(source, "\n"), 0)
+	return b.String()
+}
+
+// blocks escribe los bloques de lines. depth es la cantidad de citas que los contienen.
+func (r *Renderer) blocks(b *strings.Builder, lines []string, depth int) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		for i, line := range paragraph {
+			if i > 0 {
+				b.WriteString("<br>\n")
+			}
+			r.inline(b, line, true, 0)
+		}
+		b.WriteString("</p>\n")
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "":
+			flush()
+			i++
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			i = codeBlock(b, lines, i)
+		case isRule(trimmed):
+			flush()
+			b.WriteString("<hr>\n")
+			i++
+		case headingLevel(trimmed) > 0:
+			flush()
+			level := headingLevel(trimmed)
+			text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed[level:]), "#"))
+			tag := "h" + strconv.Itoa(level+2)
+			b.WriteString("<" + tag + ">")
+			r.inline(b, text, true, 0)
+			b.WriteString("</" + tag + ">\n")
+			i++
+		case strings.HasPrefix(trimmed, ">") && depth < maxQuoteDepth:
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				line := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(line, ">") {
+					break
+				}
+				line = strings.TrimPrefix(line, ">")
+				quoted = append(quoted, strings.TrimPrefix(line, " "))
+			}
+			b.WriteString("<blockquote>\n")
+			r.blocks(b, quoted, depth+1)
+			b.WriteString("</blockquote>\n")
+		default:
+			if _, _, _, ok := listItem(trimmed); ok {
+				flush()
+				i = r.list(b, lines, i)
+				continue
+			}
+			paragraph = append(paragraph, trimmed)
+			i++
+		}
+	}
+	flush()
+}
+
+// codeBlock escribe el bloque de codigo que abre lines[start] y devuelve la linea que le sigue. Un
+// bloque sin cierre llega hasta el final.
+func codeBlock(b *strings.Builder, lines []string, start int) int {
+	b.WriteString("<pre><code>")
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+			i++
+			break
+		}
+		b.WriteString(html.EscapeString(lines[i]) + "\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// list escribe la lista que empieza en lines[start] y devuelve la linea que le sigue. Las lineas
+// con sangria siguen el item anterior y una linea en blanco no corta la lista si despues viene otro
+// item del mismo tipo.
+func (r *Renderer) list(b *strings.Builder, lines []string, start int) int {
+	ordered, first, _, _ := listItem(strings.TrimSpace(lines[start]))
+	var items [][]string
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if isOrdered, _, text, ok := listItem(trimmed); ok && isOrdered == ordered && !isRule(trimmed) {
+			items = append(items, []string{text})
+			i++
+			continue
+		}
+		if trimmed == "" {
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+			if next < len(lines) {
+				if isOrdered, _, _, ok := listItem(strings.TrimSpace(lines[next])); ok && isOrdered == ordered {
+					i = next
+					continue
+				}
+			}
+			break
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			break
+		}
+		items[len(items)-1] = append(items[len(items)-1], trimmed)
+		i++
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if ordered && first != 1 {
+		b.WriteString(` start="` + strconv.Itoa(first) + `"`)
+	}
+	b.WriteString(">\n")
+	for _, item := range items {
+		b.WriteString("<li>")
+		for j, text := range item {
+			if j > 0 {
+				b.WriteString("<br>\n")
+			}
+			r.inline(b, text, true, 0)
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// listItem reconoce un item de lista: "- texto", "* texto", "+ texto", "3. texto" o "3) texto"
+func listItem(line string) (ordered bool, number int, text string, ok bool) {
+	if len(line) >= 2 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return false, 0, strings.TrimSpace(line[2:]), true
+	}
+	digits := 0
+	for digits < len(line) && digits < 9 && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || digits+1 >= len(line) || (line[digits] != '.' && line[digits] != ')') || line[digits+1] != ' ' {
+		return false, 0, "", false
+	}
+	number, _ = strconv.Atoi(line[:digits])
+	return true, number, strings.TrimSpace(line[digits+2:]), true
+}
+
+// headingLevel devuelve la cantidad de "#" de un encabezado, o 0 si la linea no es un encabezado
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 3 || level == len(line) || line[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// isRule reconoce un separador: tres o mas "-", "*" o "_" iguales, con espacios entre medio
+func isRule(line string) bool {
+	compact := strings.ReplaceAll(line, " ", "")
+	return len(compact) >= 3 && strings.Count(compact, compact[:1]) == len(compact) &&
+		strings.ContainsRune("-*_", rune(compact[0]))
+}
+
+// inline escribe el texto de un bloque con sus enfasis, codigo, enlaces e imagenes. Dentro del
+// texto de un enlace links es false, para no anidar enlaces.
+func (r *Renderer) inline(b *strings.Builder, s string, links bool, depth int) {
+	// unclosed guarda los delimitadores que ya no tienen cierre mas adelante
+	unclosed := map[string]bool{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isPunct(s[i+1]):
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+		case c == '`' && !unclosed["`"]:
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				b.WriteString("<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+			unclosed["`"] = true
+		case c == '!' && i+1 < len(s) && s[i+1] == '[' && depth < maxInlineDepth:
+			if text, dest, n, ok := linkAt(s[i+1:]); ok {
+				r.image(b, text, dest)
+				i += 1 + n
+				continue
+			}
+		case c == '[' && links && depth < maxInlineDepth:
+			if text, dest, n, ok := linkAt(s[i:]); ok {
+				r.link(b, text, dest, depth)
+				i += n
+				continue
+			}
+		case (c == '*' || c == '_') && depth < maxInlineDepth:
+			delim := s[i : i+1]
+			if i+1 < len(s) && s[i+1] == c {
+				delim = s[i : i+2]
+			}
+			// "_" dentro de una palabra (snake_case) no es enfasis
+			if c == '_' && i > 0 && isWordByte(s[i-1]) || unclosed[delim] {
+				break
+			}
+			end, ok := closer(s, i+len(delim), delim)
+			if !ok {
+				unclosed[delim] = true
+				break
+			}
+			tag := "em"
+			if len(delim) == 2 {
+				tag = "strong"
+			}
+			b.WriteString("<" + tag + ">")
+			r.inline(b, s[i+len(delim):end], links, depth+1)
+			b.WriteString("</" + tag + ">")
+			i = end + len(delim)
+			continue
+		}
+
+		// texto comun hasta el proximo caracter que puede abrir algo
+		j := i + 1
+		for j < len(s) && !strings.ContainsRune("\\`![*_", rune(s[j])) {
+			j++
+		}
+		b.WriteString(html.EscapeString(s[i:j]))
+		i = j
+	}
+}
+
+// closer busca desde start el delimitador que cierra un enfasis. El contenido no puede estar vacio
+// ni empezar o terminar con espacios, y un "*" simple no cierra con la mitad de un "**".
+func closer(s string, start int, delim string) (int, bool) {
+	if start >= len(s) || s[start] == ' ' || s[start] == '\t' {
+		return 0, false
+	}
+	for k := start + 1; k+len(delim) <= len(s); k++ {
+		if s[k:k+len(delim)] != delim {
+			continue
+		}
+		if len(delim) == 1 && k+1 < len(s) && s[k+1] == delim[0] {
+			k++
+			continue
+		}
+		if s[k-1] == ' ' || s[k-1] == '\t' {
+			continue
+		}
+		if delim[0] == '_' && k+len(delim) < len(s) && isWordByte(s[k+len(delim)]) {
+			continue
+		}
+		return k, true
+	}
+	return 0, false
+}
+
+// linkAt lee "[texto](destino)" al principio de s y devuelve cuantos bytes ocupa. Un titulo entre
+// comillas despues del destino se descarta.
+func linkAt(s string) (text, dest string, n int, ok bool) {
+	depth := 0
+	closeText := -1
+	for i := 0; i < len(s) && i < maxLinkSpan; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		if depth == 0 {
+			closeText = i
+			break
+		}
+	}
+	if closeText < 0 || closeText+1 >= len(s) || s[closeText+1] != '(' {
+		return "", "", 0, false
+	}
+	depth = 0
+	start := closeText + 1
+	for i := start; i < len(s) && i-start < maxLinkSpan; i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 {
+			dest = strings.TrimSpace(s[start+1 : i])
+			if fields := strings.Fields(dest); len(fields) > 0 {
+				dest = strings.TrimSuffix(strings.TrimPrefix(fields[0], "<"), ">")
+			}
+			return s[1:closeText], dest, i + 1, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// link escribe un enlace; si el destino no es seguro queda solo el texto
+func (r *Renderer) link(b *strings.Builder, text, dest string, depth int) {
+	href, ok := safeLink(dest)
+	if !ok {
+		r.inline(b, text, false, depth+1)
+		return
+	}
+	b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow ugc noopener noreferrer">`)
+	if strings.TrimSpace(text) == "" {
+		b.WriteString(html.EscapeString(href))
+	} else {
+		r.inline(b, text, false, depth+1)
+	}
+	b.WriteString("</a>")
+}
+
+// image escribe una imagen; si el origen no esta permitido queda su texto alternativo
+func (r *Renderer) image(b *strings.Builder, alt, dest string) {
+	src, ok := r.safeImage(dest)
+	if !ok {
+		b.WriteString(html.EscapeString(alt))
+		return
+	}
+	b.WriteString(`<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(alt) + `" loading="lazy">`)
+}
+
+// safeLink acepta http y https con host, mailto, rutas del sitio ("/...") y anclas ("#..."). Todo lo
+// demas (javascript:, data:, vbscript:, "//otro-host", ...) se descarta.
+func safeLink(raw string) (string, bool) {
+	u, ok := parseURL(raw)
+	if !ok {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", false
+		}
+	case "mailto":
+		if u.Opaque == "" {
+			return "", false
+		}
+	case "":
+		if !localPath(raw, u) && !strings.HasPrefix(raw, "#") {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	return u.String(), true
+}
+
+// safeImage acepta rutas del sitio y https de los hosts permitidos
+func (r *Renderer) safeImage(raw string) (string, bool) {
+	u, ok := parseURL(raw)
+	if !ok {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		if r == nil || !r.imageHosts[strings.ToLower(u.Hostname())] {
+			return "", false
+		}
+	case "":
+		if !localPath(raw, u) {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	return u.String(), true
+}
+
+func parseURL(raw string) (*url.URL, bool) {
+	if raw == "" || len(raw) > maxLinkSpan {
+		return nil, false
+	}
+	u, err := url.Parse(raw)
+	return u, err == nil
+}
+
+// localPath indica si raw es una ruta del sitio. "//host" cambia de dominio y algunos navegadores
+// leen "\" como "/", asi que ninguno de los dos cuenta.
+func localPath(raw string, u *url.URL) bool {
+	return u.Host == "" && strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") &&
+		!strings.Contains(raw, "\\")
+}
+
+// isPunct indica si c es un caracter de puntuacion ASCII, los unicos que "\" escapa
+func isPunct(c byte) bool {
+	return c > ' ' && c < 0x7f && !isWordByte(c)
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,189 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// xssCorpus son entradas que intentan colar scripts, atributos de evento o esquemas peligrosos
+var xssCorpus = []string{
+	// HTML crudo
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=//evil.example/x.js></SCRIPT>`,
+	`<img src=x onerror=alert(1)>`,
+	`<svg/onload=alert(1)>`,
+	`<a href="javascript:alert(1)">x</a>`,
+	`<iframe src="data:text/html,<script>alert(1)</script>"></iframe>`,
+	"<scr<script>ipt>alert(1)</script>",
+	`"><script>alert(1)</script>`,
+	`# <svg onload=alert(1)>`,
+	`> <img src=x onerror=alert(1)>`,
+	`- <b onmouseover=alert(1)>item</b>`,
+	`**<b onmouseover=alert(1)>**`,
+	"`<script>alert(1)</script>`",
+	"```\n<script>alert(1)</script>\n```",
+	`\<script>alert(1)\</script>`,
+
+	// esquemas en enlaces
+	`[x](javascript:alert(1))`,
+	`[x](JaVaScRiPt:alert(1))`,
+	`[x]( javascript:alert(1) )`,
+	`[x](<javascript:alert(1)>)`,
+	`[x](javascript:alert(1) "titulo")`,
+	"[x](java\tscript:alert(1))",
+	"[x](\x01javascript:alert(1))",
+	`[x](java%0ascript:alert(1))`,
+	`[x](javascript&colon;alert(1))`,
+	`[x](&#106;avascript:alert(1))`,
+	`[x](&#x6A;avascript:alert(1))`,
+	`[x](&#0000106&#0000097vascript:alert(1))`,
+	`[x](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)`,
+	`[x](DATA:text/html,<script>alert(1)</script>)`,
+	`[x](vbscript:msgbox(1))`,
+	`[x](VbScRiPt:msgbox(1))`,
+	`[x](//evil.example)`,
+	`[x](/\evil.example)`,
+	`[x](https://ok.example/" onmouseover="alert(1))`,
+	`[x](https://ok.example/?q=<script>alert(1)</script>)`,
+	`[<img src=x onerror=alert(1)>](https://ok.example)`,
+
+	// autolinks
+	`<javascript:alert(1)>`,
+	`<http://evil.example/" onmouseover="alert(1)>`,
+	`<data:text/html,<script>alert(1)</script>>`,
+
+	// corchetes anidados
+	`[[x](javascript:alert(1))](https://ok.example)`,
+	`[a [b](javascript:alert(1)) c](/ok)`,
+	`[![x](javascript:alert(1))](https://ok.example)`,
+	`[![x](https://img.example/a.png)](javascript:alert(1))`,
+	`![[x](javascript:alert(1))](/img.png)`,
+	`[x]([y](javascript:alert(1)))`,
+	`[[[[[[[[x](javascript:alert(1))]]]]]]]]`,
+
+	// imagenes
+	`![x](javascript:alert(1))`,
+	`![x](data:image/svg+xml;base64,PHN2ZyBvbmxvYWQ9YWxlcnQoMSk+)`,
+	`![x](https://evil.example/x.png)`,
+	`![x](http://img.example/x.png)`,
+	`![x](//evil.example/x.png)`,
+	`![x](https://img.example.evil.example/x.png)`,
+	`![x" onerror="alert(1)](https://img.example/x.png)`,
+	`![x](https://img.example/x.png" onerror="alert(1))`,
+}
+
+// tagPattern es una etiqueta bien formada con atributos entre comillas dobles
+var tagPattern = regexp.MustCompile(`^<(/?)([a-z0-9]+)((?: [a-z]+="[^"<>]*")*)>`)
+
+var attrPattern = regexp.MustCompile(` ([a-z]+)="([^"]*)"`)
+
+// evilLink es un enlace o imagen al host del atacante
+var evilLink = regexp.MustCompile(`(href|src)="[^"]*evil\.example`)
+
+// allowedTags son las etiquetas que emite el subconjunto, con los atributos que puede llevar cada una
+var allowedTags = map[string][]string{
+	"p": nil, "br": nil, "h3": nil, "h4": nil, "h5": nil, "strong": nil, "em": nil, "code": nil, "pre": nil,
+	"ul": nil, "ol": {"start"}, "li": nil, "blockquote": nil, "hr": nil,
+	"a":   {"href", "rel"},
+	"img": {"src", "alt", "loading"},
+}
+
+// checkSafe controla que todo "<" de la salida abra una etiqueta permitida, sin atributos de evento
+// y con enlaces e imagenes a esquemas seguros
+func checkSafe(t *testing.T, input, out string) {
+	t.Helper()
+	for rest := out; ; {
+		i := strings.IndexByte(rest, '<')
+		if i < 0 {
+			break
+		}
+		rest = rest[i:]
+		m := tagPattern.FindStringSubmatch(rest)
+		if m == nil {
+			t.Errorf("%q: unexpected markup %.40q in %q", input, rest, out)
+			return
+		}
+		attrs, ok := allowedTags[m[2]]
+		if !ok {
+			t.Errorf("%q: tag <%s> in %q", input, m[2], out)
+		}
+		for _, attr := range attrPattern.FindAllStringSubmatch(m[3], -1) {
+			name, value := attr[1], html.UnescapeString(attr[2])
+			if !slices.Contains(attrs, name) {
+				t.Errorf("%q: attribute %s on <%s> in %q", input, name, m[2], out)
+			}
+			if (name == "href" || name == "src") && !safeURL(value) {
+				t.Errorf("%q: unsafe %s %q in %q", input, name, value, out)
+			}
+		}
+		rest = rest[len(m[0]):]
+	}
+	if strings.Contains(strings.ToLower(out), "<script") {
+		t.Errorf("%q: script in %q", input, out)
+	}
+}
+
+// safeURL indica si un destino ya emitido es http(s), mailto, ruta del sitio o ancla. Se sacan los
+// espacios y caracteres de control, que los navegadores ignoran dentro del esquema.
+func safeURL(value string) bool {
+	compact := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.ToLower(value))
+	for _, scheme := range []string{"javascript:", "data:", "vbscript:"} {
+		if strings.HasPrefix(compact, scheme) {
+			return false
+		}
+	}
+	switch {
+	case strings.HasPrefix(compact, "https://"), strings.HasPrefix(compact, "http://"),
+		strings.HasPrefix(compact, "mailto:"), strings.HasPrefix(compact, "#"):
+		return true
+	case strings.HasPrefix(compact, "/"):
+		return !strings.HasPrefix(compact, "//") && !strings.Contains(compact, `\`)
+	}
+	return false
+}
+
+func TestRenderXSSCorpus(t *testing.T) {
+	r := New([]string{"img.example"})
+	for _, input := range xssCorpus {
+		out := r.Render(input)
+		checkSafe(t, input, out)
+		// el corpus solo enlaza a ok.example, img.example y rutas del sitio
+		if evilLink.MatchString(out) {
+			t.Errorf("%q: link to evil.example in %q", input, out)
+		}
+	}
+}
+
+func TestRenderKeepsSafeLinks(t *testing.T) {
+	r := New([]string{"img.example"})
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`[ok](https://ok.example/a?b=1&c=2)`,
+			`<a href="https://ok.example/a?b=1&amp;c=2" rel="nofollow ugc noopener noreferrer">ok</a>`},
+		{`[ok](/projects/1)`, `<a href="/projects/1" rel="nofollow ugc noopener noreferrer">ok</a>`},
+		{`[ok](mailto:taller@ok.example)`,
+			`<a href="mailto:taller@ok.example" rel="nofollow ugc noopener noreferrer">ok</a>`},
+		{`![mesa](https://img.example/mesa.png)`, `<img src="https://img.example/mesa.png" alt="mesa" loading="lazy">`},
+		{`![mesa](/uploads/mesa.png)`, `<img src="/uploads/mesa.png" alt="mesa" loading="lazy">`},
+		{`![mesa](https://evil.example/mesa.png)`, `<p>mesa</p>`},
+		{`[x](javascript:alert(1))`, `<p>x</p>`},
+		{`<script>alert(1)</script>`, `<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>`},
+	}
+	for _, tt := range tests {
+		out := r.Render(tt.input)
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("Render(%q) = %q, want it to contain %q", tt.input, out, tt.want)
+		}
+		checkSafe(t, tt.input, out)
+	}
+}
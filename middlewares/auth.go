@@ -0,0 +1,220 @@
+package middlewares
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// ErrInvalidToken se devuelve ante cualquier ID token que no supere la verificacion
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims contiene los datos relevantes de un ID token de Firebase
+type Claims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	AuthTime  int64  `json:"auth_time"`
+	Email     string `json:"email"`
+}
+
+// TokenVerifier verifica ID tokens de Firebase firmados con RS256
+type TokenVerifier struct {
+	ProjectID string
+	Keys      KeySource
+	Leeway    time.Duration
+	Now       func() time.Time
+}
+
+// NewTokenVerifier crea un verificador para el proyecto de Firebase indicado
+func NewTokenVerifier(projectID string, keys KeySource) *TokenVerifier {
+	return &TokenVerifier{
+		ProjectID: projectID,
+		Keys:      keys,
+		Leeway:    time.Minute,
+		Now:       time.Now,
+	}
+}
+
+// Issuer devuelve el emisor esperado para los tokens del proyecto
+func (v *TokenVerifier) Issuer() string {
+	return "https://securetoken.google.com/" + v.ProjectID
+}
+
+// Verify valida firma, emisor, audiencia y vigencia del token y devuelve sus claims
+func (v *TokenVerifier) Verify(ctx context.Context, raw string) (*Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	key, err := v.Keys.PublicKey(ctx, header.Kid)
+	if err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrInvalidToken, err)
+	}
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *TokenVerifier) validateClaims(c *Claims) error {
+	now := v.Now()
+	switch {
+	case v.ProjectID == "":
+		return fmt.Errorf("%w: firebase project id not configured", ErrInvalidToken)
+	case c.Issuer != v.Issuer():
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	case c.Audience != v.ProjectID:
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	case c.Subject == "" || len(c.Subject) > 128:
+		return fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	case now.Add(-v.Leeway).After(time.Unix(c.ExpiresAt, 0)):
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	case now.Add(v.Leeway).Before(time.Unix(c.IssuedAt, 0)):
+		return fmt.Errorf("%w: token issued in the future", ErrInvalidToken)
+	case now.Add(v.Leeway).Before(time.Unix(c.AuthTime, 0)):
+		return fmt.Errorf("%w: auth_time in the future", ErrInvalidToken)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// UserLookup busca el usuario asociado a un firebase_uid. Devuelve nil, nil si no existe.
+type UserLookup func(ctx context.Context, firebaseUID string) (*models.User, error)
+
+// UserLookupFrom adapta un UserRepository a UserLookup
+func UserLookupFrom(users repositories.UserRepository) UserLookup {
+	return func(ctx context.Context, firebaseUID string) (*models.User, error) {
+		user, err := users.FindByFirebaseUID(ctx, firebaseUID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, nil
+		}
+		return user, err
+	}
+}
+
+// Authenticate verifica el header "Authorization: Bearer <token>" y deja la identidad en el contexto.
+// Los requests sin header pasan como anonimos; un token invalido corta con 401.
+func Authenticate(verifier *TokenVerifier, lookup UserLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || strings.TrimSpace(token) == "" {
+				writeAuthError(w, http.StatusUnauthorized, "Invalid authorization header")
+				return
+			}
+
+			claims, err := verifier.Verify(r.Context(), strings.TrimSpace(token))
+			if err != nil {
+				if errors.Is(err, ErrInvalidToken) {
+					writeAuthError(w, http.StatusUnauthorized, "Invalid or expired token")
+					return
+				}
+				log.Printf("Error verifying token: %v", err)
+				writeAuthError(w, http.StatusServiceUnavailable, "Could not verify token")
+				return
+			}
+
+			user, err := lookup(r.Context(), claims.Subject)
+			if err != nil {
+				log.Printf("Error looking up user %s: %v", claims.Subject, err)
+				writeAuthError(w, http.StatusInternalServerError, "Could not load user")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), claims, user)))
+		})
+	}
+}
+
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+type contextKey int
+
+const (
+	claimsKey contextKey = iota
+	userKey
+)
+
+// WithIdentity agrega los claims y el usuario (puede ser nil si aun no se registro) al contexto
+func WithIdentity(ctx context.Context, claims *Claims, user *models.User) context.Context {
+	ctx = context.WithValue(ctx, claimsKey, claims)
+	if user != nil {
+		ctx = context.WithValue(ctx, userKey, user)
+	}
+	return ctx
+}
+
+// ClaimsFromContext devuelve los claims del token verificado, si hay
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*Claims)
+	return claims, ok
+}
+
+// UserFromContext devuelve el usuario autenticado, si hay uno registrado
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userKey).(*models.User)
+	return user, ok
+}
@@ -0,0 +1,307 @@
+package middlewares
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+const testProject = "woodys-test"
+
+var (
+	testKey   = mustKey()
+	otherKey  = mustKey()
+	testNow   = time.Unix(1_700_000_000, 0)
+	testClock = func() time.Time { return testNow }
+)
+
+func mustKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// validClaims son claims que el verificador de testProject acepta en testNow
+func validClaims() Claims {
+	return Claims{
+		Subject:   "firebase-uid",
+		Issuer:    "https://securetoken.google.com/" + testProject,
+		Audience:  testProject,
+		IssuedAt:  testNow.Add(-time.Minute).Unix(),
+		ExpiresAt: testNow.Add(time.Hour).Unix(),
+		AuthTime:  testNow.Add(-time.Minute).Unix(),
+		Email:     "test@example.com",
+	}
+}
+
+// signToken arma un ID token RS256 con kid firmado con key
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims Claims) string {
+	t.Helper()
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := segment(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"}) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testVerifier(keys KeySource) *TokenVerifier {
+	verifier := NewTokenVerifier(testProject, keys)
+	verifier.Now = testClock
+	return verifier
+}
+
+func TestVerify(t *testing.T) {
+	verifier := testVerifier(StaticKeySource{"k1": &testKey.PublicKey})
+
+	tests := []struct {
+		name   string
+		key    *rsa.PrivateKey
+		kid    string
+		modify func(*Claims)
+		valid  bool
+	}{
+		{name: "valid token", key: testKey, kid: "k1", valid: true},
+		{name: "bad signature", key: otherKey, kid: "k1"},
+		{name: "unknown kid", key: testKey, kid: "k2"},
+		{name: "expired", key: testKey, kid: "k1", modify: func(c *Claims) {
+			c.ExpiresAt = testNow.Add(-2 * time.Minute).Unix()
+		}},
+		{name: "expired within leeway", key: testKey, kid: "k1", valid: true, modify: func(c *Claims) {
+			c.ExpiresAt = testNow.Add(-30 * time.Second).Unix()
+		}},
+		{name: "issued in the future", key: testKey, kid: "k1", modify: func(c *Claims) {
+			c.IssuedAt = testNow.Add(5 * time.Minute).Unix()
+		}},
+		{name: "auth_time in the future", key: testKey, kid: "k1", modify: func(c *Claims) {
+			c.AuthTime = testNow.Add(5 * time.Minute).Unix()
+		}},
+		{name: "wrong audience", key: testKey, kid: "k1", modify: func(c *Claims) {
+			c.Audience = "other-project"
+		}},
+		{name: "wrong issuer", key: testKey, kid: "k1", modify: func(c *Claims) {
+			c.Issuer = "https://securetoken.google.com/other-project"
+		}},
+		{name: "empty subject", key: testKey, kid: "k1", modify: func(c *Claims) {
+			c.Subject = ""
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			if tt.modify != nil {
+				tt.modify(&claims)
+			}
+			got, err := verifier.Verify(context.Background(), signToken(t, tt.key, tt.kid, claims))
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("Verify() error = %v, want ErrInvalidToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if *got != claims {
+				t.Errorf("Verify() = %+v, want %+v", *got, claims)
+			}
+		})
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	verifier := testVerifier(StaticKeySource{"k1": &testKey.PublicKey})
+	token := signToken(t, testKey, "k1", validClaims())
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"k1"}`))
+	tests := map[string]string{
+		"empty":          "",
+		"two segments":   "a.b",
+		"bad base64":     "!!!.b.c",
+		"alg none":       header + token[strings.Index(token, "."):],
+		"truncated sig":  token[:len(token)-4],
+		"garbage header": "e30.e30.e30",
+	}
+	for name, raw := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := verifier.Verify(context.Background(), raw); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+// failingKeys simula no poder descargar las claves
+type failingKeys struct{}
+
+func (failingKeys) PublicKey(context.Context, string) (*rsa.PublicKey, error) {
+	return nil, errors.New("network down")
+}
+
+func TestAuthenticate(t *testing.T) {
+	user := &models.User{ID: 7, FirebaseUID: "firebase-uid"}
+	lookup := func(_ context.Context, uid string) (*models.User, error) {
+		if uid == user.FirebaseUID {
+			return user, nil
+		}
+		return nil, nil
+	}
+	valid := signToken(t, testKey, "k1", validClaims())
+	expired := validClaims()
+	expired.ExpiresAt = testNow.Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name     string
+		keys     KeySource
+		header   string
+		status   int
+		wantUser bool
+	}{
+		{name: "anonymous", keys: StaticKeySource{}, status: http.StatusOK},
+		{name: "valid token", keys: StaticKeySource{"k1": &testKey.PublicKey}, header: "Bearer " + valid,
+			status: http.StatusOK, wantUser: true},
+		{name: "not bearer", keys: StaticKeySource{"k1": &testKey.PublicKey}, header: "Basic abc",
+			status: http.StatusUnauthorized},
+		{name: "empty bearer", keys: StaticKeySource{"k1": &testKey.PublicKey}, header: "Bearer  ",
+			status: http.StatusUnauthorized},
+		{name: "expired token", keys: StaticKeySource{"k1": &testKey.PublicKey},
+			header: "Bearer " + signToken(t, testKey, "k1", expired), status: http.StatusUnauthorized},
+		{name: "keys unavailable", keys: failingKeys{}, header: "Bearer " + valid,
+			status: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUser *models.User
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = UserFromContext(r.Context())
+			})
+			handler := Authenticate(testVerifier(tt.keys), lookup)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body)
+			}
+			if (gotUser != nil) != tt.wantUser {
+				t.Errorf("user in context = %v, want %v", gotUser, tt.wantUser)
+			}
+		})
+	}
+}
+
+// jwksHandler sirve testKey como JWKS con kid y cuenta los pedidos
+func jwksHandler(kid string, requests *atomic.Int32, delay time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		e := big.NewInt(int64(testKey.PublicKey.E)).Bytes()
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":%q,"n":%q,"e":%q}]}`, kid,
+			base64.RawURLEncoding.EncodeToString(testKey.PublicKey.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(e))
+	}
+}
+
+func TestRemoteKeySourceFetchesOnce(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(jwksHandler("k1", &requests, 50*time.Millisecond))
+	defer server.Close()
+	source := NewRemoteKeySource(server.URL, time.Hour)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := source.PublicKey(context.Background(), "k1"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("PublicKey() error = %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("key set fetched %d times, want 1", n)
+	}
+
+	// un kid desconocido no vuelve a pedir el set antes de minRefreshInterval
+	if _, err := source.PublicKey(context.Background(), "k2"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("PublicKey(k2) error = %v, want ErrUnknownKey", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("key set fetched %d times after unknown kid, want 1", n)
+	}
+}
+
+func TestRemoteKeySourceServesCacheDuringRefresh(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Load() > 0 {
+			<-release
+		}
+		jwksHandler("k1", &requests, 0)(w, r)
+	}))
+	defer server.Close()
+	defer close(release)
+	source := NewRemoteKeySource(server.URL, time.Hour)
+	if _, err := source.PublicKey(context.Background(), "k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// un kid nuevo dispara una descarga que queda colgada; las claves ya conocidas siguen saliendo
+	source.mu.Lock()
+	source.fetchedAt = time.Now().Add(-2 * minRefreshInterval)
+	source.mu.Unlock()
+	go source.PublicKey(context.Background(), "rotated")
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := source.PublicKey(context.Background(), "k1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("PublicKey(k1) error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PublicKey(k1) blocked behind a key set refresh")
+	}
+}
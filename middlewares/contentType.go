@@ -2,12 +2,14 @@ package middlewares
 
 import "net/http"
 
+// JsonContentType pone "Content-Type: application/json" en las respuestas de las rutas de la API.
+// Las rutas que devuelven imagenes no lo usan.
 func JsonContentType(next http.Handler) http.Handler {
-	return http.HandlerFunc(func (w http.ResponseWriter, r *http.Request) {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Establecer la cabecera para cada respuesta
 		w.Header().Set("Content-Type", "application/json")
-		
+
 		// Llama al siguiente handler en la cadena
 		next.ServeHTTP(w, r)
 	})
-}
\ No newline at end of file
+}
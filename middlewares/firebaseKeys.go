@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrUnknownKey se devuelve cuando el kid de un token no corresponde a ninguna clave conocida
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// minRefreshInterval evita pedir los certificados en cada request ante kids desconocidos
+const minRefreshInterval = time.Minute
+
+// KeySource provee las claves publicas con las que se firman los ID tokens
+type KeySource interface {
+	PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// StaticKeySource es un conjunto fijo de claves, util para tests o entornos sin red
+type StaticKeySource map[string]*rsa.PublicKey
+
+// PublicKey devuelve la clave asociada al kid
+func (s StaticKeySource) PublicKey(_ context.Context, kid string) (*rsa.PublicKey, error) {
+	key, ok := s[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// RemoteKeySource descarga y cachea las claves publicas de Google. Acepta tanto el formato
+// x509 (mapa kid -> certificado PEM) como un documento JWKS.
+type RemoteKeySource struct {
+	URL    string
+	TTL    time.Duration
+	Client *http.Client
+
+	// mu solo protege el cache; la descarga se hace afuera y fetches junta los pedidos simultaneos
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	expiresAt time.Time
+	fetchedAt time.Time
+	fetches   singleflight.Group
+}
+
+// NewRemoteKeySource crea un RemoteKeySource. El TTL se usa cuando la respuesta no trae max-age.
+func NewRemoteKeySource(url string, ttl time.Duration) *RemoteKeySource {
+	return &RemoteKeySource{
+		URL:    url,
+		TTL:    ttl,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// PublicKey devuelve la clave asociada al kid, refrescando el cache si expiro o si el kid es nuevo
+func (s *RemoteKeySource) PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	keys, expiresAt, fetchedAt := s.keys, s.expiresAt, s.fetchedAt
+	s.mu.Unlock()
+
+	now := time.Now()
+	var err error
+	if keys == nil || now.After(expiresAt) {
+		keys, err = s.refresh(ctx)
+	} else if _, ok := keys[kid]; !ok && now.Sub(fetchedAt) >= minRefreshInterval {
+		// Google rota las claves: ante un kid desconocido se vuelve a pedir el set
+		keys, err = s.refresh(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// refresh descarga las claves una sola vez aunque lo pidan varios requests a la vez. La descarga no
+// se corta si se cancela el request que la empezo, porque los demas la estan esperando.
+func (s *RemoteKeySource) refresh(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	keys, err, _ := s.fetches.Do("keys", func() (any, error) {
+		return s.fetch(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys.(map[string]*rsa.PublicKey), nil
+}
+
+func (s *RemoteKeySource) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching signing keys: unexpected status %d", resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decoding signing keys: %w", err)
+	}
+	keys, err := ParseKeySet(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.TTL
+	if maxAge, ok := parseMaxAge(resp.Header.Get("Cache-Control")); ok {
+		ttl = maxAge
+	}
+	now := time.Now()
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = now
+	s.expiresAt = now.Add(ttl)
+	s.mu.Unlock()
+	return keys, nil
+}
+
+// ParseKeySet interpreta un set de claves en formato JWKS o en formato x509 (kid -> PEM)
+func ParseKeySet(data []byte) (map[string]*rsa.PublicKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err == nil && jwks.Keys != nil {
+		keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+		for _, k := range jwks.Keys {
+			if k.Kty != "RSA" {
+				continue
+			}
+			key, err := parseJWK(k.N, k.E)
+			if err != nil {
+				return nil, fmt.Errorf("parsing key %q: %w", k.Kid, err)
+			}
+			keys[k.Kid] = key
+		}
+		return keys, nil
+	}
+
+	var certs map[string]string
+	if err := json.Unmarshal(data, &certs); err != nil {
+		return nil, fmt.Errorf("unrecognized key set format: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(certs))
+	for kid, certPEM := range certs {
+		key, err := parseCertificateKey(certPEM)
+		if err != nil {
+			return nil, fmt.Errorf("parsing certificate %q: %w", kid, err)
+		}
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+func parseJWK(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	exp := new(big.Int).SetBytes(eBytes)
+	if !exp.IsInt64() || exp.Int64() > int64(^uint32(0)>>1) {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: int(exp.Int64())}, nil
+}
+
+func parseCertificateKey(certPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("invalid PEM block")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("certificate key is not RSA")
+	}
+	return key, nil
+}
+
+// parseMaxAge extrae el max-age de un header Cache-Control
+func parseMaxAge(header string) (time.Duration, bool) {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.TrimSpace(directive)
+		value, ok := strings.CutPrefix(directive, "max-age=")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	return 0, false
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS project_list_items;
+DROP TABLE IF EXISTS project_lists;
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS comment_likes;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS profile_pictures;
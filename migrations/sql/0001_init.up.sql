@@ -0,0 +1,99 @@
+-- Esquema inicial: captura las tablas de todos los structs de models.
+-- Usa IF NOT EXISTS para poder adoptar bases creadas a mano antes de tener migraciones.
+
+CREATE TABLE IF NOT EXISTS profile_pictures (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    referenced text        NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    username        text        NOT NULL DEFAULT '',
+    email           text        NOT NULL DEFAULT '',
+    reputation      real        NOT NULL DEFAULT 0,
+    profile_picture bigint      NOT NULL DEFAULT 0,
+    firebase_uid    text        NOT NULL,
+    is_admin        boolean     NOT NULL DEFAULT false
+);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin boolean NOT NULL DEFAULT false;
+CREATE UNIQUE INDEX IF NOT EXISTS users_firebase_uid_key ON users (firebase_uid);
+
+CREATE TABLE IF NOT EXISTS projects (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz         NOT NULL DEFAULT now(),
+    updated_at     timestamptz         NOT NULL DEFAULT now(),
+    owner          bigint              NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title          text                NOT NULL DEFAULT '',
+    average_rating real                NOT NULL DEFAULT 0,
+    rating_count   bigint              NOT NULL DEFAULT 0,
+    main_material  text                NOT NULL DEFAULT '',
+    materials      character varying[] NOT NULL DEFAULT '{}',
+    height         real                NOT NULL DEFAULT 0,
+    length         real                NOT NULL DEFAULT 0,
+    width          real                NOT NULL DEFAULT 0,
+    tools          character varying[] NOT NULL DEFAULT '{}',
+    description    text                NOT NULL DEFAULT '',
+    style          character varying[] NOT NULL DEFAULT '{}',
+    environment    text                NOT NULL DEFAULT '',
+    portrait       text                NOT NULL DEFAULT '',
+    images         character varying[] NOT NULL DEFAULT '{}',
+    tutorial       text                NOT NULL DEFAULT '',
+    time_to_build  bigint              NOT NULL DEFAULT 0,
+    is_public      boolean             NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS projects_owner_idx ON projects (owner);
+
+-- parent_comment_id = 0 identifica a los comentarios de primer nivel, por eso no lleva FK
+CREATE TABLE IF NOT EXISTS comments (
+    id                bigserial PRIMARY KEY,
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    project_id        bigint      NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    content           text        NOT NULL DEFAULT '',
+    rating            bigint      NOT NULL DEFAULT 0,
+    user_id           bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    parent_comment_id bigint      NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS comments_project_id_idx ON comments (project_id);
+CREATE INDEX IF NOT EXISTS comments_parent_comment_id_idx ON comments (parent_comment_id);
+
+CREATE TABLE IF NOT EXISTS comment_likes (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    comment_id bigint      NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+    value      smallint    NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS comment_likes_user_id_comment_id_key ON comment_likes (user_id, comment_id);
+
+-- PostRating traduce la violacion de (user_id, project_id) en un 409
+CREATE TABLE IF NOT EXISTS ratings (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    value      smallint    NOT NULL,
+    user_id    bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    project_id bigint      NOT NULL REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ratings_user_id_project_id_key ON ratings (user_id, project_id);
+CREATE INDEX IF NOT EXISTS ratings_project_id_idx ON ratings (project_id);
+
+CREATE TABLE IF NOT EXISTS project_lists (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    user_id    bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       text        NOT NULL,
+    is_public  boolean     NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS project_lists_user_id_idx ON project_lists (user_id);
+
+-- AddProjectToList traduce 23503 (FK) en 404 y 23505 (unique) en 409
+CREATE TABLE IF NOT EXISTS project_list_items (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    project_list_id bigint      NOT NULL REFERENCES project_lists (id) ON DELETE CASCADE,
+    project_id      bigint      NOT NULL REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS project_list_items_list_id_project_id_key
+    ON project_list_items (project_list_id, project_id);
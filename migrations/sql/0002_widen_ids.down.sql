@@ -0,0 +1,2 @@
+-- No se vuelve a achicar ningun id: hacerlo podria truncar datos existentes.
+SELECT 1;
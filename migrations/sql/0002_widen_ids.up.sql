@@ -0,0 +1,56 @@
+-- Ensancha todas las columnas de ids y claves foraneas a bigint (int8) para que coincidan con los
+-- int64 de models. Es idempotente: solo altera las columnas que todavia no son bigint.
+-- Solo tiene efecto sobre bases creadas antes de 0001_init.
+DO $$
+DECLARE
+    col record;
+BEGIN
+    FOR col IN
+        SELECT c.table_name, c.column_name
+        FROM information_schema.columns c
+        JOIN (VALUES
+            ('users', 'id'),
+            ('users', 'profile_picture'),
+            ('profile_pictures', 'id'),
+            ('projects', 'id'),
+            ('projects', 'owner'),
+            ('comments', 'id'),
+            ('comments', 'project_id'),
+            ('comments', 'user_id'),
+            ('comments', 'parent_comment_id'),
+            ('comment_likes', 'id'),
+            ('comment_likes', 'user_id'),
+            ('comment_likes', 'comment_id'),
+            ('ratings', 'id'),
+            ('ratings', 'user_id'),
+            ('ratings', 'project_id'),
+            ('project_lists', 'id'),
+            ('project_lists', 'user_id'),
+            ('project_list_items', 'id'),
+            ('project_list_items', 'project_list_id'),
+            ('project_list_items', 'project_id')
+        ) AS wanted(table_name, column_name)
+            ON wanted.table_name = c.table_name AND wanted.column_name = c.column_name
+        WHERE c.table_schema = current_schema()
+          AND c.data_type <> 'bigint'
+    LOOP
+        RAISE NOTICE 'widening %.% to bigint', col.table_name, col.column_name;
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE bigint', col.table_name, col.column_name);
+    END LOOP;
+
+    -- Las secuencias de columnas serial/integer quedan limitadas a int4 aunque la columna cambie
+    FOR col IN
+        SELECT s.schemaname, s.sequencename
+        FROM pg_sequences s
+        WHERE s.data_type <> 'bigint'::regtype
+          AND format('%I.%I', s.schemaname, s.sequencename) IN (
+              SELECT pg_get_serial_sequence(quote_ident(t), 'id')
+              FROM unnest(ARRAY[
+                  'users', 'profile_pictures', 'projects', 'comments', 'comment_likes',
+                  'ratings', 'project_lists', 'project_list_items'
+              ]) AS t
+          )
+    LOOP
+        EXECUTE format('ALTER SEQUENCE %I.%I AS bigint', col.schemaname, col.sequencename);
+    END LOOP;
+END $$;
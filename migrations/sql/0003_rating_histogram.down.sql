@@ -0,0 +1,8 @@
+ALTER TABLE ratings DROP CONSTRAINT IF EXISTS ratings_value_range;
+
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS ratings_1,
+    DROP COLUMN IF EXISTS ratings_2,
+    DROP COLUMN IF EXISTS ratings_3,
+    DROP COLUMN IF EXISTS ratings_4,
+    DROP COLUMN IF EXISTS ratings_5;
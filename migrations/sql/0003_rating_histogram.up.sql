@@ -0,0 +1,34 @@
+-- Histograma de valoraciones por proyecto. Se mantiene de forma incremental al escribir ratings;
+-- rating_count y average_rating pasan a derivarse de estas columnas.
+ALTER TABLE projects
+    ADD COLUMN ratings_1 bigint NOT NULL DEFAULT 0,
+    ADD COLUMN ratings_2 bigint NOT NULL DEFAULT 0,
+    ADD COLUMN ratings_3 bigint NOT NULL DEFAULT 0,
+    ADD COLUMN ratings_4 bigint NOT NULL DEFAULT 0,
+    ADD COLUMN ratings_5 bigint NOT NULL DEFAULT 0;
+
+-- NOT VALID: no revisa filas viejas que pudieran estar fuera de rango, solo las nuevas
+ALTER TABLE ratings ADD CONSTRAINT ratings_value_range CHECK (value BETWEEN 1 AND 5) NOT VALID;
+
+UPDATE projects p
+SET ratings_1      = h.r1,
+    ratings_2      = h.r2,
+    ratings_3      = h.r3,
+    ratings_4      = h.r4,
+    ratings_5      = h.r5,
+    rating_count   = h.total,
+    average_rating = CASE WHEN h.total = 0 THEN 0 ELSE h.stars::real / h.total END
+FROM (
+    SELECT pr.id,
+           COUNT(r.id) FILTER (WHERE r.value = 1)              AS r1,
+           COUNT(r.id) FILTER (WHERE r.value = 2)              AS r2,
+           COUNT(r.id) FILTER (WHERE r.value = 3)              AS r3,
+           COUNT(r.id) FILTER (WHERE r.value = 4)              AS r4,
+           COUNT(r.id) FILTER (WHERE r.value = 5)              AS r5,
+           COUNT(r.id) FILTER (WHERE r.value BETWEEN 1 AND 5)  AS total,
+           COALESCE(SUM(r.value) FILTER (WHERE r.value BETWEEN 1 AND 5), 0) AS stars
+    FROM projects pr
+    LEFT JOIN ratings r ON r.project_id = pr.id
+    GROUP BY pr.id
+) AS h
+WHERE p.id = h.id;
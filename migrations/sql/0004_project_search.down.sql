@@ -0,0 +1,6 @@
+-- La extension unaccent se deja instalada: puede usarla algo fuera de este esquema.
+DROP INDEX IF EXISTS projects_search_vector_idx;
+ALTER TABLE projects DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS projects_search_document(text, text, text, character varying[], character varying[], character varying[]);
+DROP TEXT SEARCH CONFIGURATION IF EXISTS woodys_es;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS woodys_en;
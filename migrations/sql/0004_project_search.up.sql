@@ -0,0 +1,43 @@
+-- Busqueda de texto completo sobre proyectos. Las configuraciones woodys_es y woodys_en son copias de
+-- spanish/english que pasan cada palabra por unaccent antes del stemming, asi "rústica" y "rustica"
+-- generan el mismo lexema tanto en el documento como en la consulta.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'woodys_es') THEN
+        CREATE TEXT SEARCH CONFIGURATION woodys_es (COPY = spanish);
+        ALTER TEXT SEARCH CONFIGURATION woodys_es
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, spanish_stem;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'woodys_en') THEN
+        CREATE TEXT SEARCH CONFIGURATION woodys_en (COPY = english);
+        ALTER TEXT SEARCH CONFIGURATION woodys_en
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, english_stem;
+    END IF;
+END
+$$;
+
+-- array_to_string no es IMMUTABLE, por eso el documento se arma en una funcion propia que si lo es
+-- (los arrays de proyectos son varchar[], cuya conversion a texto no depende de la sesion).
+-- Pesos: A titulo, B estilo y materiales, C descripcion y herramientas, D tutorial.
+CREATE OR REPLACE FUNCTION projects_search_document(
+    title text, description text, tutorial text,
+    materials character varying[], tools character varying[], style character varying[]
+) RETURNS tsvector
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS
+$$
+    SELECT setweight(to_tsvector('woodys_es', coalesce(title, '')), 'A')
+        || setweight(to_tsvector('woodys_en', coalesce(title, '')), 'A')
+        || setweight(to_tsvector('woodys_es', array_to_string(style || materials, ' ')), 'B')
+        || setweight(to_tsvector('woodys_en', array_to_string(style || materials, ' ')), 'B')
+        || setweight(to_tsvector('woodys_es', coalesce(description, '') || ' ' || array_to_string(tools, ' ')), 'C')
+        || setweight(to_tsvector('woodys_en', coalesce(description, '') || ' ' || array_to_string(tools, ' ')), 'C')
+        || setweight(to_tsvector('woodys_es', coalesce(tutorial, '')), 'D')
+        || setweight(to_tsvector('woodys_en', coalesce(tutorial, '')), 'D')
+$$;
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (projects_search_document(title, description, tutorial, materials, tools, style)) STORED;
+
+CREATE INDEX IF NOT EXISTS projects_search_vector_idx ON projects USING GIN (search_vector);
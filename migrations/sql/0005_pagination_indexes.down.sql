@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS projects_owner_created_at_idx;
+DROP INDEX IF EXISTS projects_created_at_idx;
+DROP INDEX IF EXISTS projects_average_rating_idx;
+DROP INDEX IF EXISTS projects_rating_count_idx;
+DROP INDEX IF EXISTS projects_time_to_build_idx;
+DROP INDEX IF EXISTS comments_project_created_at_idx;
+DROP INDEX IF EXISTS comments_parent_created_at_idx;
+DROP INDEX IF EXISTS ratings_project_created_at_idx;
+DROP INDEX IF EXISTS project_lists_user_created_at_idx;
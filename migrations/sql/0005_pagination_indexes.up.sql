@@ -0,0 +1,14 @@
+-- Indices para la paginacion por keyset: cada listado filtra por su columna padre y ordena por
+-- (clave, id), asi la pagina siguiente es un range scan sin importar cuantas filas haya antes.
+CREATE INDEX IF NOT EXISTS projects_owner_created_at_idx ON projects (owner, created_at, id);
+CREATE INDEX IF NOT EXISTS projects_created_at_idx ON projects (created_at, id);
+CREATE INDEX IF NOT EXISTS projects_average_rating_idx ON projects (average_rating, id);
+CREATE INDEX IF NOT EXISTS projects_rating_count_idx ON projects (rating_count, id);
+CREATE INDEX IF NOT EXISTS projects_time_to_build_idx ON projects (time_to_build, id);
+
+CREATE INDEX IF NOT EXISTS comments_project_created_at_idx ON comments (project_id, created_at, id);
+CREATE INDEX IF NOT EXISTS comments_parent_created_at_idx ON comments (parent_comment_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS ratings_project_created_at_idx ON ratings (project_id, created_at, id);
+
+CREATE INDEX IF NOT EXISTS project_lists_user_created_at_idx ON project_lists (user_id, created_at, id);
@@ -0,0 +1,12 @@
+-- Inventario de herramientas y materiales de cada usuario. normalized_name es la clave de
+-- inventory.Normalize; no se repite por usuario y tipo.
+CREATE TABLE IF NOT EXISTS inventory_items (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    user_id         bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind            text        NOT NULL CHECK (kind IN ('tool', 'material')),
+    name            text        NOT NULL,
+    normalized_name text        NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS inventory_items_user_kind_name_key
+    ON inventory_items (user_id, kind, normalized_name);
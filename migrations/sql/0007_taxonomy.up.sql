@@ -0,0 +1,96 @@
+-- Taxonomia canonica de herramientas, materiales, estilos y ambientes. Los proyectos guardan el slug
+-- de cada termino; label_es, label_en y synonyms son los nombres con que se lo reconoce al crear o
+-- editar un proyecto. parent arma la jerarquia dentro de un mismo tipo (madera dura -> roble).
+CREATE TABLE IF NOT EXISTS taxonomy_terms (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    kind       text        NOT NULL CHECK (kind IN ('tool', 'material', 'style', 'environment')),
+    slug       text        NOT NULL CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    parent     text,
+    label_es   text        NOT NULL,
+    label_en   text        NOT NULL,
+    synonyms   text[]      NOT NULL DEFAULT '{}',
+    CONSTRAINT taxonomy_terms_kind_slug_key UNIQUE (kind, slug),
+    CONSTRAINT taxonomy_terms_parent_fkey FOREIGN KEY (kind, parent) REFERENCES taxonomy_terms (kind, slug)
+);
+
+-- Las filas padre van antes que sus hijos para que la foreign key se cumpla fila a fila
+INSERT INTO taxonomy_terms (kind, slug, parent, label_es, label_en, synonyms) VALUES
+    -- herramientas
+    ('tool', 'power-tools',   NULL,          'Herramientas eléctricas', 'Power tools', '{electricas}'),
+    ('tool', 'hand-tools',    NULL,          'Herramientas manuales', 'Hand tools', '{manuales}'),
+    ('tool', 'circular-saw',  'power-tools', 'Sierra circular', 'Circular saw', '{circular}'),
+    ('tool', 'jigsaw',        'power-tools', 'Sierra caladora', 'Jigsaw', '{caladora,"jig saw","sierra de calar"}'),
+    ('tool', 'miter-saw',     'power-tools', 'Ingletadora', 'Miter saw', '{"sierra ingletadora","mitre saw",tronzadora}'),
+    ('tool', 'table-saw',     'power-tools', 'Sierra de mesa', 'Table saw', '{"sierra de banco"}'),
+    ('tool', 'drill',         'power-tools', 'Taladro', 'Drill', '{"power drill","taladro percutor"}'),
+    ('tool', 'drill-driver',  'power-tools', 'Atornillador', 'Drill driver', '{"taladro atornillador","cordless drill","impact driver"}'),
+    ('tool', 'sander',        'power-tools', 'Lijadora', 'Sander', '{"lijadora orbital","orbital sander","random orbit sander"}'),
+    ('tool', 'router',        'power-tools', 'Fresadora', 'Router', '{rebajadora,tupi}'),
+    ('tool', 'heat-gun',      'power-tools', 'Pistola de calor', 'Heat gun', '{}'),
+    ('tool', 'glue-gun',      'power-tools', 'Pistola de silicona', 'Glue gun', '{"pistola de pegamento","hot glue gun"}'),
+    ('tool', 'handsaw',       'hand-tools',  'Serrucho', 'Handsaw', '{"sierra de mano","hand saw"}'),
+    ('tool', 'screwdriver',   'hand-tools',  'Destornillador', 'Screwdriver', '{}'),
+    ('tool', 'hammer',        'hand-tools',  'Martillo', 'Hammer', '{}'),
+    ('tool', 'mallet',        'hand-tools',  'Maza', 'Mallet', '{mazo}'),
+    ('tool', 'hand-plane',    'hand-tools',  'Cepillo de carpintero', 'Hand plane', '{cepillo,garlopa,plane}'),
+    ('tool', 'chisel',        'hand-tools',  'Formón', 'Chisel', '{escoplo}'),
+    ('tool', 'clamp',         'hand-tools',  'Sargento', 'Clamp', '{prensa,"prensa sargento","bar clamp"}'),
+    ('tool', 'square',        'hand-tools',  'Escuadra', 'Square', '{"carpenter square","speed square"}'),
+    ('tool', 'tape-measure',  'hand-tools',  'Cinta métrica', 'Tape measure', '{metro,flexometro,"measuring tape"}'),
+    ('tool', 'level',         'hand-tools',  'Nivel', 'Level', '{"nivel de burbuja","spirit level"}'),
+    ('tool', 'pocket-jig',    'hand-tools',  'Plantilla de tornillo oculto', 'Pocket hole jig', '{"kreg jig",kreg}'),
+    ('tool', 'paint-brush',   'hand-tools',  'Pincel', 'Paint brush', '{brocha,brush}'),
+    ('tool', 'utility-knife', 'hand-tools',  'Cutter', 'Utility knife', '{trincheta,"box cutter"}'),
+    -- materiales
+    ('material', 'solid-wood',  NULL,         'Madera maciza', 'Solid wood', '{maciza}'),
+    ('material', 'hardwood',    'solid-wood', 'Madera dura', 'Hardwood', '{"madera noble"}'),
+    ('material', 'softwood',    'solid-wood', 'Madera blanda', 'Softwood', '{conifera}'),
+    ('material', 'boards',      NULL,         'Tableros', 'Engineered boards', '{tablero,"engineered wood"}'),
+    ('material', 'hardware',    NULL,         'Herrajes', 'Hardware', '{herraje,fasteners}'),
+    ('material', 'finishes',    NULL,         'Terminaciones', 'Finishes', '{acabado,finish}'),
+    ('material', 'supplies',    NULL,         'Insumos', 'Supplies', '{consumables}'),
+    ('material', 'oak',         'hardwood',   'Roble', 'Oak', '{}'),
+    ('material', 'walnut',      'hardwood',   'Nogal', 'Walnut', '{}'),
+    ('material', 'beech',       'hardwood',   'Haya', 'Beech', '{}'),
+    ('material', 'ash',         'hardwood',   'Fresno', 'Ash', '{}'),
+    ('material', 'eucalyptus',  'hardwood',   'Eucalipto', 'Eucalyptus', '{}'),
+    ('material', 'pine',        'softwood',   'Pino', 'Pine', '{}'),
+    ('material', 'cedar',       'softwood',   'Cedro', 'Cedar', '{}'),
+    ('material', 'mdf',         'boards',     'MDF', 'MDF', '{fibrofacil,"medium density fiberboard"}'),
+    ('material', 'plywood',     'boards',     'Multilaminado', 'Plywood', '{contrachapado,terciado,fenolico}'),
+    ('material', 'melamine',    'boards',     'Melamina', 'Melamine', '{}'),
+    ('material', 'osb',         'boards',     'OSB', 'OSB', '{"oriented strand board"}'),
+    ('material', 'screws',      'hardware',   'Tornillos', 'Screws', '{}'),
+    ('material', 'nails',       'hardware',   'Clavos', 'Nails', '{}'),
+    ('material', 'dowels',      'hardware',   'Tarugos', 'Dowels', '{espiga}'),
+    ('material', 'hinges',      'hardware',   'Bisagras', 'Hinges', '{}'),
+    ('material', 'wood-glue',   'supplies',   'Cola vinílica', 'Wood glue', '{cola,"cola de carpintero","adhesivo vinilico",glue,"pva glue"}'),
+    ('material', 'sandpaper',   'supplies',   'Lija', 'Sandpaper', '{"papel de lija","sanding paper"}'),
+    ('material', 'varnish',     'finishes',   'Barniz', 'Varnish', '{}'),
+    ('material', 'wood-stain',  'finishes',   'Tinte para madera', 'Wood stain', '{tinte,stain}'),
+    ('material', 'paint',       'finishes',   'Pintura', 'Paint', '{}'),
+    ('material', 'wax',         'finishes',   'Cera', 'Wax', '{}'),
+    ('material', 'oil',         'finishes',   'Aceite', 'Oil', '{"aceite de linaza","linseed oil","danish oil"}'),
+    -- estilos
+    ('style', 'rustic',       NULL,     'Rústico', 'Rustic', '{campo,farmhouse}'),
+    ('style', 'modern',       NULL,     'Moderno', 'Modern', '{contemporaneo,contemporary}'),
+    ('style', 'minimalist',   'modern', 'Minimalista', 'Minimalist', '{minimal}'),
+    ('style', 'scandinavian', 'modern', 'Nórdico', 'Scandinavian', '{escandinavo,nordic}'),
+    ('style', 'industrial',   NULL,     'Industrial', 'Industrial', '{}'),
+    ('style', 'classic',      NULL,     'Clásico', 'Classic', '{tradicional,traditional}'),
+    ('style', 'vintage',      NULL,     'Vintage', 'Vintage', '{retro}'),
+    ('style', 'mid-century',  'vintage', 'Mid-century', 'Mid-century modern', '{"mid century modern","moderno de mitad de siglo"}'),
+    ('style', 'bohemian',     NULL,     'Bohemio', 'Bohemian', '{boho}'),
+    -- ambientes
+    ('environment', 'indoor',      NULL,      'Interior', 'Indoor', '{adentro,inside}'),
+    ('environment', 'outdoor',     NULL,      'Exterior', 'Outdoor', '{afuera,outside}'),
+    ('environment', 'living-room', 'indoor',  'Living', 'Living room', '{"sala de estar",comedor,"dining room"}'),
+    ('environment', 'bedroom',     'indoor',  'Dormitorio', 'Bedroom', '{habitacion,cuarto}'),
+    ('environment', 'kitchen',     'indoor',  'Cocina', 'Kitchen', '{}'),
+    ('environment', 'bathroom',    'indoor',  'Baño', 'Bathroom', '{}'),
+    ('environment', 'office',      'indoor',  'Oficina', 'Office', '{estudio,escritorio,study}'),
+    ('environment', 'kids-room',   'indoor',  'Cuarto de niños', 'Kids room', '{infantil,nursery}'),
+    ('environment', 'garden',      'outdoor', 'Jardín', 'Garden', '{patio,yard}'),
+    ('environment', 'terrace',     'outdoor', 'Terraza', 'Terrace', '{balcon,balcony,deck}')
+ON CONFLICT (kind, slug) DO NOTHING;
@@ -0,0 +1,16 @@
+-- Lista de piezas de cada proyecto, para armar la lista de corte. Las medidas estan en milimetros y
+-- material es uno de los slugs de projects.materials.
+CREATE TABLE IF NOT EXISTS project_parts (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz      NOT NULL DEFAULT now(),
+    project_id   bigint           NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    position     integer          NOT NULL,
+    name         text             NOT NULL,
+    quantity     integer          NOT NULL CHECK (quantity > 0),
+    material     text             NOT NULL,
+    thickness_mm double precision NOT NULL CHECK (thickness_mm > 0),
+    width_mm     double precision NOT NULL CHECK (width_mm > 0),
+    length_mm    double precision NOT NULL CHECK (length_mm > 0),
+    grain        text             NOT NULL DEFAULT 'length' CHECK (grain IN ('length', 'width', 'any'))
+);
+CREATE INDEX IF NOT EXISTS project_parts_project_position_idx ON project_parts (project_id, position);
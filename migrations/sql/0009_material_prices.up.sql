@@ -0,0 +1,19 @@
+-- Catalogo de precios de materiales. material es un slug de la taxonomia (kind material) y region
+-- vacia es el precio general, que vale donde no hay uno regional. El precio vigente a una fecha es
+-- el de effective_date mas reciente que no la pase.
+CREATE TABLE IF NOT EXISTS material_prices (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz   NOT NULL DEFAULT now(),
+    updated_at     timestamptz   NOT NULL DEFAULT now(),
+    material       text          NOT NULL,
+    unit           text          NOT NULL
+        CHECK (unit IN ('board_foot', 'cubic_metre', 'square_metre', 'linear_metre', 'unit')),
+    price          numeric(14,2) NOT NULL CHECK (price > 0),
+    currency       text          NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    region         text          NOT NULL DEFAULT '',
+    effective_date date          NOT NULL,
+    supplier       text          NOT NULL DEFAULT ''
+);
+-- la importacion de listas de precios hace upsert sobre esta clave
+CREATE UNIQUE INDEX IF NOT EXISTS material_prices_key_idx
+    ON material_prices (material, unit, currency, region, effective_date);
@@ -0,0 +1,2 @@
+-- projects.tutorial conserva el texto de los pasos, asi que no se pierde el contenido
+DROP TABLE IF EXISTS project_steps;
@@ -0,0 +1,22 @@
+-- Pasos del tutorial de cada proyecto. projects.tutorial queda como el texto de todos los pasos, que
+-- arma la api al escribirlos, para que la busqueda de texto siga indexando el tutorial.
+CREATE TABLE IF NOT EXISTS project_steps (
+    id                bigserial PRIMARY KEY,
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    updated_at        timestamptz NOT NULL DEFAULT now(),
+    project_id        bigint      NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    position          integer     NOT NULL,
+    title             text        NOT NULL,
+    body              text        NOT NULL DEFAULT '',
+    images            varchar[]   NOT NULL DEFAULT '{}',
+    estimated_minutes integer     CHECK (estimated_minutes >= 0),
+    tools             varchar[]   NOT NULL DEFAULT '{}',
+    safety_notes      text[]      NOT NULL DEFAULT '{}'
+);
+CREATE INDEX IF NOT EXISTS project_steps_project_position_idx ON project_steps (project_id, position);
+
+-- el tutorial de cada proyecto existente pasa a ser su unico paso
+INSERT INTO project_steps (project_id, position, title, body)
+SELECT id, 0, 'Tutorial', tutorial
+FROM projects
+WHERE btrim(tutorial) <> '';
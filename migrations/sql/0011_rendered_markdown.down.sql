@@ -0,0 +1,5 @@
+ALTER TABLE project_steps DROP COLUMN IF EXISTS body_html;
+ALTER TABLE comments DROP COLUMN IF EXISTS content_html;
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS tutorial_html,
+    DROP COLUMN IF EXISTS description_html;
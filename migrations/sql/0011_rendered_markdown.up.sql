@@ -0,0 +1,7 @@
+-- HTML de los textos en Markdown, que arma la api al escribirlos. Los textos que ya existen se
+-- renderizan con "woodys-backend markdown render".
+ALTER TABLE projects
+    ADD COLUMN IF NOT EXISTS description_html text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tutorial_html    text NOT NULL DEFAULT '';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_html text NOT NULL DEFAULT '';
+ALTER TABLE project_steps ADD COLUMN IF NOT EXISTS body_html text NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- Imagenes subidas con POST /uploads. El id es al azar (uploads.NewID) para que las imagenes de
+-- proyectos privados no se puedan adivinar; el archivo se guarda en el storage con la clave id/original.
+CREATE TABLE IF NOT EXISTS uploads (
+    id           varchar(26) PRIMARY KEY,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    user_id      bigint      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    content_type text        NOT NULL CHECK (content_type IN ('image/jpeg', 'image/png', 'image/webp')),
+    size         bigint      NOT NULL CHECK (size > 0),
+    width        integer     NOT NULL CHECK (width > 0),
+    height       integer     NOT NULL CHECK (height > 0),
+    sha256       char(64)    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS uploads_user_id_idx ON uploads (user_id);
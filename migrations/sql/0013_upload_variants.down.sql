@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS upload_variants;
+
+DROP INDEX IF EXISTS uploads_unprocessed_idx;
+
+ALTER TABLE uploads
+    DROP COLUMN IF EXISTS processed_at,
+    DROP COLUMN IF EXISTS dominant_color,
+    DROP COLUMN IF EXISTS blurhash;
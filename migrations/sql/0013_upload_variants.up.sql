@@ -0,0 +1,20 @@
+-- Versiones reducidas de cada upload (miniaturas para srcset) y el placeholder para la carga diferida.
+-- Las genera un worker despues de la subida; processed_at queda NULL hasta entonces, asi que los
+-- uploads que ya existian se procesan solos al arrancar.
+ALTER TABLE uploads
+    ADD COLUMN IF NOT EXISTS blurhash       varchar(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS dominant_color varchar(7)  NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS processed_at   timestamptz;
+
+CREATE INDEX IF NOT EXISTS uploads_unprocessed_idx ON uploads (created_at) WHERE processed_at IS NULL;
+
+-- name es el nombre del archivo en el storage, junto al original: id/w480.jpg
+CREATE TABLE IF NOT EXISTS upload_variants (
+    upload_id    varchar(26) NOT NULL REFERENCES uploads (id) ON DELETE CASCADE,
+    name         varchar(32) NOT NULL,
+    width        integer     NOT NULL CHECK (width > 0),
+    height       integer     NOT NULL CHECK (height > 0),
+    content_type text        NOT NULL,
+    size         bigint      NOT NULL CHECK (size > 0),
+    PRIMARY KEY (upload_id, name)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_upload;
+
+ALTER TABLE profile_pictures
+    DROP COLUMN IF EXISTS retired_at,
+    DROP COLUMN IF EXISTS position;
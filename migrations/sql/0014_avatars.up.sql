@@ -0,0 +1,14 @@
+-- Fotos de perfil propias (un upload recortado al cuadrado) y administracion de las fotos por defecto:
+-- un orden para mostrarlas y la fecha en que se retiraron. Una foto retirada ya no se ofrece, pero los
+-- usuarios que la tenian la siguen viendo.
+ALTER TABLE profile_pictures
+    ADD COLUMN IF NOT EXISTS position   integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS retired_at timestamptz;
+
+UPDATE profile_pictures p
+SET position = o.n
+FROM (SELECT id, row_number() OVER (ORDER BY id) AS n FROM profile_pictures) o
+WHERE p.id = o.id;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS avatar_upload varchar(26) REFERENCES uploads (id) ON DELETE SET NULL;
@@ -0,0 +1,60 @@
+-- Historial de cada proyecto: despues de cada cambio la api guarda una revision con el contenido
+-- editable del proyecto, sus pasos y sus piezas (models.ProjectSnapshot). Las revisiones no se
+-- modifican; las mas viejas se borran segun PROJECT_REVISIONS_KEEP y PROJECT_REVISIONS_MAX_AGE.
+CREATE TABLE IF NOT EXISTS project_revisions (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    project_id    bigint      NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    number        integer     NOT NULL,
+    author_id     bigint      REFERENCES users (id) ON DELETE SET NULL,
+    restored_from integer,
+    changed       varchar[]   NOT NULL DEFAULT '{}',
+    snapshot      jsonb       NOT NULL,
+    UNIQUE (project_id, number)
+);
+
+-- el estado actual de cada proyecto existente es su primera revision, sin autor
+INSERT INTO project_revisions (created_at, project_id, number, snapshot)
+SELECT p.updated_at, p.id, 1, jsonb_build_object(
+    'title', p.title,
+    'description', p.description,
+    'main_material', p.main_material,
+    'materials', p.materials,
+    'height', p.height,
+    'length', p.length,
+    'width', p.width,
+    'tools', p.tools,
+    'style', p.style,
+    'environment', p.environment,
+    'portrait', p.portrait,
+    'images', p.images,
+    'time_to_build', p.time_to_build,
+    'is_public', p.is_public,
+    'steps', COALESCE((
+        SELECT jsonb_agg(jsonb_build_object(
+            'title', s.title,
+            'body', s.body,
+            'images', s.images,
+            'estimated_minutes', s.estimated_minutes,
+            'tools', s.tools,
+            'safety_notes', s.safety_notes
+        ) ORDER BY s.position, s.id)
+        FROM project_steps s
+        WHERE s.project_id = p.id
+    ), '[]'::jsonb),
+    'parts', COALESCE((
+        SELECT jsonb_agg(jsonb_build_object(
+            'name', pp.name,
+            'quantity', pp.quantity,
+            'material', pp.material,
+            'thickness', pp.thickness_mm,
+            'width', pp.width_mm,
+            'length', pp.length_mm,
+            'grain', pp.grain
+        ) ORDER BY pp.position, pp.id)
+        FROM project_parts pp
+        WHERE pp.project_id = p.id
+    ), '[]'::jsonb)
+)
+FROM projects p
+ON CONFLICT (project_id, number) DO NOTHING;
@@ -0,0 +1 @@
+-- No hay nada que deshacer: las variantes generadas tambien sirven con la version anterior.
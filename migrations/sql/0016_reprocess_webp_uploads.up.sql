@@ -0,0 +1,7 @@
+-- Antes el worker no decodificaba WebP y marcaba esos uploads como procesados sin variantes ni
+-- placeholder. Se vuelven a poner en la cola del worker, que los toma al arrancar.
+UPDATE uploads
+SET processed_at = NULL
+WHERE content_type = 'image/webp'
+  AND processed_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM upload_variants v WHERE v.upload_id = uploads.id);
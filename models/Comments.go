@@ -5,11 +5,12 @@ import "time"
 
 // Comment representa a un comentario o a una respuesta con sus respectivos datos
 type Comment struct {
-	ID              int8      `json:"id"`
+	ID              int64     `json:"id"`
 	CreatedAt       time.Time `json:"created_at"`
-	ProjectID       int8      `json:"project_id"`
+	ProjectID       int64     `json:"project_id"`
 	Content         string    `json:"content"`
+	ContentHTML     string    `json:"content_html"` // Content renderizado por el paquete markdown
 	Rating          int       `json:"rating"`
-	UserID          int8      `json:"user_id"`
-	ParentCommentID int       `json:"parent_comment_id"` // replies
+	UserID          int64     `json:"user_id"`
+	ParentCommentID int64     `json:"parent_comment_id"` // replies
 }
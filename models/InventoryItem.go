@@ -0,0 +1,22 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+import "time"
+
+// Tipos de item de inventario
+const (
+	InventoryTool     = "tool"
+	InventoryMaterial = "material"
+)
+
+// InventoryItem es una herramienta o material que un usuario tiene disponible
+type InventoryItem struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    int64     `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	// NormalizedName es el slug de la taxonomia del item o, si no esta, su nombre normalizado (ver
+	// inventory.Key). Un usuario no puede tener dos items con la misma clave.
+	NormalizedName string `json:"normalized_name"`
+}
@@ -0,0 +1,31 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+import "time"
+
+// Unidades en que se cotiza un material
+const (
+	PriceBoardFoot   = "board_foot"   // pie tablar
+	PriceCubicMetre  = "cubic_metre"  // metro cubico
+	PriceSquareMetre = "square_metre" // metro cuadrado, para placas
+	PriceLinearMetre = "linear_metre" // metro lineal, para listones y molduras
+	PriceUnit        = "unit"         // por unidad: una caja de tornillos, una lata de barniz
+)
+
+// PriceUnits son las unidades validas de un precio
+var PriceUnits = []string{PriceBoardFoot, PriceCubicMetre, PriceSquareMetre, PriceLinearMetre, PriceUnit}
+
+// MaterialPrice es el precio de un material del catalogo desde una fecha. Region vacia es el precio
+// general.
+type MaterialPrice struct {
+	ID            int64     `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Material      string    `json:"material"`
+	Unit          string    `json:"unit"`
+	Price         float64   `json:"price"`
+	Currency      string    `json:"currency"`
+	Region        string    `json:"region"`
+	EffectiveDate time.Time `json:"effective_date" gorm:"type:date"`
+	Supplier      string    `json:"supplier"`
+}
@@ -5,7 +5,10 @@ import "time"
 
 // ProfilePicture representa el modelo de una foto de perfil por defecto del sistema.
 type ProfilePicture struct {
-	ID        int8  `json:"id" gorm:"primaryKey"`
-	CreatedAt time.Time `json:"created_at"`
-	Referenced string `json:"referenced"`
-}
\ No newline at end of file
+	ID         int64     `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+	Referenced string    `json:"referenced"`
+	Position   int       `json:"position"`
+	// RetiredAt es cuando se dejo de ofrecer; los usuarios que ya la tenian la conservan
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
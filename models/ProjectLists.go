@@ -5,10 +5,10 @@ import "time"
 
 // ProjectList representa una lista de proyectos con sus respectivos datos
 type ProjectList struct {
-	ID        int8      `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UserID    int       `json:"user_id"`
-	Name      string    `json:"name"`
-	IsPublic  bool      `json:"is_public"`
-	ProjectCount int64  `json:"project_count" gorm:"-"`
+	ID           int64     `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UserID       int64     `json:"user_id"`
+	Name         string    `json:"name"`
+	IsPublic     bool      `json:"is_public"`
+	ProjectCount int64     `json:"project_count" gorm:"-"`
 }
@@ -0,0 +1,26 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+import "time"
+
+// Direcciones de la veta de una pieza
+const (
+	GrainLength = "length" // la veta corre a lo largo de la pieza
+	GrainWidth  = "width"  // la veta corre a lo ancho
+	GrainAny    = "any"    // se puede rotar, por ejemplo en MDF o melamina
+)
+
+// ProjectPart es una pieza de la lista de corte de un proyecto. Las medidas estan en milimetros.
+type ProjectPart struct {
+	ID          int64     `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	ProjectID   int64     `json:"project_id"`
+	Position    int       `json:"position"`
+	Name        string    `json:"name"`
+	Quantity    int       `json:"quantity"`
+	Material    string    `json:"material"`
+	ThicknessMM float64   `json:"thickness" gorm:"column:thickness_mm"`
+	WidthMM     float64   `json:"width" gorm:"column:width_mm"`
+	LengthMM    float64   `json:"length" gorm:"column:length_mm"`
+	Grain       string    `json:"grain"`
+}
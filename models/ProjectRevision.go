@@ -0,0 +1,240 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ProjectRevision es una version guardada de un proyecto. Se agrega una despues de cada cambio y no
+// se modifica nunca; restaurar una revision vieja agrega otra nueva con su contenido.
+type ProjectRevision struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ProjectID int64     `json:"project_id"`
+	// Number cuenta las revisiones de cada proyecto desde 1
+	Number int `json:"number"`
+	// AuthorID es quien hizo el cambio; nil en la primera revision de los proyectos anteriores al
+	// historial o si el usuario se borro
+	AuthorID *int64 `json:"author_id"`
+	// RestoredFrom es el numero de la revision restaurada, si la revision salio de una restauracion
+	RestoredFrom *int `json:"restored_from,omitempty"`
+	// Changed son los campos del snapshot que cambiaron respecto de la revision anterior
+	Changed pq.StringArray `json:"changed" gorm:"type:varchar[]"`
+	// Snapshot es nil en los listados
+	Snapshot *ProjectSnapshot `json:"snapshot,omitempty" gorm:"serializer:json"`
+}
+
+// ProjectSnapshot es el contenido editable de un proyecto: sus campos, los pasos del tutorial y la
+// lista de piezas. Los agregados de valoraciones y el HTML renderizado no se guardan.
+type ProjectSnapshot struct {
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	MainMaterial string         `json:"main_material"`
+	Materials    []string       `json:"materials"`
+	Height       float32        `json:"height"`
+	Length       float32        `json:"length"`
+	Width        float32        `json:"width"`
+	Tools        []string       `json:"tools"`
+	Style        []string       `json:"style"`
+	Environment  string         `json:"environment"`
+	Portrait     string         `json:"portrait"`
+	Images       []string       `json:"images"`
+	TimeToBuild  int            `json:"time_to_build"`
+	IsPublic     bool           `json:"is_public"`
+	Steps        []StepSnapshot `json:"steps"`
+	Parts        []PartSnapshot `json:"parts"`
+}
+
+// StepSnapshot es un paso del tutorial dentro de un ProjectSnapshot
+type StepSnapshot struct {
+	Title            string   `json:"title"`
+	Body             string   `json:"body"`
+	Images           []string `json:"images"`
+	EstimatedMinutes *int     `json:"estimated_minutes"`
+	Tools            []string `json:"tools"`
+	SafetyNotes      []string `json:"safety_notes"`
+}
+
+// PartSnapshot es una pieza dentro de un ProjectSnapshot. Las medidas estan en milimetros.
+type PartSnapshot struct {
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	Material    string  `json:"material"`
+	ThicknessMM float64 `json:"thickness"`
+	WidthMM     float64 `json:"width"`
+	LengthMM    float64 `json:"length"`
+	Grain       string  `json:"grain"`
+}
+
+// NewProjectSnapshot arma el snapshot de un proyecto con sus pasos y sus piezas, en orden. Las
+// listas vacias quedan como [] y no nil, asi dos snapshots iguales se comparan iguales.
+func NewProjectSnapshot(project *Project, steps []ProjectStep, parts []ProjectPart) ProjectSnapshot {
+	snapshot := ProjectSnapshot{
+		Title:        project.Title,
+		Description:  project.Description,
+		MainMaterial: project.MainMaterial,
+		Materials:    nonNil(project.Materials),
+		Height:       project.Height,
+		Length:       project.Length,
+		Width:        project.Width,
+		Tools:        nonNil(project.Tools),
+		Style:        nonNil(project.Style),
+		Environment:  project.Environment,
+		Portrait:     project.Portrait,
+		Images:       nonNil(project.Images),
+		TimeToBuild:  project.TimeToBuild,
+		IsPublic:     project.IsPublic,
+		Steps:        make([]StepSnapshot, 0, len(steps)),
+		Parts:        make([]PartSnapshot, 0, len(parts)),
+	}
+	for _, step := range steps {
+		snapshot.Steps = append(snapshot.Steps, StepSnapshot{
+			Title:            step.Title,
+			Body:             step.Body,
+			Images:           nonNil(step.Images),
+			EstimatedMinutes: step.EstimatedMinutes,
+			Tools:            nonNil(step.Tools),
+			SafetyNotes:      nonNil(step.SafetyNotes),
+		})
+	}
+	for _, part := range parts {
+		snapshot.Parts = append(snapshot.Parts, PartSnapshot{
+			Name:        part.Name,
+			Quantity:    part.Quantity,
+			Material:    part.Material,
+			ThicknessMM: part.ThicknessMM,
+			WidthMM:     part.WidthMM,
+			LengthMM:    part.LengthMM,
+			Grain:       part.Grain,
+		})
+	}
+	return snapshot
+}
+
+// ApplyTo copia los campos del snapshot en project. El HTML de la descripcion queda para quien llama.
+func (s *ProjectSnapshot) ApplyTo(project *Project) {
+	project.Title = s.Title
+	project.Description = s.Description
+	project.MainMaterial = s.MainMaterial
+	project.Materials = nonNil(s.Materials)
+	project.Height = s.Height
+	project.Length = s.Length
+	project.Width = s.Width
+	project.Tools = nonNil(s.Tools)
+	project.Style = nonNil(s.Style)
+	project.Environment = s.Environment
+	project.Portrait = s.Portrait
+	project.Images = nonNil(s.Images)
+	project.TimeToBuild = s.TimeToBuild
+	project.IsPublic = s.IsPublic
+}
+
+// ProjectSteps devuelve los pasos del snapshot para projectID, en orden. El HTML de cada paso queda
+// para quien llama.
+func (s *ProjectSnapshot) ProjectSteps(projectID int64) []ProjectStep {
+	steps := make([]ProjectStep, 0, len(s.Steps))
+	for i, step := range s.Steps {
+		steps = append(steps, ProjectStep{
+			ProjectID:        projectID,
+			Position:         i,
+			Title:            step.Title,
+			Body:             step.Body,
+			Images:           nonNil(step.Images),
+			EstimatedMinutes: step.EstimatedMinutes,
+			Tools:            nonNil(step.Tools),
+			SafetyNotes:      nonNil(step.SafetyNotes),
+		})
+	}
+	return steps
+}
+
+// ProjectParts devuelve las piezas del snapshot para projectID, en orden
+func (s *ProjectSnapshot) ProjectParts(projectID int64) []ProjectPart {
+	parts := make([]ProjectPart, 0, len(s.Parts))
+	for i, part := range s.Parts {
+		parts = append(parts, ProjectPart{
+			ProjectID:   projectID,
+			Position:    i,
+			Name:        part.Name,
+			Quantity:    part.Quantity,
+			Material:    part.Material,
+			ThicknessMM: part.ThicknessMM,
+			WidthMM:     part.WidthMM,
+			LengthMM:    part.LengthMM,
+			Grain:       part.Grain,
+		})
+	}
+	return parts
+}
+
+// FieldChange es un campo que cambio entre dos revisiones. Field es el nombre JSON del campo; los
+// pasos y las piezas se comparan por posicion, como "steps[2].body". Un paso o una pieza que solo
+// esta en una de las dos revisiones aparece entero, con el otro lado en null.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// DiffSnapshots devuelve los campos que cambian de from a to, en el orden de ProjectSnapshot
+func DiffSnapshots(from, to *ProjectSnapshot) []FieldChange {
+	changes := []FieldChange{}
+	diffStruct("", reflect.ValueOf(from).Elem(), reflect.ValueOf(to).Elem(), &changes)
+	return changes
+}
+
+// ChangedFields son los campos de primer nivel de changes, sin repetir: "steps[2].body" es "steps"
+func ChangedFields(changes []FieldChange) []string {
+	fields := []string{}
+	for _, change := range changes {
+		field, _, _ := strings.Cut(change.Field, "[")
+		if len(fields) == 0 || fields[len(fields)-1] != field {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func diffStruct(prefix string, from, to reflect.Value, changes *[]FieldChange) {
+	for i := 0; i < from.NumField(); i++ {
+		name, _, _ := strings.Cut(from.Type().Field(i).Tag.Get("json"), ",")
+		a, b := from.Field(i), to.Field(i)
+		if a.Kind() == reflect.Slice && a.Type().Elem().Kind() == reflect.Struct {
+			for j := 0; j < max(a.Len(), b.Len()); j++ {
+				field := fmt.Sprintf("%s%s[%d]", prefix, name, j)
+				switch {
+				case j >= a.Len():
+					*changes = append(*changes, FieldChange{Field: field, To: b.Index(j).Interface()})
+				case j >= b.Len():
+					*changes = append(*changes, FieldChange{Field: field, From: a.Index(j).Interface()})
+				default:
+					diffStruct(field+".", a.Index(j), b.Index(j), changes)
+				}
+			}
+			continue
+		}
+		if !equalValues(a, b) {
+			*changes = append(*changes, FieldChange{Field: prefix + name, From: a.Interface(), To: b.Interface()})
+		}
+	}
+}
+
+// equalValues compara dos valores del snapshot; una lista vacia y una nil son iguales
+func equalValues(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
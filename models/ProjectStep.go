@@ -0,0 +1,68 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ProjectStep es un paso del tutorial de un proyecto
+type ProjectStep struct {
+	ID        int64          `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	ProjectID int64          `json:"project_id"`
+	Position  int            `json:"position"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	BodyHTML  string         `json:"body_html"` // Body renderizado por el paquete markdown
+	Images    pq.StringArray `json:"images" gorm:"type:varchar[]"`
+	// EstimatedMinutes es cuanto lleva el paso; nil si el autor no lo estimo
+	EstimatedMinutes *int           `json:"estimated_minutes"`
+	Tools            pq.StringArray `json:"tools" gorm:"type:varchar[]"`
+	SafetyNotes      pq.StringArray `json:"safety_notes" gorm:"type:text[]"`
+}
+
+// TutorialText arma el texto del tutorial a partir de los pasos, en orden: numero y titulo de cada
+// paso seguidos de su cuerpo. Es lo que se guarda en Project.Tutorial.
+func TutorialText(steps []ProjectStep) string {
+	var b strings.Builder
+	for i, step := range steps {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(strconv.Itoa(i+1) + ". " + step.Title)
+		if body := strings.TrimSpace(step.Body); body != "" {
+			b.WriteString("\n\n" + body)
+		}
+	}
+	return b.String()
+}
+
+// TutorialHTML es el HTML de TutorialText: el numero y el titulo de cada paso como encabezado,
+// seguidos del HTML ya renderizado de su cuerpo. Es lo que se guarda en Project.TutorialHTML.
+func TutorialHTML(steps []ProjectStep) string {
+	var b strings.Builder
+	for i, step := range steps {
+		b.WriteString("<h3>" + strconv.Itoa(i+1) + ". " + html.EscapeString(step.Title) + "</h3>\n")
+		b.WriteString(step.BodyHTML)
+	}
+	return b.String()
+}
+
+// StepMinutes suma los minutos estimados de los pasos. ok es false si no hay pasos o si alguno no
+// tiene estimacion, porque la suma quedaria corta; entonces el tiempo de construccion del proyecto lo
+// sigue fijando el autor.
+func StepMinutes(steps []ProjectStep) (minutes int, ok bool) {
+	for _, step := range steps {
+		if step.EstimatedMinutes == nil {
+			return 0, false
+		}
+		minutes += *step.EstimatedMinutes
+	}
+	return minutes, len(steps) > 0
+}
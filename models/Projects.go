@@ -9,25 +9,32 @@ import (
 
 // Project representa a un proyecto de carpinteria con sus respectivos datos
 type Project struct {
-	ID            int8           `json:"id"`
-	CreatedAt     time.Time      `json:"created_at"`
-	Owner         int            `json:"owner"`
-	Title         string         `json:"title"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	AverageRating float32        `json:"average_rating"`
-	RatingCount   int            `json:"rating_count"`
-	MainMaterial  string         `json:"main_material"`
-	Materials     pq.StringArray `json:"materials" gorm:"type:varchar[]"`
-	Height        float32        `json:"height"`
-	Length        float32        `json:"length"`
-	Width		  float32        `json:"width"`
-	Tools         pq.StringArray `json:"tools" gorm:"type:varchar[]"`
-	Description   string         `json:"description"`
-	Style         pq.StringArray `json:"style" gorm:"type:varchar[]"`
-	Environment   string         `json:"environment"`
-	Portrait      string         `json:"portrait"`
-	Images        pq.StringArray `json:"images" gorm:"type:varchar[]"`
-	Tutorial      string         `json:"tutorial"`
-	TimeToBuild   int            `json:"time_to_build"`
-	IsPublic      bool           `json:"is_public"`
+	ID              int64           `json:"id"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Owner           int64           `json:"owner"`
+	Title           string          `json:"title"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	AverageRating   float32         `json:"average_rating"`
+	RatingCount     int             `json:"rating_count"`
+	Histogram       RatingHistogram `json:"rating_histogram" gorm:"embedded"`
+	BayesianScore   float64         `json:"bayesian_rating" gorm:"-"`
+	MainMaterial    string          `json:"main_material"`
+	Materials       pq.StringArray  `json:"materials" gorm:"type:varchar[]"`
+	Height          float32         `json:"height"`
+	Length          float32         `json:"length"`
+	Width           float32         `json:"width"`
+	Tools           pq.StringArray  `json:"tools" gorm:"type:varchar[]"`
+	Description     string          `json:"description"`
+	DescriptionHTML string          `json:"description_html"`
+	Style           pq.StringArray  `json:"style" gorm:"type:varchar[]"`
+	Environment     string          `json:"environment"`
+	Portrait        string          `json:"portrait"`
+	Images          pq.StringArray  `json:"images" gorm:"type:varchar[]"`
+	PortraitImage   *ImageSet       `json:"portrait_image,omitempty" gorm:"-"` // Portrait con sus variantes
+	ImageSets       []ImageSet      `json:"image_sets,omitempty" gorm:"-"`     // Images con sus variantes
+	Tutorial        string          `json:"tutorial"`
+	TutorialHTML    string          `json:"tutorial_html"`
+	TimeToBuild     int             `json:"time_to_build"`
+	IsPublic        bool            `json:"is_public"`
+	Search          *SearchMatch    `json:"search,omitempty" gorm:"-"`
 }
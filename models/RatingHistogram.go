@@ -0,0 +1,69 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+// Valores posibles de una valoracion
+const (
+	MinRatingValue = 1
+	MaxRatingValue = 5
+)
+
+// RatingHistogram cuenta las valoraciones de un proyecto por cantidad de estrellas
+type RatingHistogram struct {
+	One   int64 `json:"1" gorm:"column:ratings_1"`
+	Two   int64 `json:"2" gorm:"column:ratings_2"`
+	Three int64 `json:"3" gorm:"column:ratings_3"`
+	Four  int64 `json:"4" gorm:"column:ratings_4"`
+	Five  int64 `json:"5" gorm:"column:ratings_5"`
+}
+
+// bucket devuelve el contador de la cantidad de estrellas indicada, o nil si esta fuera de rango
+func (h *RatingHistogram) bucket(value int8) *int64 {
+	switch value {
+	case 1:
+		return &h.One
+	case 2:
+		return &h.Two
+	case 3:
+		return &h.Three
+	case 4:
+		return &h.Four
+	case 5:
+		return &h.Five
+	}
+	return nil
+}
+
+// Add suma (delta > 0) o resta (delta < 0) valoraciones con la cantidad de estrellas indicada
+func (h *RatingHistogram) Add(value int8, delta int64) {
+	if b := h.bucket(value); b != nil {
+		*b += delta
+	}
+}
+
+// Count devuelve la cantidad total de valoraciones
+func (h RatingHistogram) Count() int64 {
+	return h.One + h.Two + h.Three + h.Four + h.Five
+}
+
+// Sum devuelve la suma de estrellas de todas las valoraciones
+func (h RatingHistogram) Sum() int64 {
+	return h.One + 2*h.Two + 3*h.Three + 4*h.Four + 5*h.Five
+}
+
+// Average devuelve el promedio simple de estrellas
+func (h RatingHistogram) Average() float64 {
+	if h.Count() == 0 {
+		return 0
+	}
+	return float64(h.Sum()) / float64(h.Count())
+}
+
+// BayesianScore devuelve el promedio ponderado por un prior: equivale a sumar priorWeight votos
+// ficticios con valor priorMean, asi pocos votos altos no superan a muchos votos casi tan altos.
+func (h RatingHistogram) BayesianScore(priorMean, priorWeight float64) float64 {
+	n := float64(h.Count())
+	if n+priorWeight == 0 {
+		return 0
+	}
+	return (priorWeight*priorMean + float64(h.Sum())) / (priorWeight + n)
+}
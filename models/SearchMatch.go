@@ -0,0 +1,10 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+// SearchMatch describe por que un proyecto coincidio con una busqueda de texto. Title y Description
+// son fragmentos ya escapados como HTML donde los terminos encontrados van entre <mark> y </mark>.
+type SearchMatch struct {
+	Rank        float32 `json:"rank"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+}
@@ -0,0 +1,32 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Tipos de termino de la taxonomia
+const (
+	TaxonomyTool        = "tool"
+	TaxonomyMaterial    = "material"
+	TaxonomyStyle       = "style"
+	TaxonomyEnvironment = "environment"
+)
+
+// TaxonomyKinds son los tipos de termino, en el orden en que se listan
+var TaxonomyKinds = []string{TaxonomyTool, TaxonomyMaterial, TaxonomyStyle, TaxonomyEnvironment}
+
+// TaxonomyTerm es un valor canonico de herramienta, material, estilo o ambiente. Los proyectos
+// guardan el Slug; las etiquetas y los sinonimos son los nombres con que se lo reconoce.
+type TaxonomyTerm struct {
+	ID        int64          `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	Kind      string         `json:"kind"`
+	Slug      string         `json:"slug"`
+	Parent    *string        `json:"parent"`
+	LabelES   string         `json:"label_es" gorm:"column:label_es"`
+	LabelEN   string         `json:"label_en" gorm:"column:label_en"`
+	Synonyms  pq.StringArray `json:"synonyms" gorm:"type:text[]"`
+}
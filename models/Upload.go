@@ -0,0 +1,97 @@
+// Package models proporciona todos los modelos de datos del sistema
+package models
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Upload es una imagen subida por un usuario. El archivo esta en el storage; URL es la direccion
+// estable con la que se referencia desde proyectos y usuarios.
+type Upload struct {
+	ID            string          `json:"id" gorm:"primaryKey"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UserID        int64           `json:"user_id"`
+	ContentType   string          `json:"content_type"`
+	Size          int64           `json:"size"`
+	Width         int             `json:"width"`
+	Height        int             `json:"height"`
+	SHA256        string          `json:"sha256" gorm:"column:sha256"`
+	URL           string          `json:"url" gorm:"-"`
+	Variants      []UploadVariant `json:"variants" gorm:"foreignKey:UploadID"`
+	Blurhash      string          `json:"blurhash"`
+	DominantColor string          `json:"dominant_color"` // "#rrggbb"
+	// ProcessedAt es cuando se generaron las variantes; nil mientras esperan al worker
+	ProcessedAt *time.Time `json:"processed_at"`
+}
+
+// UploadVariant es una version reducida de un upload, guardada en el storage junto al original
+type UploadVariant struct {
+	UploadID    string `json:"-" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"primaryKey"` // nombre del archivo, p. ej. "w480.jpg"
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url" gorm:"-"`
+}
+
+// ImageSet es una imagen de un proyecto lista para <img srcset> o <picture>: la url original, sus
+// variantes por tipo y el placeholder que se muestra mientras carga
+type ImageSet struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	// Srcset tiene las variantes JPEG y PNG, que lee cualquier navegador, y el original, para <img srcset>
+	Srcset string `json:"srcset,omitempty"`
+	// Sources tiene un srcset por tipo de variante, para los <source> de <picture>. El navegador usa
+	// el primero que entiende, asi que van del tipo mas liviano al mas pesado.
+	Sources       []ImageSource `json:"sources,omitempty"`
+	Blurhash      string        `json:"blurhash,omitempty"`
+	DominantColor string        `json:"dominant_color,omitempty"`
+}
+
+// ImageSource son las variantes de un tipo de imagen, como van en <source type srcset>
+type ImageSource struct {
+	Type   string `json:"type"`
+	Srcset string `json:"srcset"`
+}
+
+// ImageSet arma el ImageSet del upload; las URL del upload y de sus variantes ya tienen que estar
+func (u *Upload) ImageSet() ImageSet {
+	set := ImageSet{
+		URL:           u.URL,
+		Width:         u.Width,
+		Height:        u.Height,
+		Blurhash:      u.Blurhash,
+		DominantColor: u.DominantColor,
+	}
+	byType := map[string][]string{}
+	sizes := map[string]int64{}
+	var types, fallback []string
+	for _, variant := range u.Variants {
+		entry := srcsetEntry(variant.URL, variant.Width)
+		if _, ok := byType[variant.ContentType]; !ok {
+			types = append(types, variant.ContentType)
+		}
+		byType[variant.ContentType] = append(byType[variant.ContentType], entry)
+		sizes[variant.ContentType] += variant.Size
+		if variant.ContentType == "image/jpeg" || variant.ContentType == "image/png" {
+			fallback = append(fallback, entry)
+		}
+	}
+	slices.SortStableFunc(types, func(a, b string) int { return cmp.Compare(sizes[a], sizes[b]) })
+	for _, contentType := range types {
+		set.Sources = append(set.Sources, ImageSource{Type: contentType, Srcset: strings.Join(byType[contentType], ", ")})
+	}
+	// el original es siempre la version mas grande
+	set.Srcset = strings.Join(append(fallback, srcsetEntry(u.URL, u.Width)), ", ")
+	return set
+}
+
+func srcsetEntry(url string, width int) string {
+	return url + " " + strconv.Itoa(width) + "w"
+}
@@ -5,11 +5,16 @@ import "time"
 
 // User representa a un usuario con sus respectivos datos
 type User struct {
-	ID             int8      `json:"id"`
+	ID             int64     `json:"id"`
 	CreatedAt      time.Time `json:"created_at"`
 	Username       string    `json:"username"`
 	Email          string    `json:"email"`
 	Reputation     float32   `json:"reputation"`
-	ProfilePicture int8      `json:"profile_picture"`
-	FirebaseUID    string    `json:"firebase_uid"`
+	ProfilePicture int64     `json:"profile_picture"`
+	// AvatarUpload es el id del upload con la foto propia; si esta, tiene prioridad sobre ProfilePicture
+	AvatarUpload *string `json:"avatar_upload"`
+	// AvatarURL es la foto que hay que mostrar: la propia, la elegida o la primera por defecto
+	AvatarURL   string `json:"avatar_url" gorm:"-"`
+	FirebaseUID string `json:"firebase_uid"`
+	IsAdmin     bool   `json:"is_admin"`
 }
@@ -0,0 +1,125 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Codec firma y verifica los cursores con HMAC-SHA256
+type Codec struct {
+	key []byte
+}
+
+// NewCodec crea un Codec con la clave indicada. Todas las instancias de la api deben compartirla
+// para que un cursor emitido por una sirva en otra.
+func NewCodec(key []byte) *Codec {
+	return &Codec{key: key}
+}
+
+// NewRandomCodec crea un Codec con una clave aleatoria: los cursores dejan de valer al reiniciar
+func NewRandomCodec() *Codec {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("pagination: generating cursor key: %v", err))
+	}
+	return NewCodec(key)
+}
+
+// cursor es el contenido firmado de un cursor
+type cursor struct {
+	Scope string `json:"s"`
+	Key   string `json:"k"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    int64  `json:"i"`
+	// Pinned son los valores fijados en la primera pagina que las siguientes tienen que reusar
+	Pinned map[string]string `json:"p,omitempty"`
+}
+
+func (c *Codec) encode(req Request, after Keyset) string {
+	value, err := normalize(after.Value)
+	if err != nil {
+		panic(err)
+	}
+	payload, err := json.Marshal(cursor{
+		Scope:  req.scope,
+		Key:    req.Order.Key,
+		Desc:   req.Order.Desc,
+		Value:  formatValue(value),
+		ID:     after.ID,
+		Pinned: req.pinned,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+func (c *Codec) decode(raw, scope string, order Order, kind Kind) (*Keyset, map[string]string, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidParams)
+
+	encodedPayload, encodedMAC, ok := strings.Cut(raw, ".")
+	if !ok {
+		return nil, nil, invalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, nil, invalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return nil, nil, invalid
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(payload, &cur); err != nil || cur.Scope != scope {
+		return nil, nil, invalid
+	}
+	if cur.Key != order.Key || cur.Desc != order.Desc {
+		return nil, nil, fmt.Errorf("%w: cursor was issued for a different sort or order", ErrInvalidParams)
+	}
+	value, err := parseValue(cur.Value, kind)
+	if err != nil {
+		return nil, nil, invalid
+	}
+	return &Keyset{Value: value, ID: cur.ID}, cur.Pinned, nil
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return v.(string)
+	}
+}
+
+func parseValue(raw string, kind Kind) (any, error) {
+	switch kind {
+	case Int:
+		return strconv.ParseInt(raw, 10, 64)
+	case Float:
+		return strconv.ParseFloat(raw, 64)
+	case Time:
+		return time.Parse(time.RFC3339Nano, raw)
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,214 @@
+// Package pagination implementa la paginacion por keyset de los endpoints que devuelven listas.
+// Lee limit, sort, order, cursor e include_total de la query string, firma los cursores para que
+// el cliente no pueda fabricarlos y arma el sobre de respuesta con next_cursor y total.
+//
+// El cursor guarda el valor de la clave de orden y el id del ultimo elemento devuelto; la pagina
+// siguiente se pide con "(clave, id) > (valor, id)" (o "<" en orden descendente), lo que usa el
+// indice y cuesta lo mismo en la pagina 1 que en la 10.000, a diferencia de OFFSET.
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidParams se devuelve ante parametros de paginacion invalidos (el handler responde 400)
+var ErrInvalidParams = errors.New("invalid pagination parameters")
+
+// Kind es el tipo del valor de una clave de orden
+type Kind int
+
+// Tipos de clave de orden soportados
+const (
+	Int Kind = iota
+	Float
+	Time
+	String
+)
+
+// SortKey es un campo por el que se puede ordenar un listado
+type SortKey struct {
+	Name string
+	Kind Kind
+	// Desc es el sentido por defecto cuando no se pasa order
+	Desc bool
+}
+
+// Spec describe la paginacion que acepta un endpoint
+type Spec struct {
+	// Scope identifica al endpoint: un cursor emitido por un listado no sirve para otro
+	Scope        string
+	Keys         []SortKey
+	DefaultSort  string
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// key busca una clave de orden por nombre
+func (s Spec) key(name string) (SortKey, bool) {
+	for _, k := range s.Keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return SortKey{}, false
+}
+
+// WithDefaultSort devuelve una copia del Spec con otro orden por defecto
+func (s Spec) WithDefaultSort(name string) Spec {
+	s.DefaultSort = name
+	return s
+}
+
+// Order es la clave de orden elegida y su sentido. El desempate es siempre por id en el mismo sentido.
+type Order struct {
+	Key  string
+	Desc bool
+}
+
+// Keyset es la posicion de un elemento en el orden: el valor de la clave y su id
+type Keyset struct {
+	Value any
+	ID    int64
+}
+
+// Request es la pagina pedida, ya validada
+type Request struct {
+	Order Order
+	// After es la posicion del ultimo elemento de la pagina anterior, nil en la primera pagina
+	After *Keyset
+	Limit int
+	// WithTotal pide contar todos los elementos que cumplen el filtro
+	WithTotal bool
+
+	scope  string
+	kind   Kind
+	pinned map[string]string
+}
+
+// Pin fija un valor del que depende el orden para que las paginas siguientes usen el mismo, aunque
+// cambie mientras tanto. Viaja firmado en el cursor.
+func (r *Request) Pin(name, value string) {
+	if r.pinned == nil {
+		r.pinned = map[string]string{}
+	}
+	r.pinned[name] = value
+}
+
+// Pinned devuelve el valor que fijo la primera pagina, si el cursor lo trae
+func (r Request) Pinned(name string) (string, bool) {
+	value, ok := r.pinned[name]
+	return value, ok
+}
+
+// Fetch es la cantidad de filas que debe traer el repositorio: una mas que Limit para saber si hay
+// pagina siguiente sin contar
+func (r Request) Fetch() int {
+	return r.Limit + 1
+}
+
+// Page es el sobre de respuesta de los listados
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// Parse lee los parametros de paginacion de la query string segun el Spec del endpoint
+func (c *Codec) Parse(values url.Values, spec Spec) (Request, error) {
+	req := Request{Limit: spec.DefaultLimit, scope: spec.Scope}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > spec.MaxLimit {
+			return Request{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidParams, spec.MaxLimit)
+		}
+		req.Limit = limit
+	}
+
+	name := values.Get("sort")
+	if name == "" {
+		name = spec.DefaultSort
+	}
+	key, ok := spec.key(name)
+	if !ok {
+		return Request{}, fmt.Errorf("%w: sort must be one of %s", ErrInvalidParams, spec.keyNames())
+	}
+	req.Order = Order{Key: key.Name, Desc: key.Desc}
+	req.kind = key.Kind
+
+	switch values.Get("order") {
+	case "":
+	case "asc":
+		req.Order.Desc = false
+	case "desc":
+		req.Order.Desc = true
+	default:
+		return Request{}, fmt.Errorf("%w: order must be asc or desc", ErrInvalidParams)
+	}
+
+	if raw := values.Get("cursor"); raw != "" {
+		after, pinned, err := c.decode(raw, spec.Scope, req.Order, key.Kind)
+		if err != nil {
+			return Request{}, err
+		}
+		req.After = after
+		req.pinned = pinned
+	}
+
+	if raw := values.Get("include_total"); raw != "" {
+		withTotal, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Request{}, fmt.Errorf("%w: include_total must be true or false", ErrInvalidParams)
+		}
+		req.WithTotal = withTotal
+	}
+	return req, nil
+}
+
+func (s Spec) keyNames() string {
+	names := ""
+	for i, k := range s.Keys {
+		if i > 0 {
+			names += ", "
+		}
+		names += k.Name
+	}
+	return names
+}
+
+// NewPage arma la respuesta a partir de las filas que devolvio el repositorio (hasta Fetch()).
+// key devuelve la posicion de un elemento segun el orden pedido y se usa para el proximo cursor.
+func NewPage[T any](c *Codec, req Request, rows []T, total *int64, key func(T) Keyset) Page[T] {
+	page := Page[T]{Items: rows, Total: total}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(rows) > req.Limit {
+		page.Items = rows[:req.Limit]
+		page.NextCursor = c.encode(req, key(page.Items[req.Limit-1]))
+	}
+	return page
+}
+
+// normalize lleva los valores de las claves a int64, float64, time.Time o string
+func normalize(value any) (any, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case time.Time:
+		return v, nil
+	case string:
+		return v, nil
+	}
+	return nil, fmt.Errorf("pagination: unsupported key type %T", value)
+}
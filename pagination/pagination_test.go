@@ -0,0 +1,203 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSpec = Spec{
+	Scope: "projects",
+	Keys: []SortKey{
+		{Name: "created_at", Kind: Time, Desc: true},
+		{Name: "rating", Kind: Float, Desc: true},
+	},
+	DefaultSort: "created_at", DefaultLimit: 2, MaxLimit: 50,
+}
+
+// nextCursor arma el cursor que sigue a after en la pagina req
+func nextCursor(t *testing.T, c *Codec, req Request, after Keyset) string {
+	t.Helper()
+	rows := []Keyset{after, {}}
+	req.Limit = 1
+	page := NewPage(c, req, rows, nil, func(k Keyset) Keyset { return k })
+	if page.NextCursor == "" {
+		t.Fatal("no next cursor")
+	}
+	return page.NextCursor
+}
+
+func parse(c *Codec, spec Spec, query string) (Request, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		panic(err)
+	}
+	return c.Parse(values, spec)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := NewRandomCodec()
+	first, err := parse(c, testSpec, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 3, 14, 9, 26, 53, 123456789, time.UTC)
+	cursor := nextCursor(t, c, first, Keyset{Value: created, ID: 42})
+
+	next, err := parse(c, testSpec, "cursor="+cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.After == nil || !next.After.Value.(time.Time).Equal(created) || next.After.ID != 42 {
+		t.Errorf("after = %+v, want %v and id 42", next.After, created)
+	}
+	if next.Order != (Order{Key: "created_at", Desc: true}) {
+		t.Errorf("order = %+v, want created_at desc", next.Order)
+	}
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	c := NewRandomCodec()
+	first, err := parse(c, testSpec, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := nextCursor(t, c, first, Keyset{Value: time.Now(), ID: 42})
+	payload, mac, _ := strings.Cut(cursor, ".")
+
+	// flip cambia el primer caracter de s por otro valido en base64url
+	flip := func(s string) string {
+		if s[0] == 'A' {
+			return "B" + s[1:]
+		}
+		return "A" + s[1:]
+	}
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"signature changed", payload + "." + flip(mac)},
+		{"payload changed", flip(payload) + "." + mac},
+		{"signature missing", payload},
+		{"not base64", "!!!." + mac},
+		{"signed with another key", nextCursor(t, NewRandomCodec(), first, Keyset{Value: time.Now(), ID: 42})},
+	}
+	for _, tt := range tests {
+		if _, err := parse(c, testSpec, "cursor="+url.QueryEscape(tt.cursor)); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%s: err = %v, want ErrInvalidParams", tt.name, err)
+		}
+	}
+}
+
+func TestCursorBoundToScopeAndOrder(t *testing.T) {
+	c := NewRandomCodec()
+	first, err := parse(c, testSpec, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := url.QueryEscape(nextCursor(t, c, first, Keyset{Value: time.Now(), ID: 42}))
+
+	other := testSpec
+	other.Scope = "comments"
+	tests := []struct {
+		name  string
+		spec  Spec
+		query string
+	}{
+		{"other endpoint", other, "cursor=" + cursor},
+		{"other sort key", testSpec, "sort=rating&cursor=" + cursor},
+		{"other order", testSpec, "order=asc&cursor=" + cursor},
+	}
+	for _, tt := range tests {
+		if _, err := parse(c, tt.spec, tt.query); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%s: err = %v, want ErrInvalidParams", tt.name, err)
+		}
+	}
+}
+
+// TestCursorKeepsPinnedValues controla que un valor fijado en la primera pagina, como el promedio
+// global de la busqueda (site_mean), viaje firmado a las siguientes
+func TestCursorKeepsPinnedValues(t *testing.T) {
+	c := NewRandomCodec()
+	first, err := parse(c, testSpec, "sort=rating")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := first.Pinned("site_mean"); ok {
+		t.Fatal("first page already has a pinned value")
+	}
+	first.Pin("site_mean", "3.75")
+	cursor := nextCursor(t, c, first, Keyset{Value: 4.2, ID: 7})
+
+	next, err := parse(c, testSpec, "sort=rating&cursor="+url.QueryEscape(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mean, ok := next.Pinned("site_mean"); !ok || mean != "3.75" {
+		t.Errorf("pinned site_mean = %q, %v; want 3.75", mean, ok)
+	}
+	// el cursor de la pagina 3 lo sigue llevando
+	third, err := parse(c, testSpec, "sort=rating&cursor="+url.QueryEscape(nextCursor(t, c, next, Keyset{Value: 4.0, ID: 3})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mean, _ := third.Pinned("site_mean"); mean != "3.75" {
+		t.Errorf("pinned site_mean on page 3 = %q, want 3.75", mean)
+	}
+}
+
+func TestParseRejectsInvalidParams(t *testing.T) {
+	c := NewRandomCodec()
+	for _, query := range []string{
+		"limit=0", "limit=51", "limit=dos", "sort=title", "order=up", "include_total=maybe",
+	} {
+		if _, err := parse(c, testSpec, query); !errors.Is(err, ErrInvalidParams) {
+			t.Errorf("%s: err = %v, want ErrInvalidParams", query, err)
+		}
+	}
+}
+
+type item struct {
+	id    int64
+	score float64
+}
+
+func itemKey(i item) Keyset { return Keyset{Value: i.score, ID: i.id} }
+
+func TestSliceBreaksTiesByID(t *testing.T) {
+	items := []item{{5, 2}, {3, 1}, {9, 2}, {1, 2}, {7, 1}, {2, 3}}
+	tests := []struct {
+		desc bool
+		want []int64
+	}{
+		{false, []int64{3, 7, 1, 5, 9, 2}},
+		{true, []int64{2, 9, 5, 1, 7, 3}},
+	}
+	for _, tt := range tests {
+		// de a dos por pagina, asi los cursores caen en medio de los empates
+		req := Request{Order: Order{Key: "score", Desc: tt.desc}, Limit: 2}
+		var got []int64
+		for {
+			rows, _ := Slice(items, req, itemKey)
+			for _, row := range rows[:min(len(rows), req.Limit)] {
+				got = append(got, row.id)
+			}
+			if len(rows) <= req.Limit {
+				break
+			}
+			after := itemKey(rows[req.Limit-1])
+			req.After = &after
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("desc=%v: ids = %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+
+	req := Request{Order: Order{Key: "score"}, Limit: 10, WithTotal: true}
+	if _, total := Slice(items, req, itemKey); total == nil || *total != int64(len(items)) {
+		t.Errorf("total = %v, want %d", total, len(items))
+	}
+}
@@ -0,0 +1,81 @@
+package pagination
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Slice aplica la pagina sobre elementos ya filtrados en memoria, con la misma semantica que el
+// keyset en SQL. La usan los repositorios en memoria; key devuelve la posicion de cada elemento.
+func Slice[T any](items []T, req Request, key func(T) Keyset) ([]T, *int64) {
+	var total *int64
+	if req.WithTotal {
+		n := int64(len(items))
+		total = &n
+	}
+
+	keys := make([]Keyset, len(items))
+	for i, item := range items {
+		keys[i] = key(item)
+	}
+	less := func(a, b Keyset) bool {
+		c := compare(a.Value, b.Value)
+		if c == 0 {
+			c = compare(a.ID, b.ID)
+		}
+		if req.Order.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+
+	index := make([]int, len(items))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool { return less(keys[index[i]], keys[index[j]]) })
+
+	out := make([]T, 0, req.Fetch())
+	for _, i := range index {
+		if req.After != nil && !less(*req.After, keys[i]) {
+			continue
+		}
+		if len(out) == req.Fetch() {
+			break
+		}
+		out = append(out, items[i])
+	}
+	return out, total
+}
+
+// compare ordena dos valores de clave del mismo tipo
+func compare(a, b any) int {
+	a, _ = normalize(a)
+	b, _ = normalize(b)
+	switch x := a.(type) {
+	case int64:
+		y := b.(int64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(x, b.(string))
+	}
+	return 0
+}
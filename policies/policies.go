@@ -0,0 +1,100 @@
+// Package policies centraliza las reglas de autorizacion sobre los recursos del sistema
+package policies
+
+import (
+	"errors"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+var (
+	// ErrUnauthenticated se devuelve cuando la accion requiere un usuario autenticado
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrForbidden se devuelve cuando el usuario no tiene permisos sobre el recurso
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Authenticated exige que haya un usuario registrado
+func Authenticated(user *models.User) error {
+	if user == nil {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+// Owns exige que el usuario sea el duenio del recurso o un administrador
+func Owns(user *models.User, ownerID int64) error {
+	if err := Authenticated(user); err != nil {
+		return err
+	}
+	if user.IsAdmin || user.ID == ownerID {
+		return nil
+	}
+	return ErrForbidden
+}
+
+// Admin exige que el usuario sea administrador
+func Admin(user *models.User) error {
+	if err := Authenticated(user); err != nil {
+		return err
+	}
+	if !user.IsAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// ManageUser controla la edicion o borrado de una cuenta
+func ManageUser(user *models.User, target *models.User) error {
+	return Owns(user, target.ID)
+}
+
+// ManageProject controla la edicion o borrado de un proyecto
+func ManageProject(user *models.User, project *models.Project) error {
+	return Owns(user, project.Owner)
+}
+
+// ManageComment controla el borrado de un comentario
+func ManageComment(user *models.User, comment *models.Comment) error {
+	return Owns(user, comment.UserID)
+}
+
+// ManageRating controla la edicion de una valoracion
+func ManageRating(user *models.User, rating *models.Rating) error {
+	return Owns(user, rating.UserID)
+}
+
+// ViewRatings controla la consulta de las valoraciones de un usuario
+func ViewRatings(user *models.User, ownerID int64) error {
+	return Owns(user, ownerID)
+}
+
+// ManageProjectList controla la edicion de una lista y de sus items
+func ManageProjectList(user *models.User, list *models.ProjectList) error {
+	return Owns(user, list.UserID)
+}
+
+// ManageInventory controla la lectura y edicion del inventario de un usuario
+func ManageInventory(user *models.User, ownerID int64) error {
+	return Owns(user, ownerID)
+}
+
+// ManageTaxonomy controla el alta y la edicion de terminos de la taxonomia
+func ManageTaxonomy(user *models.User) error {
+	return Admin(user)
+}
+
+// ManagePrices controla el alta, la edicion y la importacion de precios del catalogo
+func ManagePrices(user *models.User) error {
+	return Admin(user)
+}
+
+// ManageProfilePictures controla el alta, el retiro y el orden de las fotos de perfil por defecto
+func ManageProfilePictures(user *models.User) error {
+	return Admin(user)
+}
+
+// ManageUpload controla el borrado de una imagen subida
+func ManageUpload(user *models.User, upload *models.Upload) error {
+	return Owns(user, upload.UserID)
+}
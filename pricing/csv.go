@@ -0,0 +1,145 @@
+package pricing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// MaxCSVRows es la mayor cantidad de precios de una lista importada
+const MaxCSVRows = 10000
+
+// requiredColumns son las columnas obligatorias de una lista de precios; region y supplier son
+// opcionales
+var requiredColumns = []string{"material", "unit", "price", "currency", "effective_date"}
+
+// Row es un precio leido de la lista, con su linea en el archivo
+type Row struct {
+	Line  int
+	Price models.MaterialPrice
+}
+
+// RowError es el problema de una linea de la lista; la linea 1 es el encabezado
+type RowError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ParseCSV lee una lista de precios. La primera linea nombra las columnas, en cualquier orden y sin
+// importar mayusculas; el separador puede ser coma o punto y coma, como la exportan las planillas en
+// español, y el precio acepta coma decimal si no hay punto. Devuelve las filas validas y los
+// problemas de cada linea; quien importa decide si alcanza con que haya errores para rechazar todo.
+// Material queda como vino, para resolverlo contra la taxonomia.
+func ParseCSV(r io.Reader) ([]Row, []RowError) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, []RowError{{Line: 1, Message: "could not read the file: " + err.Error()}}
+	}
+	data = bytes.TrimPrefix(data, []byte("\uFEFF")) // Excel agrega BOM al guardar en UTF-8
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	header, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	record, err := reader.Read()
+	if err != nil {
+		return nil, []RowError{{Line: 1, Message: "missing header: " + err.Error()}}
+	}
+	columns := map[string]int{}
+	for i, name := range record {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, []RowError{{Line: 1, Message: "missing column " + name}}
+		}
+	}
+
+	var rows []Row
+	var errs []RowError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.Line
+			}
+			errs = append(errs, RowError{Line: line, Message: err.Error()})
+			continue
+		}
+		if isBlank(record) {
+			continue
+		}
+		if len(rows)+len(errs) >= MaxCSVRows {
+			errs = append(errs, RowError{Line: line, Message: fmt.Sprintf("a price list cannot have more than %d rows", MaxCSVRows)})
+			break
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		price, message := parseRow(field)
+		if message != "" {
+			errs = append(errs, RowError{Line: line, Message: message})
+			continue
+		}
+		rows = append(rows, Row{Line: line, Price: price})
+	}
+	return rows, errs
+}
+
+func parseRow(field func(string) string) (models.MaterialPrice, string) {
+	price := models.MaterialPrice{
+		Material: field("material"),
+		Unit:     strings.ToLower(field("unit")),
+		Currency: strings.ToUpper(field("currency")),
+		Region:   strings.ToUpper(field("region")),
+		Supplier: field("supplier"),
+	}
+
+	raw := field("price")
+	if !strings.Contains(raw, ".") {
+		raw = strings.ReplaceAll(raw, ",", ".")
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return price, fmt.Sprintf("price %q is not a number", field("price"))
+	}
+	price.Price = value
+
+	if raw := field("effective_date"); raw != "" {
+		date, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return price, fmt.Sprintf("effective_date %q must be YYYY-MM-DD", raw)
+		}
+		price.EffectiveDate = date
+	}
+	return price, Validate(price)
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package pricing
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		csv      string
+		want     []Row
+		wantErrs []RowError
+	}{
+		{
+			name: "comma separated",
+			csv:  "material,unit,price,currency,effective_date\npine,cubic_metre,1200.50,usd,2026-03-01\n",
+			want: []Row{{Line: 2, Price: models.MaterialPrice{Material: "pine", Unit: "cubic_metre", Price: 1200.5,
+				Currency: "USD", EffectiveDate: date}}},
+		},
+		{
+			name: "semicolon separated with decimal comma, any column order and case",
+			csv:  "Region;Effective_Date;Material;Unit;Price;Currency;Supplier\nar-b;2026-03-01;Pino;Board_Foot;12,5;ars;Maderera Sur\n",
+			want: []Row{{Line: 2, Price: models.MaterialPrice{Material: "Pino", Unit: "board_foot", Price: 12.5,
+				Currency: "ARS", Region: "AR-B", Supplier: "Maderera Sur", EffectiveDate: date}}},
+		},
+		{
+			name: "quoted decimal comma in a comma separated file",
+			csv:  "material,unit,price,currency,effective_date\nscrews,unit,\"3,75\",ARS,2026-03-01\n",
+			want: []Row{{Line: 2, Price: models.MaterialPrice{Material: "screws", Unit: "unit", Price: 3.75,
+				Currency: "ARS", EffectiveDate: date}}},
+		},
+		{
+			name: "byte order mark from Excel",
+			csv:  "\uFEFFmaterial;unit;price;currency;effective_date\r\npine;unit;10;ARS;2026-03-01\r\n",
+			want: []Row{{Line: 2, Price: models.MaterialPrice{Material: "pine", Unit: "unit", Price: 10,
+				Currency: "ARS", EffectiveDate: date}}},
+		},
+		{
+			name: "errors keep the line of the file",
+			csv: "material,unit,price,currency,effective_date\n" +
+				"pine,unit,10,ARS,2026-03-01\n" +
+				"\n" +
+				"pine,unit,diez,ARS,2026-03-01\n" +
+				"pine,unit,10,ARS,01/03/2026\n" +
+				"pine,litre,10,ARS,2026-03-01\n",
+			want: []Row{{Line: 2, Price: models.MaterialPrice{Material: "pine", Unit: "unit", Price: 10,
+				Currency: "ARS", EffectiveDate: date}}},
+			wantErrs: []RowError{
+				{Line: 4, Message: `price "diez" is not a number`},
+				{Line: 5, Message: `effective_date "01/03/2026" must be YYYY-MM-DD`},
+				{Line: 6, Message: "unit must be board_foot, cubic_metre, square_metre, linear_metre or unit"},
+			},
+		},
+		{
+			name:     "missing column",
+			csv:      "material,unit,price,effective_date\npine,unit,10,2026-03-01\n",
+			wantErrs: []RowError{{Line: 1, Message: "missing column currency"}},
+		},
+		{
+			name:     "empty file",
+			csv:      "",
+			wantErrs: []RowError{{Line: 1, Message: "missing header: EOF"}},
+		},
+	}
+	for _, tt := range tests {
+		rows, errs := ParseCSV(strings.NewReader(tt.csv))
+		if !reflect.DeepEqual(rows, tt.want) {
+			t.Errorf("%s: rows = %+v, want %+v", tt.name, rows, tt.want)
+		}
+		if !reflect.DeepEqual(errs, tt.wantErrs) {
+			t.Errorf("%s: errors = %+v, want %+v", tt.name, errs, tt.wantErrs)
+		}
+	}
+
+	// una comilla sin cerrar se informa en la linea donde empieza
+	rows, errs := ParseCSV(strings.NewReader("material,unit,price,currency,effective_date\n" +
+		"pine,unit,\"10,ARS,2026-03-01\n"))
+	if len(rows) != 0 || len(errs) != 1 || errs[0].Line != 2 {
+		t.Errorf("unclosed quote: rows = %+v, errors = %+v; want one error on line 2", rows, errs)
+	}
+}
+
+func TestParseCSVMaxRows(t *testing.T) {
+	var csv strings.Builder
+	csv.WriteString("material,unit,price,currency,effective_date\n")
+	for range MaxCSVRows + 5 {
+		csv.WriteString("pine,unit,10,ARS,2026-03-01\n")
+	}
+	rows, errs := ParseCSV(strings.NewReader(csv.String()))
+	if len(rows) != MaxCSVRows {
+		t.Errorf("%d rows, want %d", len(rows), MaxCSVRows)
+	}
+	// se corta en la primera fila de mas, que es la linea MaxCSVRows+2 contando el encabezado
+	want := []RowError{{Line: MaxCSVRows + 2, Message: "a price list cannot have more than 10000 rows"}}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors = %+v, want %+v", errs, want)
+	}
+}
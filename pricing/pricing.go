@@ -0,0 +1,220 @@
+// Package pricing estima el costo de los materiales de un proyecto con el catalogo de precios y lee
+// las listas de precios de los proveedores en CSV.
+package pricing
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/carpentry-hub/woodys-backend/cutlist"
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// Origen de la cantidad de una linea del presupuesto
+const (
+	SourceParts     = "parts"     // medida sobre la lista de piezas
+	SourceMaterials = "materials" // el proyecto solo nombra el material: se cuenta una unidad
+)
+
+// Limites de un precio del catalogo
+const (
+	MaxPrice    = 1e9
+	maxSupplier = 100
+)
+
+var (
+	currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+	// regionCode es un pais ISO 3166-1 o una subdivision ISO 3166-2 (AR, AR-B)
+	regionCode = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+)
+
+// measuredUnits son las unidades que se pueden medir sobre la lista de piezas, en orden de preferencia
+var measuredUnits = []string{
+	models.PriceCubicMetre, models.PriceBoardFoot, models.PriceSquareMetre, models.PriceLinearMetre,
+}
+
+// Validate controla un precio del catalogo y devuelve el problema, o "" si es valido. Moneda y
+// region ya tienen que estar en mayusculas.
+func Validate(price models.MaterialPrice) string {
+	switch {
+	case price.Material == "":
+		return "material cannot be empty"
+	case !slices.Contains(models.PriceUnits, price.Unit):
+		return "unit must be board_foot, cubic_metre, square_metre, linear_metre or unit"
+	case !(price.Price > 0 && price.Price <= MaxPrice):
+		return "price must be greater than 0 and at most 1000000000"
+	case !ValidCurrency(price.Currency):
+		return "currency must be an ISO 4217 code such as ARS or USD"
+	case !ValidRegion(price.Region):
+		return "region must be empty or an ISO 3166 code such as AR or AR-B"
+	case price.EffectiveDate.IsZero():
+		return "effective_date is required"
+	case utf8.RuneCountInString(price.Supplier) > maxSupplier:
+		return "supplier cannot exceed 100 characters"
+	}
+	return ""
+}
+
+// ValidCurrency indica si code es un codigo de moneda ISO 4217 en mayusculas
+func ValidCurrency(code string) bool {
+	return currencyCode.MatchString(code)
+}
+
+// ValidRegion indica si code es vacio (precio general) o un codigo ISO 3166 en mayusculas
+func ValidRegion(code string) bool {
+	return code == "" || regionCode.MatchString(code)
+}
+
+// Options son la moneda, la region y la fecha del presupuesto. Waste es el desperdicio que se suma a
+// lo medido sobre las piezas, como fraccion (0.1 es 10%).
+type Options struct {
+	Currency string
+	Region   string
+	Date     time.Time
+	Waste    float64
+}
+
+// Line es un material del presupuesto. Sin precio, UnitPrice y Subtotal quedan en nil.
+type Line struct {
+	Material string  `json:"material"`
+	Source   string  `json:"source"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	// PricedAs es el material cuyo precio se uso cuando el material no tiene precio propio y se toma
+	// el de un padre de la taxonomia, por ejemplo roble con el precio de madera dura
+	PricedAs      string     `json:"priced_as,omitempty"`
+	PriceID       int64      `json:"price_id,omitempty"`
+	Region        *string    `json:"region,omitempty"`
+	EffectiveDate *time.Time `json:"effective_date,omitempty"`
+	UnitPrice     *float64   `json:"unit_price"`
+	Subtotal      *float64   `json:"subtotal"`
+}
+
+// Breakdown es el presupuesto de materiales de un proyecto. Total suma solo las lineas con precio;
+// Missing son los materiales que quedaron afuera.
+type Breakdown struct {
+	Currency string   `json:"currency"`
+	Region   string   `json:"region"`
+	Date     string   `json:"date"`
+	Waste    float64  `json:"waste"`
+	Lines    []Line   `json:"lines"`
+	Total    float64  `json:"total"`
+	Missing  []string `json:"missing"`
+	Complete bool     `json:"complete"`
+}
+
+// Materials son los materiales a cotizar: los del proyecto y los de las piezas que no esten entre
+// ellos, sin repetir. Sirve para pedir los precios antes de llamar a Estimate.
+func Materials(project models.Project, parts []models.ProjectPart) []string {
+	materials := make([]string, 0, len(project.Materials))
+	for _, material := range project.Materials {
+		if material != "" && !slices.Contains(materials, material) {
+			materials = append(materials, material)
+		}
+	}
+	for _, part := range parts {
+		if !slices.Contains(materials, part.Material) {
+			materials = append(materials, part.Material)
+		}
+	}
+	return materials
+}
+
+// Estimate arma el presupuesto de materiales de un proyecto. Los materiales con piezas se cotizan
+// por volumen, superficie o largo, lo primero que tenga precio; los que el proyecto solo nombra se
+// cotizan por unidad. prices son los precios candidatos (ListEffective) y ancestors da los padres de
+// un material en la taxonomia, que se usan si el material no tiene precio propio. Entre dos precios
+// gana el material mas especifico, despues el de la region pedida sobre el general y despues la
+// fecha mas reciente.
+func Estimate(
+	project models.Project, parts []models.ProjectPart, prices []models.MaterialPrice,
+	ancestors func(material string) []string, opts Options,
+) Breakdown {
+	breakdown := Breakdown{
+		Currency: opts.Currency,
+		Region:   opts.Region,
+		Date:     opts.Date.Format(time.DateOnly),
+		Waste:    round(opts.Waste*100, 2),
+		Lines:    []Line{},
+		Missing:  []string{},
+	}
+
+	measured := map[string]map[string]float64{}
+	for _, part := range parts {
+		if measured[part.Material] == nil {
+			measured[part.Material] = map[string]float64{}
+		}
+		quantities := measured[part.Material]
+		quantities[models.PriceCubicMetre] += cutlist.CubicMetres(part)
+		quantities[models.PriceBoardFoot] += cutlist.BoardFeet(part)
+		quantities[models.PriceSquareMetre] += part.WidthMM * part.LengthMM / 1e6 * float64(part.Quantity)
+		quantities[models.PriceLinearMetre] += part.LengthMM / 1e3 * float64(part.Quantity)
+	}
+
+	for _, material := range Materials(project, parts) {
+		line := Line{Material: material, Source: SourceMaterials}
+		units := []string{models.PriceUnit}
+		if _, ok := measured[material]; ok {
+			line.Source = SourceParts
+			units = measuredUnits
+		}
+
+		price, found := choose(prices, append([]string{material}, ancestors(material)...), units, opts.Region)
+		if !found {
+			breakdown.Missing = append(breakdown.Missing, material)
+			breakdown.Lines = append(breakdown.Lines, line)
+			continue
+		}
+
+		line.Unit = price.Unit
+		line.Quantity = 1
+		if line.Source == SourceParts {
+			// seis decimales como los metros cubicos de la lista de corte
+			line.Quantity = round(measured[material][price.Unit]*(1+opts.Waste), 6)
+		}
+		if price.Material != material {
+			line.PricedAs = price.Material
+		}
+		unitPrice := price.Price
+		subtotal := round(line.Quantity*unitPrice, 2)
+		line.PriceID = price.ID
+		line.Region = &price.Region
+		line.EffectiveDate = &price.EffectiveDate
+		line.UnitPrice = &unitPrice
+		line.Subtotal = &subtotal
+		breakdown.Total += subtotal
+		breakdown.Lines = append(breakdown.Lines, line)
+	}
+	breakdown.Total = round(breakdown.Total, 2)
+	breakdown.Complete = len(breakdown.Missing) == 0
+	return breakdown
+}
+
+// choose elige el precio de un material. materials va del mas especifico al mas general y units en
+// orden de preferencia; prices viene ordenado de la fecha mas reciente a la mas vieja.
+func choose(prices []models.MaterialPrice, materials, units []string, region string) (models.MaterialPrice, bool) {
+	regions := []string{region}
+	if region != "" {
+		regions = append(regions, "")
+	}
+	for _, material := range materials {
+		for _, region := range regions {
+			for _, unit := range units {
+				for _, price := range prices {
+					if price.Material == material && price.Region == region && price.Unit == unit {
+						return price, true
+					}
+				}
+			}
+		}
+	}
+	return models.MaterialPrice{}, false
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
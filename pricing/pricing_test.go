@@ -0,0 +1,158 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories/memory"
+)
+
+var march = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func price(id int64, material, unit, region string, value float64) models.MaterialPrice {
+	return models.MaterialPrice{ID: id, Material: material, Unit: unit, Region: region, Price: value,
+		Currency: "ARS", EffectiveDate: march}
+}
+
+// ancestors es la taxonomia de los tests: roble y nogal son maderas duras
+func ancestors(material string) []string {
+	switch material {
+	case "oak", "walnut":
+		return []string{"hardwood"}
+	}
+	return nil
+}
+
+func TestEstimateChoosesPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		material string
+		region   string
+		prices   []models.MaterialPrice
+		wantID   int64
+		wantAs   string
+	}{
+		{
+			name: "exact material before its parent", material: "oak",
+			prices: []models.MaterialPrice{price(1, "hardwood", "unit", "", 50), price(2, "oak", "unit", "", 80)},
+			wantID: 2,
+		},
+		{
+			name: "parent when the material has no price", material: "walnut",
+			prices: []models.MaterialPrice{price(1, "hardwood", "unit", "", 50), price(2, "oak", "unit", "", 80)},
+			wantID: 1, wantAs: "hardwood",
+		},
+		{
+			name: "exact material before a regional price of its parent", material: "oak", region: "AR-B",
+			prices: []models.MaterialPrice{price(1, "hardwood", "unit", "AR-B", 50), price(2, "oak", "unit", "", 80)},
+			wantID: 2,
+		},
+		{
+			name: "requested region before the general price", material: "oak", region: "AR-B",
+			prices: []models.MaterialPrice{price(1, "oak", "unit", "", 80), price(2, "oak", "unit", "AR-B", 90)},
+			wantID: 2,
+		},
+		{
+			name: "general price when the region has none", material: "oak", region: "AR-C",
+			prices: []models.MaterialPrice{price(1, "oak", "unit", "AR-B", 90), price(2, "oak", "unit", "", 80)},
+			wantID: 2,
+		},
+		{
+			name: "no region only takes general prices", material: "oak",
+			prices: []models.MaterialPrice{price(1, "oak", "unit", "AR-B", 90)},
+		},
+		{
+			name: "first of equal candidates, that is the most recent", material: "oak",
+			prices: []models.MaterialPrice{price(3, "oak", "unit", "", 95), price(1, "oak", "unit", "", 80)},
+			wantID: 3,
+		},
+	}
+	for _, tt := range tests {
+		project := models.Project{Materials: []string{tt.material}}
+		breakdown := Estimate(project, nil, tt.prices, ancestors, Options{Currency: "ARS", Region: tt.region, Date: march})
+		line := breakdown.Lines[0]
+		if line.PriceID != tt.wantID || line.PricedAs != tt.wantAs {
+			t.Errorf("%s: price %d as %q, want %d as %q", tt.name, line.PriceID, line.PricedAs, tt.wantID, tt.wantAs)
+		}
+		if found := tt.wantID != 0; breakdown.Complete != found || (len(breakdown.Missing) == 0) != found {
+			t.Errorf("%s: complete = %v, missing = %q", tt.name, breakdown.Complete, breakdown.Missing)
+		}
+	}
+}
+
+func TestEstimateMeasuresParts(t *testing.T) {
+	project := models.Project{Materials: []string{"pine", "screws"}}
+	parts := []models.ProjectPart{
+		{Material: "pine", Quantity: 2, ThicknessMM: 18, WidthMM: 200, LengthMM: 900},
+		{Material: "mdf", Quantity: 1, ThicknessMM: 15, WidthMM: 400, LengthMM: 500},
+	}
+	prices := []models.MaterialPrice{
+		// con piezas se prefiere el metro cubico al pie tablar
+		price(1, "pine", "board_foot", "", 10), price(2, "pine", "cubic_metre", "", 1000),
+		price(3, "mdf", "square_metre", "", 20), price(4, "screws", "unit", "", 5),
+	}
+	breakdown := Estimate(project, parts, prices, ancestors, Options{Currency: "ARS", Date: march, Waste: 0.1})
+
+	want := []struct {
+		material, source, unit string
+		quantity, subtotal     float64
+	}{
+		{"pine", SourceParts, "cubic_metre", 0.007128, 7.13},
+		{"screws", SourceMaterials, "unit", 1, 5},
+		{"mdf", SourceParts, "square_metre", 0.22, 4.4},
+	}
+	if len(breakdown.Lines) != len(want) {
+		t.Fatalf("lines = %+v, want %d", breakdown.Lines, len(want))
+	}
+	for i, w := range want {
+		line := breakdown.Lines[i]
+		if line.Material != w.material || line.Source != w.source || line.Unit != w.unit ||
+			line.Quantity != w.quantity || line.Subtotal == nil || *line.Subtotal != w.subtotal {
+			t.Errorf("line %d = %+v, want %+v", i, line, w)
+		}
+	}
+	if breakdown.Total != 16.53 || !breakdown.Complete || breakdown.Waste != 10 {
+		t.Errorf("breakdown = %+v, want a complete total of 16.53 with 10%% waste", breakdown)
+	}
+}
+
+// TestEstimateEffectiveDate controla que con los candidatos de ListEffective gane el precio vigente
+// a la fecha del presupuesto: el mas reciente que no es posterior
+func TestEstimateEffectiveDate(t *testing.T) {
+	ctx := context.Background()
+	repos, _ := memory.New()
+	for i, date := range []time.Time{march, march.AddDate(0, 3, 0), march.AddDate(0, 9, 0)} {
+		p := price(0, "oak", "unit", "", float64(100+10*i))
+		p.EffectiveDate = date
+		if err := repos.Prices.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		date time.Time
+		want *float64
+	}{
+		{march.AddDate(0, 0, -1), nil},
+		{march, ptr(100.0)},
+		{march.AddDate(0, 5, 0), ptr(110.0)},
+		{march.AddDate(1, 0, 0), ptr(120.0)},
+	}
+	for _, tt := range tests {
+		prices, err := repos.Prices.ListEffective(ctx, []string{"oak"}, "ARS", []string{""}, tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line := Estimate(models.Project{Materials: []string{"oak"}}, nil, prices, ancestors,
+			Options{Currency: "ARS", Date: tt.date}).Lines[0]
+		if (line.UnitPrice == nil) != (tt.want == nil) || line.UnitPrice != nil && *line.UnitPrice != *tt.want {
+			t.Errorf("%s: unit price = %v, want %v", tt.date.Format(time.DateOnly), line.UnitPrice, tt.want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
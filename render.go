@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/carpentry-hub/woodys-backend/config"
+	"github.com/carpentry-hub/woodys-backend/db"
+	"github.com/carpentry-hub/woodys-backend/markdown"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+const markdownUsage = `usage: woodys-backend markdown <command>
+
+commands:
+  render [--dry-run]  render the Markdown of every description, tutorial step and comment again and
+                      store the HTML that changed (run it after migrating or changing MARKDOWN_IMAGE_HOSTS)`
+
+// runMarkdown ejecuta los subcomandos de "markdown" y termina el proceso
+func runMarkdown(cfg *config.Config, args []string) {
+	if len(args) == 0 || args[0] != "render" || len(args) > 2 || len(args) == 2 && args[1] != "--dry-run" {
+		log.Fatal(markdownUsage)
+	}
+	dryRun := len(args) == 2
+
+	database, err := db.Connection(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	repos := repositories.NewGorm(database)
+
+	report, err := markdown.Backfill(context.Background(), repos, markdown.New(cfg.Markdown.ImageHosts), dryRun)
+	report.Write(os.Stdout)
+	if err != nil {
+		log.Fatalf("Render failed: %v", err)
+	}
+	if dryRun {
+		fmt.Println("dry run: nothing was written")
+	}
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/lib/pq"
+)
+
+// BuildableFilter es el inventario contra el que se comparan los proyectos, ya en slugs de la
+// taxonomia como los guardan los proyectos
+type BuildableFilter struct {
+	Tools     []string
+	Materials []string
+	// MaxMissingTools descarta los proyectos a los que les faltan mas herramientas
+	MaxMissingTools int
+}
+
+// BuildableProject es un proyecto publico con lo que le falta al inventario para construirlo
+type BuildableProject struct {
+	models.Project   `gorm:"embedded"`
+	MissingTools     pq.StringArray `gorm:"type:varchar[]"`
+	MissingMaterials pq.StringArray `gorm:"type:varchar[]"`
+	// Coverage es la fraccion de herramientas y materiales distintos del proyecto que ya se tienen
+	Coverage float64
+	// Rank es la clave de orden "missing": menos herramientas faltantes, despues menos materiales y
+	// despues mayor cobertura
+	Rank float64
+}
+
+// Cover calcula en Go lo mismo que ListBuildable en SQL, para la implementacion en memoria
+func Cover(project models.Project, filter BuildableFilter) BuildableProject {
+	missingTools, tools := missing(project.Tools, filter.Tools)
+	missingMaterials, materials := missing(project.Materials, filter.Materials)
+	coverage := 1.0
+	if needed := tools + materials; needed > 0 {
+		coverage = float64(needed-len(missingTools)-len(missingMaterials)) / float64(needed)
+	}
+	return BuildableProject{
+		Project:          project,
+		MissingTools:     missingTools,
+		MissingMaterials: missingMaterials,
+		Coverage:         coverage,
+		Rank:             buildableRank(len(missingTools), len(missingMaterials), coverage),
+	}
+}
+
+// buildableRank junta los tres criterios del orden en un solo valor para el cursor. Un proyecto no
+// llega a mil materiales, asi que cada criterio queda en su rango sin pisar al anterior.
+func buildableRank(missingTools, missingMaterials int, coverage float64) float64 {
+	return float64(missingTools*1000000+missingMaterials*1000) + (1 - coverage)
+}
+
+// missing devuelve los valores distintos de needed que no estan en have, en el orden en que
+// aparecen, y cuantos valores distintos hay
+func missing(needed, have []string) ([]string, int) {
+	owned := make(map[string]bool, len(have))
+	for _, value := range have {
+		owned[value] = true
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	for _, value := range needed {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		if !owned[value] {
+			out = append(out, value)
+		}
+	}
+	return out, len(seen)
+}
+
+// buildableCoverage calcula por proyecto las herramientas y materiales faltantes, la cobertura y la
+// clave de orden, con las mismas cuentas y en el mismo orden que Cover
+const buildableCoverage = `CROSS JOIN LATERAL (
+	SELECT counts.missing_tools, counts.missing_materials, counts.coverage,
+		(cardinality(counts.missing_tools) * 1000000 + cardinality(counts.missing_materials) * 1000)::float8
+			+ (1 - counts.coverage) AS missing_rank
+	FROM (
+		SELECT missing.tools AS missing_tools, missing.materials AS missing_materials,
+			CASE WHEN missing.needed = 0 THEN 1
+				ELSE (missing.needed - cardinality(missing.tools) - cardinality(missing.materials))::float8 / missing.needed
+			END AS coverage
+		FROM (
+			SELECT
+				ARRAY(SELECT t FROM unnest(projects.tools) WITH ORDINALITY AS u(t, n)
+					WHERE t <> ALL(?::varchar[]) GROUP BY t ORDER BY min(n))::varchar[] AS tools,
+				ARRAY(SELECT m FROM unnest(projects.materials) WITH ORDINALITY AS u(m, n)
+					WHERE m <> ALL(?::varchar[]) GROUP BY m ORDER BY min(n))::varchar[] AS materials,
+				(SELECT count(DISTINCT t) FROM unnest(projects.tools) AS u(t)) +
+					(SELECT count(DISTINCT m) FROM unnest(projects.materials) AS u(m)) AS needed
+		) AS missing
+	) AS counts
+) AS coverage`
+
+// ListBuildable devuelve los proyectos publicos a los que les faltan a lo sumo MaxMissingTools
+// herramientas del inventario. La comparacion se hace en la base: solo viajan las filas de la pagina.
+func (r *projectRepository) ListBuildable(
+	ctx context.Context, filter BuildableFilter, page pagination.Request,
+) ([]BuildableProject, *int64, error) {
+	// un slice nil viaja como NULL y "<> ALL(NULL)" no deja pasar nada: el inventario vacio va como {}
+	tools := pq.StringArray(append([]string{}, filter.Tools...))
+	materials := pq.StringArray(append([]string{}, filter.Materials...))
+	query := r.db.WithContext(ctx).Model(&models.Project{}).
+		Joins(buildableCoverage, tools, materials).
+		Where("projects.is_public = TRUE").
+		Where("cardinality(coverage.missing_tools) <= ?", filter.MaxMissingTools)
+
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := sortColumns{"missing": column("coverage.missing_rank")}
+	for key, expr := range projectSortColumns {
+		columns[key] = expr
+	}
+	query, err = paginate(query.Select("projects.*, coverage.missing_tools, coverage.missing_materials, "+
+		"coverage.coverage, coverage.missing_rank AS rank"), page, columns, "projects.id")
+	if err != nil {
+		return nil, nil, err
+	}
+	var projects []BuildableProject
+	if err := query.Find(&projects).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	return projects, total, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+// TestListBuildableMatchesCover controla que la cuenta en SQL coincida con Cover, que es la que usa
+// la implementacion en memoria, incluido el valor de la clave de orden que viaja en el cursor
+func TestListBuildableMatchesCover(t *testing.T) {
+	repos := NewGorm(testDB(t))
+	ctx := context.Background()
+	owner := testUser(t, repos, "owner")
+	slug := func(name string) string { return name + "-" + owner.FirebaseUID }
+
+	requirements := []struct {
+		title            string
+		tools, materials []string
+	}{
+		{"Completo", []string{slug("jigsaw"), slug("jigsaw")}, []string{slug("pine")}},
+		{"Parcial", []string{slug("sander"), slug("jigsaw")}, []string{slug("glue"), slug("pine")}},
+		{"Faltan tres", []string{slug("clamp"), slug("sander"), slug("router")}, nil},
+	}
+	filter := BuildableFilter{Tools: []string{slug("jigsaw")}, Materials: []string{slug("pine")}, MaxMissingTools: 2}
+	want := map[int64]BuildableProject{}
+	for _, req := range requirements {
+		project := testProject(t, repos, owner, req.title)
+		project.Tools, project.Materials = req.tools, append([]string{}, req.materials...)
+		if err := repos.Projects.Update(ctx, project, Revision{}); err != nil {
+			t.Fatal(err)
+		}
+		if covered := Cover(*project, filter); len(covered.MissingTools) <= filter.MaxMissingTools {
+			want[project.ID] = covered
+		}
+	}
+
+	spec := pagination.Spec{
+		Scope: "buildable", Keys: []pagination.SortKey{{Name: "missing", Kind: pagination.Float}},
+		DefaultSort: "missing", DefaultLimit: 100, MaxLimit: 100,
+	}
+	codec := pagination.NewRandomCodec()
+	page, err := codec.Parse(url.Values{}, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[int64]BuildableProject{}
+	previous := -1.0
+	for {
+		rows, _, err := repos.Projects.ListBuildable(ctx, filter, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows[:min(len(rows), page.Limit)] {
+			if row.Rank < previous {
+				t.Errorf("project %d out of order: rank %v after %v", row.ID, row.Rank, previous)
+			}
+			previous = row.Rank
+			if row.Owner == owner.ID {
+				got[row.ID] = row
+			}
+		}
+		next := pagination.NewPage(codec, page, rows, nil, func(row BuildableProject) pagination.Keyset {
+			return pagination.Keyset{Value: row.Rank, ID: row.ID}
+		}).NextCursor
+		if next == "" {
+			break
+		}
+		if page, err = codec.Parse(url.Values{"cursor": {next}}, spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d of the owner's projects, want %d", len(got), len(want))
+	}
+	for id, w := range want {
+		g := got[id]
+		if !slices.Equal(g.MissingTools, w.MissingTools) || !slices.Equal(g.MissingMaterials, w.MissingMaterials) ||
+			g.Coverage != w.Coverage || g.Rank != w.Rank {
+			t.Errorf("%s: got %v %v %v %v, want %v %v %v %v", w.Title,
+				g.MissingTools, g.MissingMaterials, g.Coverage, g.Rank,
+				w.MissingTools, w.MissingMaterials, w.Coverage, w.Rank)
+		}
+	}
+
+	private := testProject(t, repos, owner, "Privado")
+	private.IsPublic = false
+	if err := repos.Projects.Update(ctx, private, Revision{}); err != nil {
+		t.Fatal(err)
+	}
+	page, _ = codec.Parse(url.Values{}, spec)
+	rows, _, err := repos.Projects.ListBuildable(ctx, BuildableFilter{MaxMissingTools: 0}, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.ContainsFunc(rows, func(row BuildableProject) bool { return row.ID == private.ID }) {
+		t.Errorf("private project %d listed", private.ID)
+	}
+}
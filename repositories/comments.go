@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"gorm.io/gorm"
+)
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id int64) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).First(&comment, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &comment, nil
+}
+
+// commentSortColumns son las claves de orden de los listados de comentarios
+var commentSortColumns = sortColumns{
+	"created_at": column("created_at"),
+	"rating":     column("rating"),
+}
+
+func (r *commentRepository) ListByProject(
+	ctx context.Context, projectID int64, page pagination.Request,
+) ([]models.Comment, *int64, error) {
+	return r.list(r.db.WithContext(ctx).Model(&models.Comment{}).Where("project_id = ?", projectID), page)
+}
+
+func (r *commentRepository) ListReplies(
+	ctx context.Context, parentID int64, page pagination.Request,
+) ([]models.Comment, *int64, error) {
+	return r.list(r.db.WithContext(ctx).Model(&models.Comment{}).Where("parent_comment_id = ?", parentID), page)
+}
+
+func (r *commentRepository) list(query *gorm.DB, page pagination.Request) ([]models.Comment, *int64, error) {
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err = paginate(query, page, commentSortColumns, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	var comments []models.Comment
+	if err := query.Find(&comments).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) ListAfterID(ctx context.Context, afterID int64, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Find(&comments).Error
+	return comments, translate(err)
+}
+
+func (r *commentRepository) UpdateRendered(ctx context.Context, comment *models.Comment) error {
+	result := r.db.WithContext(ctx).Model(&models.Comment{ID: comment.ID}).
+		UpdateColumn("content_html", comment.ContentHTML)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return translate(r.db.WithContext(ctx).Create(comment).Error)
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id int64) error {
+	return deleteByID(r.db.WithContext(ctx), &models.Comment{}, id)
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"sort"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// maxFacetValues limita cuantos valores distintos se devuelven por faceta (los mas frecuentes)
+const maxFacetValues = 50
+
+// FacetValue es un valor de una faceta con la cantidad de proyectos que lo tienen
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// Bucket es un rango [Min, Max) de una faceta numerica; Min o Max nil dejan el rango abierto
+type Bucket struct {
+	Key   string   `json:"key"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+// contains indica si el valor cae en el rango del bucket
+func (b Bucket) contains(value float64) bool {
+	return (b.Min == nil || value >= *b.Min) && (b.Max == nil || value < *b.Max)
+}
+
+// Facets son los conteos de la busqueda actual por cada filtro del explorador, siempre sobre proyectos
+// publicos. Las facetas de valores se cuentan sin su propio filtro, asi al elegir "Roble" se siguen
+// viendo los conteos de "Pino".
+type Facets struct {
+	Style         []FacetValue `json:"style"`
+	Materials     []FacetValue `json:"materials"`
+	Tools         []FacetValue `json:"tools"`
+	MainMaterial  []FacetValue `json:"main_material"`
+	Environment   []FacetValue `json:"environment"`
+	TimeToBuild   []Bucket     `json:"time_to_build"`
+	Height        []Bucket     `json:"height"`
+	Width         []Bucket     `json:"width"`
+	Length        []Bucket     `json:"length"`
+	AverageRating []Bucket     `json:"average_rating"`
+}
+
+// rangeFacet describe una faceta numerica: la columna, como leerla de un proyecto y sus buckets
+type rangeFacet struct {
+	column  string
+	value   func(models.Project) float64
+	buckets []Bucket
+	target  func(*Facets) *[]Bucket
+}
+
+func bound(v float64) *float64 { return &v }
+
+// buckets arma rangos consecutivos a partir de los limites: [0, l1), [l1, l2), ..., [ln, inf)
+func buckets(keys []string, limits ...float64) []Bucket {
+	out := make([]Bucket, 0, len(limits)+1)
+	var previous *float64
+	for i, limit := range limits {
+		out = append(out, Bucket{Key: keys[i], Min: previous, Max: bound(limit)})
+		previous = bound(limit)
+	}
+	return append(out, Bucket{Key: keys[len(limits)], Min: previous})
+}
+
+// Las dimensiones estan en centimetros y time_to_build en minutos
+var (
+	dimensionBuckets = buckets([]string{"0-30", "30-60", "60-100", "100-200", "200+"}, 30, 60, 100, 200)
+
+	rangeFacets = []rangeFacet{
+		{
+			column:  "time_to_build",
+			value:   func(p models.Project) float64 { return float64(p.TimeToBuild) },
+			buckets: buckets([]string{"0-60", "60-180", "180-480", "480-1440", "1440+"}, 60, 180, 480, 1440),
+			target:  func(f *Facets) *[]Bucket { return &f.TimeToBuild },
+		},
+		{
+			column:  "height",
+			value:   func(p models.Project) float64 { return float64(p.Height) },
+			buckets: dimensionBuckets,
+			target:  func(f *Facets) *[]Bucket { return &f.Height },
+		},
+		{
+			column:  "width",
+			value:   func(p models.Project) float64 { return float64(p.Width) },
+			buckets: dimensionBuckets,
+			target:  func(f *Facets) *[]Bucket { return &f.Width },
+		},
+		{
+			column:  "length",
+			value:   func(p models.Project) float64 { return float64(p.Length) },
+			buckets: dimensionBuckets,
+			target:  func(f *Facets) *[]Bucket { return &f.Length },
+		},
+		{
+			column:  "average_rating",
+			value:   func(p models.Project) float64 { return float64(p.AverageRating) },
+			buckets: buckets([]string{"0-1", "1-2", "2-3", "3-4", "4+"}, 1, 2, 3, 4),
+			target:  func(f *Facets) *[]Bucket { return &f.AverageRating },
+		},
+	}
+)
+
+// valueFacet describe una faceta de valores: la columna, sus valores en un proyecto y el filtro
+// que hay que sacar para contarla
+type valueFacet struct {
+	column  string
+	array   bool
+	values  func(models.Project) []string
+	without func(ProjectFilter) ProjectFilter
+	target  func(*Facets) *[]FacetValue
+}
+
+var valueFacets = []valueFacet{
+	{
+		column: "style", array: true,
+		values:  func(p models.Project) []string { return p.Style },
+		without: func(f ProjectFilter) ProjectFilter { f.Style = nil; return f },
+		target:  func(f *Facets) *[]FacetValue { return &f.Style },
+	},
+	{
+		column: "materials", array: true,
+		values:  func(p models.Project) []string { return p.Materials },
+		without: func(f ProjectFilter) ProjectFilter { f.Materials = nil; return f },
+		target:  func(f *Facets) *[]FacetValue { return &f.Materials },
+	},
+	{
+		column: "tools", array: true,
+		values:  func(p models.Project) []string { return p.Tools },
+		without: func(f ProjectFilter) ProjectFilter { f.Tools = nil; return f },
+		target:  func(f *Facets) *[]FacetValue { return &f.Tools },
+	},
+	{
+		column:  "main_material",
+		values:  func(p models.Project) []string { return []string{p.MainMaterial} },
+		without: func(f ProjectFilter) ProjectFilter { f.MainMaterial = nil; return f },
+		target:  func(f *Facets) *[]FacetValue { return &f.MainMaterial },
+	},
+	{
+		column:  "environment",
+		values:  func(p models.Project) []string { return []string{p.Environment} },
+		without: func(f ProjectFilter) ProjectFilter { f.Environment = nil; return f },
+		target:  func(f *Facets) *[]FacetValue { return &f.Environment },
+	},
+}
+
+// CountFacets calcula las facetas en memoria sobre todos los proyectos. matches indica si un proyecto
+// cumple un filtro; se llama con el filtro completo y con el filtro sin cada faceta de valores.
+func CountFacets(projects []models.Project, filter ProjectFilter, matches func(models.Project, ProjectFilter) bool) *Facets {
+	facets := &Facets{}
+
+	for _, facet := range valueFacets {
+		without := facet.without(filter)
+		counts := map[string]int64{}
+		for _, p := range projects {
+			if !p.IsPublic || !matches(p, without) {
+				continue
+			}
+			seen := map[string]bool{}
+			for _, value := range facet.values(p) {
+				if value != "" && !seen[value] {
+					seen[value] = true
+					counts[value]++
+				}
+			}
+		}
+		*facet.target(facets) = topValues(counts)
+	}
+
+	for _, facet := range rangeFacets {
+		out := append([]Bucket{}, facet.buckets...)
+		for _, p := range projects {
+			if !p.IsPublic || !matches(p, filter) {
+				continue
+			}
+			for i := range out {
+				if out[i].contains(facet.value(p)) {
+					out[i].Count++
+				}
+			}
+		}
+		*facet.target(facets) = out
+	}
+	return facets
+}
+
+// topValues ordena los valores por cantidad descendente (y alfabeticamente ante empates) y se queda
+// con los maxFacetValues primeros
+func topValues(counts map[string]int64) []FacetValue {
+	values := make([]FacetValue, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, FacetValue{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if len(values) > maxFacetValues {
+		values = values[:maxFacetValues]
+	}
+	return values
+}
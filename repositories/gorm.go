@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Codigos de error de Postgres que se traducen a errores del paquete
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// NewGorm crea todos los repositorios respaldados por la base de datos
+func NewGorm(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:           &userRepository{db: db},
+		Projects:        &projectRepository{db: db},
+		Comments:        &commentRepository{db: db},
+		Ratings:         &ratingRepository{db: db},
+		ProjectLists:    &projectListRepository{db: db},
+		ProfilePictures: &profilePictureRepository{db: db},
+		Inventory:       &inventoryRepository{db: db},
+		Taxonomy:        &taxonomyRepository{db: db},
+		Parts:           &projectPartRepository{db: db},
+		Steps:           &projectStepRepository{db: db},
+		Prices:          &priceRepository{db: db},
+		Uploads:         &uploadRepository{db: db},
+		Revisions:       &projectRevisionRepository{db: db},
+	}
+}
+
+// translate convierte los errores de gorm y Postgres en los errores del paquete
+func translate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return errors.Join(ErrConflict, err)
+		case pgForeignKeyViolation:
+			return errors.Join(ErrMissingReference, err)
+		}
+	}
+	return err
+}
+
+// deleteByID borra un registro por id y devuelve ErrNotFound si no existia
+func deleteByID(db *gorm.DB, model any, id int64) error {
+	result := db.Unscoped().Delete(model, id)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// sortColumns traduce las claves de orden de un listado a la expresion SQL que las calcula
+type sortColumns map[string]clause.Expr
+
+// column es una clave de orden que es directamente una columna
+func column(name string) clause.Expr {
+	return clause.Expr{SQL: name}
+}
+
+// countTotal cuenta las filas del query si la pagina lo pide. Debe llamarse antes de agregar
+// select, orden o limite.
+func countTotal(query *gorm.DB, page pagination.Request) (*int64, error) {
+	if !page.WithTotal {
+		return nil, nil
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &total, nil
+}
+
+// paginate agrega al query el orden, la condicion del cursor y el limite de la pagina.
+// idColumn es la columna de desempate, calificada si el query tiene joins.
+func paginate(query *gorm.DB, page pagination.Request, columns sortColumns, idColumn string) (*gorm.DB, error) {
+	expr, ok := columns[page.Order.Key]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort key %q", page.Order.Key)
+	}
+
+	direction, comparison := "ASC", ">"
+	if page.Order.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if page.After != nil {
+		vars := append(append([]any{}, expr.Vars...), page.After.Value, page.After.ID)
+		query = query.Where(clause.Expr{
+			SQL:  "(" + expr.SQL + ", " + idColumn + ") " + comparison + " (?, ?)",
+			Vars: vars,
+		})
+	}
+	return query.
+		Order(clause.Expr{SQL: expr.SQL + " " + direction + ", " + idColumn + " " + direction, Vars: expr.Vars}).
+		Limit(page.Fetch()), nil
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"gorm.io/gorm"
+)
+
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+func (r *inventoryRepository) FindByID(ctx context.Context, id int64) (*models.InventoryItem, error) {
+	var item models.InventoryItem
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &item, nil
+}
+
+func (r *inventoryRepository) ListByUser(ctx context.Context, userID int64) ([]models.InventoryItem, error) {
+	var items []models.InventoryItem
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("kind, normalized_name").Find(&items).Error
+	return items, translate(err)
+}
+
+func (r *inventoryRepository) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.InventoryItem{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, translate(err)
+}
+
+func (r *inventoryRepository) Create(ctx context.Context, item *models.InventoryItem) error {
+	return translate(r.db.WithContext(ctx).Create(item).Error)
+}
+
+func (r *inventoryRepository) Update(ctx context.Context, item *models.InventoryItem) error {
+	return translate(r.db.WithContext(ctx).Save(item).Error)
+}
+
+func (r *inventoryRepository) Delete(ctx context.Context, id int64) error {
+	return deleteByID(r.db.WithContext(ctx), &models.InventoryItem{}, id)
+}
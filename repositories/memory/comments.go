@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type commentRepository struct{ s *Store }
+
+func (r commentRepository) FindByID(_ context.Context, id int64) (*models.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comment, ok := r.s.comments[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &comment, nil
+}
+
+// commentSortKeys son las claves de orden de los listados de comentarios
+var commentSortKeys = map[string]func(models.Comment) any{
+	"created_at": func(c models.Comment) any { return c.CreatedAt },
+	"rating":     func(c models.Comment) any { return c.Rating },
+}
+
+func (r commentRepository) ListByProject(
+	_ context.Context, projectID int64, page pagination.Request,
+) ([]models.Comment, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comments := sortedByID(r.s.comments, func(c models.Comment) bool { return c.ProjectID == projectID })
+	return paginate(comments, page, commentSortKeys, func(c models.Comment) int64 { return c.ID })
+}
+
+func (r commentRepository) ListReplies(
+	_ context.Context, parentID int64, page pagination.Request,
+) ([]models.Comment, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comments := sortedByID(r.s.comments, func(c models.Comment) bool { return c.ParentCommentID == parentID })
+	return paginate(comments, page, commentSortKeys, func(c models.Comment) int64 { return c.ID })
+}
+
+func (r commentRepository) ListAfterID(_ context.Context, afterID int64, limit int) ([]models.Comment, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comments := sortedByID(r.s.comments, func(c models.Comment) bool { return c.ID > afterID })
+	if len(comments) > limit {
+		comments = comments[:limit]
+	}
+	return comments, nil
+}
+
+func (r commentRepository) UpdateRendered(_ context.Context, comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.comments[comment.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	current.ContentHTML = comment.ContentHTML
+	r.s.comments[comment.ID] = current
+	return nil
+}
+
+func (r commentRepository) Create(_ context.Context, comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projects[comment.ProjectID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	if _, ok := r.s.users[comment.UserID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	comment.ID = r.s.newID()
+	comment.CreatedAt = time.Now()
+	r.s.comments[comment.ID] = *comment
+	return nil
+}
+
+func (r commentRepository) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.comments[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.comments, id)
+	return nil
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type inventoryRepository struct{ s *Store }
+
+func (r inventoryRepository) FindByID(_ context.Context, id int64) (*models.InventoryItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	item, ok := r.s.inventory[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &item, nil
+}
+
+func (r inventoryRepository) ListByUser(_ context.Context, userID int64) ([]models.InventoryItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	items := sortedByID(r.s.inventory, func(i models.InventoryItem) bool { return i.UserID == userID })
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Kind != items[j].Kind {
+			return items[i].Kind < items[j].Kind
+		}
+		return items[i].NormalizedName < items[j].NormalizedName
+	})
+	return items, nil
+}
+
+func (r inventoryRepository) CountByUser(_ context.Context, userID int64) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return int64(len(sortedByID(r.s.inventory, func(i models.InventoryItem) bool { return i.UserID == userID }))), nil
+}
+
+func (r inventoryRepository) Create(_ context.Context, item *models.InventoryItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[item.UserID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	if r.duplicate(*item) {
+		return repositories.ErrConflict
+	}
+	item.ID = r.s.newID()
+	item.CreatedAt = time.Now()
+	r.s.inventory[item.ID] = *item
+	return nil
+}
+
+func (r inventoryRepository) Update(_ context.Context, item *models.InventoryItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.inventory[item.ID]; !ok {
+		return repositories.ErrNotFound
+	}
+	if r.duplicate(*item) {
+		return repositories.ErrConflict
+	}
+	r.s.inventory[item.ID] = *item
+	return nil
+}
+
+func (r inventoryRepository) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.inventory[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.inventory, id)
+	return nil
+}
+
+// duplicate replica el indice unique (user_id, kind, normalized_name)
+func (r inventoryRepository) duplicate(item models.InventoryItem) bool {
+	for _, existing := range r.s.inventory {
+		if existing.ID != item.ID && existing.UserID == item.UserID && existing.Kind == item.Kind &&
+			existing.NormalizedName == item.NormalizedName {
+			return true
+		}
+	}
+	return false
+}
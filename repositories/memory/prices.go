@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type priceRepository struct{ s *Store }
+
+func (r priceRepository) FindByID(_ context.Context, id int64) (*models.MaterialPrice, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	price, ok := r.s.prices[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &price, nil
+}
+
+// priceSortKeys son las claves de orden del catalogo de precios
+var priceSortKeys = map[string]func(models.MaterialPrice) any{
+	"effective_date": func(p models.MaterialPrice) any { return p.EffectiveDate },
+	"material":       func(p models.MaterialPrice) any { return p.Material },
+}
+
+func (r priceRepository) List(
+	_ context.Context, filter repositories.PriceFilter, page pagination.Request,
+) ([]models.MaterialPrice, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	prices := sortedByID(r.s.prices, func(p models.MaterialPrice) bool {
+		return (filter.Material == "" || p.Material == filter.Material) &&
+			(filter.Unit == "" || p.Unit == filter.Unit) &&
+			(filter.Currency == "" || p.Currency == filter.Currency) &&
+			(filter.Region == nil || p.Region == *filter.Region)
+	})
+	return paginate(prices, page, priceSortKeys, func(p models.MaterialPrice) int64 { return p.ID })
+}
+
+func (r priceRepository) ListEffective(
+	_ context.Context, materials []string, currency string, regions []string, date time.Time,
+) ([]models.MaterialPrice, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	prices := sortedByID(r.s.prices, func(p models.MaterialPrice) bool {
+		return contains(materials, p.Material) && p.Currency == currency &&
+			contains(regions, p.Region) && !p.EffectiveDate.After(date)
+	})
+	sort.SliceStable(prices, func(i, j int) bool {
+		if !prices[i].EffectiveDate.Equal(prices[j].EffectiveDate) {
+			return prices[i].EffectiveDate.After(prices[j].EffectiveDate)
+		}
+		return prices[i].ID > prices[j].ID
+	})
+	return prices, nil
+}
+
+func (r priceRepository) Create(_ context.Context, price *models.MaterialPrice) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.findKey(*price); ok {
+		return repositories.ErrConflict
+	}
+	price.ID = r.s.newID()
+	price.CreatedAt = time.Now()
+	price.UpdatedAt = price.CreatedAt
+	r.s.prices[price.ID] = *price
+	return nil
+}
+
+func (r priceRepository) Update(_ context.Context, price *models.MaterialPrice) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.prices[price.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if id, ok := r.findKey(*price); ok && id != price.ID {
+		return repositories.ErrConflict
+	}
+	price.CreatedAt = current.CreatedAt
+	price.UpdatedAt = time.Now()
+	r.s.prices[price.ID] = *price
+	return nil
+}
+
+func (r priceRepository) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.prices[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.prices, id)
+	return nil
+}
+
+func (r priceRepository) Import(_ context.Context, prices []models.MaterialPrice) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	now := time.Now()
+	for _, price := range prices {
+		if id, ok := r.findKey(price); ok {
+			current := r.s.prices[id]
+			current.Price = price.Price
+			current.Supplier = price.Supplier
+			current.UpdatedAt = now
+			r.s.prices[id] = current
+			continue
+		}
+		price.ID = r.s.newID()
+		price.CreatedAt = now
+		price.UpdatedAt = now
+		r.s.prices[price.ID] = price
+	}
+	return nil
+}
+
+// findKey replica el indice unico (material, unit, currency, region, effective_date)
+func (r priceRepository) findKey(price models.MaterialPrice) (int64, bool) {
+	for id, existing := range r.s.prices {
+		if existing.Material == price.Material && existing.Unit == price.Unit &&
+			existing.Currency == price.Currency && existing.Region == price.Region &&
+			existing.EffectiveDate.Equal(price.EffectiveDate) {
+			return id, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type profilePictureRepository struct{ s *Store }
+
+func (r profilePictureRepository) FindByID(_ context.Context, id int64) (*models.ProfilePicture, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	picture, ok := r.s.profilePictures[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &picture, nil
+}
+
+func (r profilePictureRepository) List(_ context.Context) ([]models.ProfilePicture, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	pictures := sortedByID(r.s.profilePictures, func(p models.ProfilePicture) bool { return p.RetiredAt == nil })
+	slices.SortStableFunc(pictures, func(a, b models.ProfilePicture) int { return a.Position - b.Position })
+	return pictures, nil
+}
+
+func (r profilePictureRepository) Create(_ context.Context, picture *models.ProfilePicture) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	picture.ID = r.s.newID()
+	picture.CreatedAt = time.Now()
+	picture.Position = r.s.lastPicturePosition() + 1
+	picture.RetiredAt = nil
+	r.s.profilePictures[picture.ID] = *picture
+	return nil
+}
+
+func (r profilePictureRepository) Retire(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	picture, ok := r.s.profilePictures[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	if picture.RetiredAt == nil {
+		now := time.Now()
+		picture.RetiredAt = &now
+		r.s.profilePictures[id] = picture
+	}
+	return nil
+}
+
+func (r profilePictureRepository) Reorder(_ context.Context, ids []int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i, id := range ids {
+		if picture, ok := r.s.profilePictures[id]; ok {
+			picture.Position = i + 1
+			r.s.profilePictures[id] = picture
+		}
+	}
+	return nil
+}
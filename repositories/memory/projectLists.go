@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type projectListRepository struct{ s *Store }
+
+func (r projectListRepository) FindByID(_ context.Context, id int64) (*models.ProjectList, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	list, ok := r.s.projectLists[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &list, nil
+}
+
+// projectListSortKeys son las claves de orden del listado de listas de un usuario
+var projectListSortKeys = map[string]func(models.ProjectList) any{
+	"created_at": func(l models.ProjectList) any { return l.CreatedAt },
+	"name":       func(l models.ProjectList) any { return l.Name },
+}
+
+func (r projectListRepository) ListByUser(
+	_ context.Context, userID int64, page pagination.Request,
+) ([]models.ProjectList, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	lists := sortedByID(r.s.projectLists, func(l models.ProjectList) bool { return l.UserID == userID })
+	for i := range lists {
+		for _, item := range r.s.projectListItem {
+			if item.ProjectListID == lists[i].ID {
+				lists[i].ProjectCount++
+			}
+		}
+	}
+	return paginate(lists, page, projectListSortKeys, func(l models.ProjectList) int64 { return l.ID })
+}
+
+func (r projectListRepository) Create(_ context.Context, list *models.ProjectList) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[list.UserID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	list.ID = r.s.newID()
+	list.CreatedAt = time.Now()
+	r.s.projectLists[list.ID] = *list
+	return nil
+}
+
+func (r projectListRepository) Update(_ context.Context, list *models.ProjectList) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projectLists[list.ID]; !ok {
+		return repositories.ErrNotFound
+	}
+	r.s.projectLists[list.ID] = *list
+	return nil
+}
+
+func (r projectListRepository) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projectLists[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.projectLists, id)
+	for itemID, item := range r.s.projectListItem {
+		if item.ProjectListID == id {
+			delete(r.s.projectListItem, itemID)
+		}
+	}
+	return nil
+}
+
+func (r projectListRepository) AddItem(_ context.Context, item *models.ProjectListItem) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projectLists[item.ProjectListID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	if _, ok := r.s.projects[item.ProjectID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	for _, existing := range r.s.projectListItem {
+		if existing.ProjectListID == item.ProjectListID && existing.ProjectID == item.ProjectID {
+			return repositories.ErrConflict
+		}
+	}
+	item.ID = r.s.newID()
+	item.CreatedAt = time.Now()
+	r.s.projectListItem[item.ID] = *item
+	return nil
+}
+
+func (r projectListRepository) FindItem(_ context.Context, listID, projectID int64) (*models.ProjectListItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, item := range r.s.projectListItem {
+		if item.ProjectListID == listID && item.ProjectID == projectID {
+			return &item, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r projectListRepository) DeleteItem(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projectListItem[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.projectListItem, id)
+	return nil
+}
+
+func (r projectListRepository) ListItems(_ context.Context, listID int64) ([]models.ProjectListItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return sortedByID(r.s.projectListItem, func(i models.ProjectListItem) bool { return i.ProjectListID == listID }), nil
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type projectPartRepository struct{ s *Store }
+
+func (r projectPartRepository) ListByProject(_ context.Context, projectID int64) ([]models.ProjectPart, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.list(projectID), nil
+}
+
+func (r projectPartRepository) ListByProjects(_ context.Context, projectIDs []int64) ([]models.ProjectPart, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	wanted := make(map[int64]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		wanted[id] = true
+	}
+	parts := sortedByID(r.s.parts, func(p models.ProjectPart) bool { return wanted[p.ProjectID] })
+	sort.SliceStable(parts, func(i, j int) bool {
+		if parts[i].ProjectID != parts[j].ProjectID {
+			return parts[i].ProjectID < parts[j].ProjectID
+		}
+		return parts[i].Position < parts[j].Position
+	})
+	return parts, nil
+}
+
+func (r projectPartRepository) Replace(
+	_ context.Context, projectID int64, parts []models.ProjectPart, revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projects[projectID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	for id, part := range r.s.parts {
+		if part.ProjectID == projectID {
+			delete(r.s.parts, id)
+		}
+	}
+	now := time.Now()
+	for i := range parts {
+		parts[i].ID = r.s.newID()
+		parts[i].CreatedAt = now
+		parts[i].ProjectID = projectID
+		parts[i].Position = i
+		r.s.parts[parts[i].ID] = parts[i]
+	}
+	r.s.saveRevision(projectID, revision)
+	return nil
+}
+
+func (r projectPartRepository) list(projectID int64) []models.ProjectPart {
+	parts := sortedByID(r.s.parts, func(p models.ProjectPart) bool { return p.ProjectID == projectID })
+	sort.SliceStable(parts, func(i, j int) bool { return parts[i].Position < parts[j].Position })
+	return parts
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type projectRevisionRepository struct{ s *Store }
+
+// revisionSortKeys son las claves de orden del historial de un proyecto
+var revisionSortKeys = map[string]func(models.ProjectRevision) any{
+	"number": func(rev models.ProjectRevision) any { return int64(rev.Number) },
+}
+
+func (r projectRevisionRepository) FindByNumber(
+	_ context.Context, projectID int64, number int,
+) (*models.ProjectRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, revision := range r.s.revisions {
+		if revision.ProjectID == projectID && revision.Number == number {
+			return &revision, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r projectRevisionRepository) ListByProject(
+	_ context.Context, projectID int64, page pagination.Request,
+) ([]models.ProjectRevision, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	revisions := r.list(projectID)
+	for i := range revisions {
+		revisions[i].Snapshot = nil
+	}
+	return paginate(revisions, page, revisionSortKeys, func(rev models.ProjectRevision) int64 { return rev.ID })
+}
+
+func (r projectRevisionRepository) Restore(
+	_ context.Context, project *models.Project, steps []models.ProjectStep, parts []models.ProjectPart,
+	revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.projects[project.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	project.AverageRating = current.AverageRating
+	project.RatingCount = current.RatingCount
+	project.Histogram = current.Histogram
+	project.UpdatedAt = time.Now()
+	r.s.projects[project.ID] = *project
+
+	for id, step := range r.s.steps {
+		if step.ProjectID == project.ID {
+			delete(r.s.steps, id)
+		}
+	}
+	for i := range steps {
+		steps[i].ID = r.s.newID()
+		steps[i].CreatedAt = project.UpdatedAt
+		steps[i].UpdatedAt = project.UpdatedAt
+		steps[i].ProjectID = project.ID
+		steps[i].Position = i
+		r.s.steps[steps[i].ID] = steps[i]
+	}
+	for id, part := range r.s.parts {
+		if part.ProjectID == project.ID {
+			delete(r.s.parts, id)
+		}
+	}
+	for i := range parts {
+		parts[i].ID = r.s.newID()
+		parts[i].CreatedAt = project.UpdatedAt
+		parts[i].ProjectID = project.ID
+		parts[i].Position = i
+		r.s.parts[parts[i].ID] = parts[i]
+	}
+	projectStepRepository{r.s}.sync(project.ID)
+	r.s.saveRevision(project.ID, revision)
+	return nil
+}
+
+func (r projectRevisionRepository) list(projectID int64) []models.ProjectRevision {
+	return r.s.revisionsOf(projectID)
+}
+
+func (s *Store) revisionsOf(projectID int64) []models.ProjectRevision {
+	return sortedByID(s.revisions, func(rev models.ProjectRevision) bool { return rev.ProjectID == projectID })
+}
+
+// saveRevision replica saveRevision del repositorio de gorm; se llama con s.mu tomado, en la misma
+// escritura que el cambio
+func (s *Store) saveRevision(projectID int64, revision repositories.Revision) {
+	project := s.projects[projectID]
+	snapshot := models.NewProjectSnapshot(&project, projectStepRepository{s}.list(projectID),
+		projectPartRepository{s}.list(projectID))
+	saved := models.ProjectRevision{
+		ID:           s.newID(),
+		CreatedAt:    time.Now(),
+		ProjectID:    projectID,
+		Number:       1,
+		AuthorID:     revision.AuthorID,
+		RestoredFrom: revision.RestoredFrom,
+		Changed:      []string{},
+		Snapshot:     &snapshot,
+	}
+	revisions := s.revisionsOf(projectID)
+	if len(revisions) > 0 {
+		latest := revisions[0]
+		for _, other := range revisions {
+			if other.Number > latest.Number {
+				latest = other
+			}
+		}
+		saved.Changed = models.ChangedFields(models.DiffSnapshots(latest.Snapshot, &snapshot))
+		if len(saved.Changed) == 0 {
+			return
+		}
+		saved.Number = latest.Number + 1
+	}
+	s.revisions[saved.ID] = saved
+
+	retention := revision.Retention
+	for _, other := range revisions {
+		tooMany := retention.Keep > 0 && other.Number <= saved.Number-retention.Keep
+		tooOld := retention.MaxAge > 0 && other.CreatedAt.Before(saved.CreatedAt.Add(-retention.MaxAge))
+		if tooMany || tooOld {
+			delete(s.revisions, other.ID)
+		}
+	}
+}
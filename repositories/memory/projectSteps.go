@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type projectStepRepository struct{ s *Store }
+
+func (r projectStepRepository) FindByID(_ context.Context, id int64) (*models.ProjectStep, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	step, ok := r.s.steps[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &step, nil
+}
+
+func (r projectStepRepository) ListByProject(_ context.Context, projectID int64) ([]models.ProjectStep, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.list(projectID), nil
+}
+
+func (r projectStepRepository) Create(
+	_ context.Context, step *models.ProjectStep, revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projects[step.ProjectID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	steps := r.list(step.ProjectID)
+	if step.Position < 0 || step.Position > len(steps) {
+		step.Position = len(steps)
+	}
+	for _, other := range steps {
+		if other.Position >= step.Position {
+			other.Position++
+			r.s.steps[other.ID] = other
+		}
+	}
+	step.ID = r.s.newID()
+	step.CreatedAt = time.Now()
+	step.UpdatedAt = step.CreatedAt
+	r.s.steps[step.ID] = *step
+	r.sync(step.ProjectID)
+	r.s.saveRevision(step.ProjectID, revision)
+	return nil
+}
+
+func (r projectStepRepository) Update(
+	_ context.Context, step *models.ProjectStep, revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.steps[step.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	current.Title = step.Title
+	current.Body = step.Body
+	current.BodyHTML = step.BodyHTML
+	current.Images = step.Images
+	current.EstimatedMinutes = step.EstimatedMinutes
+	current.Tools = step.Tools
+	current.SafetyNotes = step.SafetyNotes
+	current.UpdatedAt = time.Now()
+	step.UpdatedAt = current.UpdatedAt
+	r.s.steps[step.ID] = current
+	r.sync(current.ProjectID)
+	r.s.saveRevision(current.ProjectID, revision)
+	return nil
+}
+
+func (r projectStepRepository) Delete(
+	_ context.Context, step *models.ProjectStep, revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.steps[step.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.steps, step.ID)
+	for _, other := range r.list(current.ProjectID) {
+		if other.Position > current.Position {
+			other.Position--
+			r.s.steps[other.ID] = other
+		}
+	}
+	r.sync(current.ProjectID)
+	r.s.saveRevision(current.ProjectID, revision)
+	return nil
+}
+
+func (r projectStepRepository) Reorder(
+	_ context.Context, projectID int64, ids []int64, revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, id := range ids {
+		if step, ok := r.s.steps[id]; !ok || step.ProjectID != projectID {
+			return repositories.ErrNotFound
+		}
+	}
+	for position, id := range ids {
+		step := r.s.steps[id]
+		step.Position = position
+		r.s.steps[id] = step
+	}
+	r.sync(projectID)
+	r.s.saveRevision(projectID, revision)
+	return nil
+}
+
+func (r projectStepRepository) UpdateRendered(_ context.Context, projectID int64, steps []models.ProjectStep) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, step := range steps {
+		if current, ok := r.s.steps[step.ID]; ok && current.ProjectID == projectID {
+			current.BodyHTML = step.BodyHTML
+			r.s.steps[step.ID] = current
+		}
+	}
+	r.sync(projectID)
+	return nil
+}
+
+func (r projectStepRepository) list(projectID int64) []models.ProjectStep {
+	steps := sortedByID(r.s.steps, func(s models.ProjectStep) bool { return s.ProjectID == projectID })
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Position < steps[j].Position })
+	return steps
+}
+
+// sync replica syncTutorial del repositorio de gorm
+func (r projectStepRepository) sync(projectID int64) {
+	project, ok := r.s.projects[projectID]
+	if !ok {
+		return
+	}
+	steps := r.list(projectID)
+	project.Tutorial = models.TutorialText(steps)
+	project.TutorialHTML = models.TutorialHTML(steps)
+	if minutes, ok := models.StepMinutes(steps); ok {
+		project.TimeToBuild = minutes
+	}
+	r.s.projects[projectID] = project
+}
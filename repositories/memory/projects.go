@@ -0,0 +1,305 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type projectRepository struct{ s *Store }
+
+func (r projectRepository) FindByID(_ context.Context, id int64) (*models.Project, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	project, ok := r.s.projects[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &project, nil
+}
+
+func (r projectRepository) FindByIDs(_ context.Context, ids []int64) ([]models.Project, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	wanted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return sortedByID(r.s.projects, func(p models.Project) bool { return wanted[p.ID] }), nil
+}
+
+// projectSortKeys son las claves de orden comunes a todos los listados de proyectos
+var projectSortKeys = map[string]func(models.Project) any{
+	"created_at":     func(p models.Project) any { return p.CreatedAt },
+	"average_rating": func(p models.Project) any { return p.AverageRating },
+	"rating_count":   func(p models.Project) any { return p.RatingCount },
+	"time_to_build":  func(p models.Project) any { return p.TimeToBuild },
+}
+
+func (r projectRepository) ListByOwner(
+	_ context.Context, ownerID int64, page pagination.Request,
+) ([]models.Project, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	projects := sortedByID(r.s.projects, func(p models.Project) bool { return p.Owner == ownerID })
+	return paginate(projects, page, projectSortKeys, func(p models.Project) int64 { return p.ID })
+}
+
+func (r projectRepository) ListByList(
+	_ context.Context, listID int64, page pagination.Request,
+) ([]models.Project, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	inList := map[int64]bool{}
+	for _, item := range r.s.projectListItem {
+		if item.ProjectListID == listID {
+			inList[item.ProjectID] = true
+		}
+	}
+	projects := sortedByID(r.s.projects, func(p models.Project) bool { return inList[p.ID] })
+	return paginate(projects, page, projectSortKeys, func(p models.Project) int64 { return p.ID })
+}
+
+func (r projectRepository) Search(
+	_ context.Context, filter repositories.ProjectFilter, page pagination.Request,
+) ([]models.Project, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var text textQuery
+	if filter.Query != "" {
+		text = parseTextQuery(filter.Query)
+	}
+	projects := sortedByID(r.s.projects, func(p models.Project) bool {
+		return p.IsPublic && matches(p, filter)
+	})
+	if filter.Query != "" {
+		for i := range projects {
+			projects[i].Search = text.match(projects[i])
+		}
+	}
+
+	keys := map[string]func(models.Project) any{}
+	for key, value := range projectSortKeys {
+		keys[key] = value
+	}
+	prior := repositories.BayesianPrior{}
+	if filter.Bayesian != nil {
+		prior = *filter.Bayesian
+	}
+	keys["bayesian_rating"] = func(p models.Project) any { return p.Histogram.BayesianScore(prior.Mean, prior.Weight) }
+	if filter.Query != "" {
+		keys["relevance"] = func(p models.Project) any { return p.Search.Rank }
+	}
+	return paginate(projects, page, keys, func(p models.Project) int64 { return p.ID })
+}
+
+func (r projectRepository) Facets(_ context.Context, filter repositories.ProjectFilter) (*repositories.Facets, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return repositories.CountFacets(sortedByID(r.s.projects, nil), filter, matches), nil
+}
+
+// matches indica si el proyecto cumple todas las condiciones del filtro (sin mirar is_public)
+func matches(p models.Project, filter repositories.ProjectFilter) bool {
+	switch {
+	case !matchArray(p.Style, filter.Style, filter.StyleMatch):
+		return false
+	case !matchArray(p.Materials, filter.Materials, filter.MaterialsMatch):
+		return false
+	case !matchArray(p.Tools, filter.Tools, filter.ToolsMatch):
+		return false
+	case len(filter.MainMaterial) > 0 && !contains(filter.MainMaterial, p.MainMaterial):
+		return false
+	case len(filter.Environment) > 0 && !contains(filter.Environment, p.Environment):
+		return false
+	case len(filter.Owners) > 0 && !slices.Contains(filter.Owners, p.Owner):
+		return false
+	case filter.Title != "" && !strings.Contains(strings.ToLower(p.Title), strings.ToLower(filter.Title)):
+		return false
+	case !inRange(float64(p.Height), filter.Height), !inRange(float64(p.Width), filter.Width),
+		!inRange(float64(p.Length), filter.Length), !inRange(int64(p.TimeToBuild), filter.TimeToBuild),
+		!inRange(float64(p.AverageRating), filter.AverageRating), !inRange(int64(p.RatingCount), filter.RatingCount):
+		return false
+	case filter.CreatedAt.Min != nil && p.CreatedAt.Before(*filter.CreatedAt.Min),
+		filter.CreatedAt.Max != nil && p.CreatedAt.After(*filter.CreatedAt.Max):
+		return false
+	case filter.FitsIn != nil && !filter.FitsIn.Fits(float64(p.Height), float64(p.Width), float64(p.Length)):
+		return false
+	case filter.Query != "" && parseTextQuery(filter.Query).match(p) == nil:
+		return false
+	}
+	return true
+}
+
+// matchArray compara un array del proyecto contra un filtro de varios valores
+func matchArray(values, wanted []string, match repositories.ArrayMatch) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	switch match {
+	case repositories.MatchAll:
+		for _, want := range wanted {
+			if !contains(values, want) {
+				return false
+			}
+		}
+		return true
+	case repositories.MatchOnly:
+		for _, value := range values {
+			if !contains(wanted, value) {
+				return false
+			}
+		}
+		return true
+	}
+	return containsAny(values, wanted)
+}
+
+func inRange[T int64 | float64](value T, r repositories.Range[T]) bool {
+	return (r.Min == nil || value >= *r.Min) && (r.Max == nil || value <= *r.Max)
+}
+
+func (r projectRepository) ListBuildable(
+	_ context.Context, filter repositories.BuildableFilter, page pagination.Request,
+) ([]repositories.BuildableProject, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var projects []repositories.BuildableProject
+	for _, p := range sortedByID(r.s.projects, func(p models.Project) bool { return p.IsPublic }) {
+		if project := repositories.Cover(p, filter); len(project.MissingTools) <= filter.MaxMissingTools {
+			projects = append(projects, project)
+		}
+	}
+
+	keys := map[string]func(repositories.BuildableProject) any{
+		"missing": func(p repositories.BuildableProject) any { return p.Rank },
+	}
+	for key, value := range projectSortKeys {
+		keys[key] = func(p repositories.BuildableProject) any { return value(p.Project) }
+	}
+	return paginate(projects, page, keys, func(p repositories.BuildableProject) int64 { return p.ID })
+}
+
+func (r projectRepository) ListAfterID(_ context.Context, afterID int64, limit int) ([]models.Project, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	projects := sortedByID(r.s.projects, func(p models.Project) bool { return p.ID > afterID })
+	if len(projects) > limit {
+		projects = projects[:limit]
+	}
+	return projects, nil
+}
+
+func (r projectRepository) UpdateRendered(_ context.Context, project *models.Project) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.projects[project.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	current.DescriptionHTML = project.DescriptionHTML
+	r.s.projects[project.ID] = current
+	return nil
+}
+
+func (r projectRepository) UpdateTaxonomy(
+	_ context.Context, project *models.Project, revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.projects[project.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	current.Tools = project.Tools
+	current.Materials = project.Materials
+	current.Style = project.Style
+	current.MainMaterial = project.MainMaterial
+	current.Environment = project.Environment
+	r.s.projects[project.ID] = current
+	r.s.saveRevision(project.ID, revision)
+	return nil
+}
+
+func (r projectRepository) Create(
+	_ context.Context, project *models.Project, steps []models.ProjectStep, revision repositories.Revision,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[project.Owner]; !ok {
+		return repositories.ErrMissingReference
+	}
+	project.ID = r.s.newID()
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = project.CreatedAt
+	r.s.projects[project.ID] = *project
+	for i := range steps {
+		steps[i].ID = r.s.newID()
+		steps[i].CreatedAt = project.CreatedAt
+		steps[i].UpdatedAt = project.CreatedAt
+		steps[i].ProjectID = project.ID
+		steps[i].Position = i
+		r.s.steps[steps[i].ID] = steps[i]
+	}
+	if len(steps) > 0 {
+		projectStepRepository{r.s}.sync(project.ID)
+	}
+	r.s.saveRevision(project.ID, revision)
+	return nil
+}
+
+func (r projectRepository) Update(_ context.Context, project *models.Project, revision repositories.Revision) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.projects[project.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	project.AverageRating = current.AverageRating
+	project.RatingCount = current.RatingCount
+	project.Histogram = current.Histogram
+	project.UpdatedAt = time.Now()
+	r.s.projects[project.ID] = *project
+	projectStepRepository{r.s}.sync(project.ID)
+	r.s.saveRevision(project.ID, revision)
+	return nil
+}
+
+func (r projectRepository) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projects[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.projects, id)
+	return nil
+}
+
+func (r projectRepository) Count(_ context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return int64(len(r.s.projects)), nil
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, wanted []string) bool {
+	for _, want := range wanted {
+		if contains(values, want) {
+			return true
+		}
+	}
+	return false
+}
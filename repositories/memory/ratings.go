@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type ratingRepository struct{ s *Store }
+
+func (r ratingRepository) FindByID(_ context.Context, id int64) (*models.Rating, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rating, ok := r.s.ratings[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &rating, nil
+}
+
+func (r ratingRepository) FindByUserAndProject(_ context.Context, userID, projectID int64) (*models.Rating, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, rating := range r.s.ratings {
+		if rating.UserID == userID && rating.ProjectID == projectID {
+			return &rating, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r ratingRepository) ListByUserAndProjects(_ context.Context, userID int64, projectIDs []int64) ([]models.Rating, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	wanted := make(map[int64]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		wanted[id] = true
+	}
+	return sortedByID(r.s.ratings, func(rt models.Rating) bool { return rt.UserID == userID && wanted[rt.ProjectID] }), nil
+}
+
+// ratingSortKeys son las claves de orden del listado de valoraciones de un proyecto
+var ratingSortKeys = map[string]func(models.Rating) any{
+	"created_at": func(rt models.Rating) any { return rt.CreatedAt },
+	"value":      func(rt models.Rating) any { return int64(rt.Value) },
+}
+
+func (r ratingRepository) ListByProject(
+	_ context.Context, projectID int64, page pagination.Request,
+) ([]models.Rating, *int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ratings := sortedByID(r.s.ratings, func(rt models.Rating) bool { return rt.ProjectID == projectID })
+	return paginate(ratings, page, ratingSortKeys, func(rt models.Rating) int64 { return rt.ID })
+}
+
+func (r ratingRepository) Create(_ context.Context, rating *models.Rating) (*repositories.RatingAggregate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projects[rating.ProjectID]; !ok {
+		return nil, repositories.ErrMissingReference
+	}
+	if _, ok := r.s.users[rating.UserID]; !ok {
+		return nil, repositories.ErrMissingReference
+	}
+	for _, existing := range r.s.ratings {
+		if existing.UserID == rating.UserID && existing.ProjectID == rating.ProjectID {
+			return nil, repositories.ErrConflict
+		}
+	}
+	rating.ID = r.s.newID()
+	rating.CreatedAt = time.Now()
+	rating.UpdatedAt = rating.CreatedAt
+	r.s.ratings[rating.ID] = *rating
+	return r.s.applyRating(rating.ProjectID, 0, rating.Value), nil
+}
+
+func (r ratingRepository) Update(_ context.Context, rating *models.Rating) (*repositories.RatingAggregate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	previous, ok := r.s.ratings[rating.ID]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	rating.UpdatedAt = time.Now()
+	r.s.ratings[rating.ID] = *rating
+	return r.s.applyRating(rating.ProjectID, previous.Value, rating.Value), nil
+}
+
+func (r ratingRepository) Delete(_ context.Context, rating *models.Rating) (*repositories.RatingAggregate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	previous, ok := r.s.ratings[rating.ID]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	delete(r.s.ratings, rating.ID)
+	return r.s.applyRating(rating.ProjectID, previous.Value, 0), nil
+}
+
+func (r ratingRepository) Count(_ context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return int64(len(r.s.ratings)), nil
+}
+
+func (r ratingRepository) Average(_ context.Context) (float64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if len(r.s.ratings) == 0 {
+		return 0, nil
+	}
+	var sum float64
+	for _, rating := range r.s.ratings {
+		sum += float64(rating.Value)
+	}
+	return sum / float64(len(r.s.ratings)), nil
+}
+
+// applyRating actualiza el histograma del proyecto con el valor anterior y el nuevo (0 si no hay)
+// y deriva los agregados. Debe llamarse con el lock tomado.
+func (s *Store) applyRating(projectID int64, previous, current int8) *repositories.RatingAggregate {
+	project := s.projects[projectID]
+	project.Histogram.Add(previous, -1)
+	project.Histogram.Add(current, 1)
+	aggregate := repositories.AggregateOf(project.Histogram)
+	project.AverageRating = aggregate.AverageRating
+	project.RatingCount = aggregate.RatingCount
+	s.projects[projectID] = project
+	return &aggregate
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"html"
+	"strings"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// textQuery aproxima websearch_to_tsquery: cada grupo es una alternativa de terminos unidos por "or"
+// y todos los grupos deben aparecer; los terminos con "-" excluyen. No hay stemming: un termino
+// coincide si es substring del texto normalizado, lo que alcanza para "roble" contra "robles".
+type textQuery struct {
+	groups   [][]string
+	excluded []string
+}
+
+// accents son los reemplazos que hace unaccent sobre el texto en castellano
+var accents = map[rune]rune{
+	'á': 'a', 'é': 'e', 'í': 'i', 'ó': 'o', 'ú': 'u', 'ü': 'u', 'ñ': 'n', 'à': 'a', 'è': 'e', 'ì': 'i', 'ò': 'o', 'ù': 'u',
+}
+
+// fold pasa el texto a minusculas sin acentos, conservando un rune por cada rune del original
+func fold(text string) []rune {
+	runes := []rune(strings.ToLower(text))
+	for i, r := range runes {
+		if plain, ok := accents[r]; ok {
+			runes[i] = plain
+		}
+	}
+	return runes
+}
+
+func parseTextQuery(raw string) textQuery {
+	var q textQuery
+	var words []string
+	for i, part := range strings.Split(raw, `"`) {
+		if i%2 == 1 {
+			// frase entre comillas: se busca completa
+			if phrase := strings.Join(strings.Fields(part), " "); phrase != "" {
+				words = append(words, phrase)
+			}
+			continue
+		}
+		words = append(words, strings.Fields(part)...)
+	}
+
+	alternative := false
+	for _, word := range words {
+		switch {
+		case strings.EqualFold(word, "or"):
+			alternative = len(q.groups) > 0
+		case strings.HasPrefix(word, "-") && len(word) > 1:
+			q.excluded = append(q.excluded, string(fold(word[1:])))
+			alternative = false
+		case alternative:
+			last := len(q.groups) - 1
+			q.groups[last] = append(q.groups[last], string(fold(word)))
+			alternative = false
+		default:
+			q.groups = append(q.groups, []string{string(fold(word))})
+		}
+	}
+	return q
+}
+
+// match devuelve el SearchMatch del proyecto, o nil si no cumple la consulta
+func (q textQuery) match(p models.Project) *models.SearchMatch {
+	if len(q.groups) == 0 {
+		return nil
+	}
+	// mismos pesos relativos que ts_rank para A, B, C y D
+	fields := []struct {
+		text   string
+		weight float32
+	}{
+		{p.Title, 1},
+		{strings.Join(append(append([]string{}, p.Style...), p.Materials...), " "), 0.4},
+		{p.Description + " " + strings.Join(p.Tools, " "), 0.2},
+		{p.Tutorial, 0.1},
+	}
+	documents := make([]string, len(fields))
+	for i, field := range fields {
+		documents[i] = string(fold(field.text))
+	}
+	document := strings.Join(documents, "\n")
+
+	for _, term := range q.excluded {
+		if strings.Contains(document, term) {
+			return nil
+		}
+	}
+
+	var rank float32
+	var found []string
+	for _, group := range q.groups {
+		matched := false
+		for _, term := range group {
+			for i, field := range fields {
+				if n := strings.Count(documents[i], term); n > 0 {
+					rank += float32(n) * field.weight
+					matched = true
+				}
+			}
+			if strings.Contains(document, term) {
+				found = append(found, term)
+			}
+		}
+		if !matched {
+			return nil
+		}
+	}
+
+	return &models.SearchMatch{
+		Rank:        rank,
+		Title:       highlight(p.Title, found),
+		Description: highlight(p.Description, found),
+	}
+}
+
+// highlight escapa el texto como HTML y envuelve en <mark> cada aparicion de los terminos
+func highlight(text string, terms []string) string {
+	original := []rune(text)
+	folded := fold(text)
+	marked := make([]bool, len(original))
+	for _, term := range terms {
+		needle := []rune(term)
+		for i := 0; i+len(needle) <= len(folded); i++ {
+			if string(folded[i:i+len(needle)]) == term {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range original {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(original)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+	return b.String()
+}
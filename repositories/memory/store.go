@@ -0,0 +1,142 @@
+// Package memory implementa los repositorios en memoria para poder probar los handlers sin Postgres
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// Store guarda todas las tablas en memoria. Respeta las mismas restricciones unique y de claves
+// foraneas que el esquema de Postgres para que los handlers vean los mismos errores.
+type Store struct {
+	mu sync.Mutex
+
+	nextID          int64
+	users           map[int64]models.User
+	projects        map[int64]models.Project
+	comments        map[int64]models.Comment
+	ratings         map[int64]models.Rating
+	projectLists    map[int64]models.ProjectList
+	projectListItem map[int64]models.ProjectListItem
+	profilePictures map[int64]models.ProfilePicture
+	inventory       map[int64]models.InventoryItem
+	taxonomy        map[int64]models.TaxonomyTerm
+	parts           map[int64]models.ProjectPart
+	prices          map[int64]models.MaterialPrice
+	steps           map[int64]models.ProjectStep
+	uploads         map[string]models.Upload
+	revisions       map[int64]models.ProjectRevision
+}
+
+// NewStore crea un Store vacio
+func NewStore() *Store {
+	return &Store{
+		users:           map[int64]models.User{},
+		projects:        map[int64]models.Project{},
+		comments:        map[int64]models.Comment{},
+		ratings:         map[int64]models.Rating{},
+		projectLists:    map[int64]models.ProjectList{},
+		projectListItem: map[int64]models.ProjectListItem{},
+		profilePictures: map[int64]models.ProfilePicture{},
+		inventory:       map[int64]models.InventoryItem{},
+		taxonomy:        map[int64]models.TaxonomyTerm{},
+		parts:           map[int64]models.ProjectPart{},
+		prices:          map[int64]models.MaterialPrice{},
+		steps:           map[int64]models.ProjectStep{},
+		uploads:         map[string]models.Upload{},
+		revisions:       map[int64]models.ProjectRevision{},
+	}
+}
+
+// New crea todos los repositorios sobre un Store nuevo
+func New() (*repositories.Repositories, *Store) {
+	s := NewStore()
+	return s.Repositories(), s
+}
+
+// Repositories devuelve todos los repositorios respaldados por el Store
+func (s *Store) Repositories() *repositories.Repositories {
+	return &repositories.Repositories{
+		Users:           userRepository{s},
+		Projects:        projectRepository{s},
+		Comments:        commentRepository{s},
+		Ratings:         ratingRepository{s},
+		ProjectLists:    projectListRepository{s},
+		ProfilePictures: profilePictureRepository{s},
+		Inventory:       inventoryRepository{s},
+		Taxonomy:        taxonomyRepository{s},
+		Parts:           projectPartRepository{s},
+		Steps:           projectStepRepository{s},
+		Prices:          priceRepository{s},
+		Uploads:         uploadRepository{s},
+		Revisions:       projectRevisionRepository{s},
+	}
+}
+
+// SetNextID fija el proximo id a asignar, util para probar ids grandes
+func (s *Store) SetNextID(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID = id - 1
+}
+
+// AddProfilePicture agrega una foto de perfil por defecto
+func (s *Store) AddProfilePicture(picture models.ProfilePicture) models.ProfilePicture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if picture.ID == 0 {
+		picture.ID = s.newID()
+	}
+	if picture.Position == 0 {
+		picture.Position = s.lastPicturePosition() + 1
+	}
+	s.profilePictures[picture.ID] = picture
+	return picture
+}
+
+func (s *Store) lastPicturePosition() int {
+	last := 0
+	for _, picture := range s.profilePictures {
+		last = max(last, picture.Position)
+	}
+	return last
+}
+
+func (s *Store) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func sortedByID[T any](m map[int64]T, keep func(T) bool) []T {
+	ids := make([]int64, 0, len(m))
+	for id, v := range m {
+		if keep == nil || keep(v) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	out := make([]T, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, m[id])
+	}
+	return out
+}
+
+// paginate aplica la pagina sobre elementos ya filtrados; keys son las claves de orden soportadas
+func paginate[T any](
+	items []T, page pagination.Request, keys map[string]func(T) any, id func(T) int64,
+) ([]T, *int64, error) {
+	key, ok := keys[page.Order.Key]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported sort key %q", page.Order.Key)
+	}
+	out, total := pagination.Slice(items, page, func(item T) pagination.Keyset {
+		return pagination.Keyset{Value: key(item), ID: id(item)}
+	})
+	return out, total, nil
+}
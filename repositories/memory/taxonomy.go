@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type taxonomyRepository struct{ s *Store }
+
+func (r taxonomyRepository) List(_ context.Context) ([]models.TaxonomyTerm, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	terms := sortedByID(r.s.taxonomy, nil)
+	sort.SliceStable(terms, func(i, j int) bool { return terms[i].Kind < terms[j].Kind })
+	return terms, nil
+}
+
+func (r taxonomyRepository) Create(_ context.Context, term *models.TaxonomyTerm) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, existing := range r.s.taxonomy {
+		if existing.Kind == term.Kind && existing.Slug == term.Slug {
+			return repositories.ErrConflict
+		}
+	}
+	if !r.parentExists(*term) {
+		return repositories.ErrMissingReference
+	}
+	term.ID = r.s.newID()
+	term.CreatedAt = time.Now()
+	r.s.taxonomy[term.ID] = *term
+	return nil
+}
+
+func (r taxonomyRepository) Update(_ context.Context, term *models.TaxonomyTerm) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	current, ok := r.s.taxonomy[term.ID]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	current.Parent = term.Parent
+	current.LabelES = term.LabelES
+	current.LabelEN = term.LabelEN
+	current.Synonyms = term.Synonyms
+	if !r.parentExists(current) {
+		return repositories.ErrMissingReference
+	}
+	r.s.taxonomy[term.ID] = current
+	return nil
+}
+
+// parentExists replica la foreign key (kind, parent) -> (kind, slug)
+func (r taxonomyRepository) parentExists(term models.TaxonomyTerm) bool {
+	if term.Parent == nil {
+		return true
+	}
+	for _, existing := range r.s.taxonomy {
+		if existing.Kind == term.Kind && existing.Slug == *term.Parent {
+			return true
+		}
+	}
+	return false
+}
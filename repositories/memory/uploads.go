@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type uploadRepository struct{ s *Store }
+
+func (r uploadRepository) FindByID(_ context.Context, id string) (*models.Upload, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	upload, ok := r.s.uploads[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	upload.Variants = slices.Clone(upload.Variants)
+	return &upload, nil
+}
+
+func (r uploadRepository) FindByIDs(_ context.Context, ids []string) ([]models.Upload, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	uploads := []models.Upload{}
+	for _, id := range ids {
+		if upload, ok := r.s.uploads[id]; ok {
+			upload.Variants = slices.Clone(upload.Variants)
+			uploads = append(uploads, upload)
+		}
+	}
+	return uploads, nil
+}
+
+func (r uploadRepository) Usage(_ context.Context, userID int64) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.usage(userID), nil
+}
+
+func (r uploadRepository) usage(userID int64) int64 {
+	var used int64
+	for _, upload := range r.s.uploads {
+		if upload.UserID == userID {
+			used += upload.Size
+		}
+	}
+	return used
+}
+
+func (r uploadRepository) Create(_ context.Context, upload *models.Upload, quota int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[upload.UserID]; !ok {
+		return repositories.ErrMissingReference
+	}
+	if _, ok := r.s.uploads[upload.ID]; ok {
+		return repositories.ErrConflict
+	}
+	if r.usage(upload.UserID)+upload.Size > quota {
+		return repositories.ErrQuotaExceeded
+	}
+	upload.CreatedAt = time.Now()
+	r.s.uploads[upload.ID] = *upload
+	return nil
+}
+
+func (r uploadRepository) Delete(_ context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.uploads[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.uploads, id)
+	// como el ON DELETE SET NULL de users.avatar_upload
+	for userID, user := range r.s.users {
+		if user.AvatarUpload != nil && *user.AvatarUpload == id {
+			user.AvatarUpload = nil
+			r.s.users[userID] = user
+		}
+	}
+	return nil
+}
+
+func (r uploadRepository) InUse(_ context.Context, id, url string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, user := range r.s.users {
+		if user.AvatarUpload != nil && *user.AvatarUpload == id {
+			return true, nil
+		}
+	}
+	for _, project := range r.s.projects {
+		if project.Portrait == url || slices.Contains(project.Images, url) {
+			return true, nil
+		}
+	}
+	for _, step := range r.s.steps {
+		if slices.Contains(step.Images, url) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r uploadRepository) ListUnprocessed(_ context.Context, limit int) ([]models.Upload, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var uploads []models.Upload
+	for _, upload := range r.s.uploads {
+		if upload.ProcessedAt == nil {
+			uploads = append(uploads, upload)
+		}
+	}
+	slices.SortFunc(uploads, func(a, b models.Upload) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return uploads[:min(limit, len(uploads))], nil
+}
+
+func (r uploadRepository) SaveVariants(
+	_ context.Context, id string, variants []models.UploadVariant, blurhash, dominantColor string,
+) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	upload, ok := r.s.uploads[id]
+	if !ok {
+		return repositories.ErrNotFound
+	}
+	upload.Variants = make([]models.UploadVariant, len(variants))
+	for i, variant := range variants {
+		variant.UploadID = id
+		upload.Variants[i] = variant
+	}
+	slices.SortFunc(upload.Variants, func(a, b models.UploadVariant) int {
+		if a.Width != b.Width {
+			return a.Width - b.Width
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	upload.Blurhash = blurhash
+	upload.DominantColor = dominantColor
+	now := time.Now()
+	upload.ProcessedAt = &now
+	r.s.uploads[id] = upload
+	return nil
+}
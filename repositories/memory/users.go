@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+type userRepository struct{ s *Store }
+
+func (r userRepository) FindByID(_ context.Context, id int64) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	user, ok := r.s.users[id]
+	if !ok {
+		return nil, repositories.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r userRepository) FindByFirebaseUID(_ context.Context, uid string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, user := range r.s.users {
+		if user.FirebaseUID == uid {
+			return &user, nil
+		}
+	}
+	return nil, repositories.ErrNotFound
+}
+
+func (r userRepository) Create(_ context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, existing := range r.s.users {
+		if existing.FirebaseUID == user.FirebaseUID {
+			return repositories.ErrConflict
+		}
+	}
+	user.ID = r.s.newID()
+	user.CreatedAt = time.Now()
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r userRepository) Update(_ context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[user.ID]; !ok {
+		return repositories.ErrNotFound
+	}
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r userRepository) Delete(_ context.Context, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[id]; !ok {
+		return repositories.ErrNotFound
+	}
+	delete(r.s.users, id)
+	return nil
+}
+
+func (r userRepository) Count(_ context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return int64(len(r.s.users)), nil
+}
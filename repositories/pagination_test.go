@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+// BenchmarkDeepPage compara una pagina lejana de los proyectos de un usuario pedida por keyset,
+// como la arma paginate sobre el indice (owner, created_at, id) de la migracion 0005, con la misma
+// pagina pedida con OFFSET. El keyset cuesta lo mismo en cualquier pagina; OFFSET recorre todas las
+// filas anteriores.
+//
+//	TEST_DATABASE_URL=... go test ./repositories -run '^$' -bench DeepPage
+func BenchmarkDeepPage(b *testing.B) {
+	const rows, depth, limit = 20000, 19000, 20
+	db := testDB(b)
+	repos := NewGorm(db)
+	ctx := context.Background()
+	owner := testUser(b, repos, "bench")
+
+	// de a tres proyectos por segundo, asi hay empates en created_at que desempata el id
+	base := time.Now().Add(-rows * time.Second)
+	projects := make([]models.Project, rows)
+	for i := range projects {
+		projects[i] = models.Project{
+			Owner: owner.ID, Title: "Proyecto", IsPublic: true, CreatedAt: base.Add(time.Duration(i/3) * time.Second),
+			Materials: []string{}, Tools: []string{}, Style: []string{}, Images: []string{},
+		}
+	}
+	if err := db.CreateInBatches(&projects, 1000).Error; err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Where("owner = ?", owner.ID).Delete(&models.Project{}) })
+
+	offset := func() []int64 {
+		var ids []int64
+		err := db.WithContext(ctx).Model(&models.Project{}).Where("owner = ?", owner.ID).
+			Order("created_at DESC, id DESC").Offset(depth).Limit(limit+1).Pluck("id", &ids).Error
+		if err != nil {
+			b.Fatal(err)
+		}
+		return ids
+	}
+
+	// la posicion del ultimo elemento de la pagina anterior es lo que trae el cursor
+	var last models.Project
+	err := db.Where("owner = ?", owner.ID).Order("created_at DESC, id DESC").Offset(depth - 1).First(&last).Error
+	if err != nil {
+		b.Fatal(err)
+	}
+	page := pagination.Request{
+		Order: pagination.Order{Key: "created_at", Desc: true},
+		After: &pagination.Keyset{Value: last.CreatedAt, ID: last.ID},
+		Limit: limit,
+	}
+	keyset := func() []int64 {
+		found, _, err := repos.Projects.ListByOwner(ctx, owner.ID, page)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ids := make([]int64, len(found))
+		for i, project := range found {
+			ids[i] = project.ID
+		}
+		return ids
+	}
+
+	if k, o := keyset(), offset(); !slices.Equal(k, o) {
+		b.Fatalf("keyset page %v differs from offset page %v", k, o)
+	}
+	b.Run("keyset", func(b *testing.B) {
+		for b.Loop() {
+			keyset()
+		}
+	})
+	b.Run("offset", func(b *testing.B) {
+		for b.Loop() {
+			offset()
+		}
+	})
+}
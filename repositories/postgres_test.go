@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/migrations"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testDB abre la base de TEST_DATABASE_URL con las migraciones aplicadas. Los tests que la usan
+// necesitan Postgres y se saltean si la variable no esta definida; sirve tambien para benchmarks.
+func testDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrations.New(sqlDB)
+	if err == nil {
+		_, err = migrator.Up(context.Background())
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// testUser crea un usuario con un firebase_uid unico y lo borra al terminar el test
+func testUser(t testing.TB, repos *Repositories, name string) *models.User {
+	t.Helper()
+	user := models.User{
+		Username:    name,
+		FirebaseUID: name + "-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	if err := repos.Users.Create(context.Background(), &user); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repos.Users.Delete(context.Background(), user.ID) })
+	return &user
+}
+
+// testProject crea un proyecto publico de owner y lo borra al terminar el test
+func testProject(t *testing.T, repos *Repositories, owner *models.User, title string) *models.Project {
+	t.Helper()
+	project := models.Project{
+		Owner: owner.ID, Title: title, IsPublic: true,
+		Materials: []string{}, Tools: []string{}, Style: []string{}, Images: []string{},
+	}
+	if err := repos.Projects.Create(context.Background(), &project, nil, Revision{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repos.Projects.Delete(context.Background(), project.ID) })
+	return &project
+}
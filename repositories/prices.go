@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type priceRepository struct {
+	db *gorm.DB
+}
+
+func (r *priceRepository) FindByID(ctx context.Context, id int64) (*models.MaterialPrice, error) {
+	var price models.MaterialPrice
+	if err := r.db.WithContext(ctx).First(&price, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &price, nil
+}
+
+// priceSortColumns son las claves de orden del catalogo de precios
+var priceSortColumns = sortColumns{
+	"effective_date": column("effective_date"),
+	"material":       column("material"),
+}
+
+func (r *priceRepository) List(
+	ctx context.Context, filter PriceFilter, page pagination.Request,
+) ([]models.MaterialPrice, *int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.MaterialPrice{})
+	if filter.Material != "" {
+		query = query.Where("material = ?", filter.Material)
+	}
+	if filter.Unit != "" {
+		query = query.Where("unit = ?", filter.Unit)
+	}
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if filter.Region != nil {
+		query = query.Where("region = ?", *filter.Region)
+	}
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err = paginate(query, page, priceSortColumns, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	var prices []models.MaterialPrice
+	if err := query.Find(&prices).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	return prices, total, nil
+}
+
+func (r *priceRepository) ListEffective(
+	ctx context.Context, materials []string, currency string, regions []string, date time.Time,
+) ([]models.MaterialPrice, error) {
+	var prices []models.MaterialPrice
+	if len(materials) == 0 || len(regions) == 0 {
+		return prices, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("material IN ? AND currency = ? AND region IN ? AND effective_date <= ?",
+			materials, currency, regions, date).
+		Order("effective_date DESC, id DESC").
+		Find(&prices).Error
+	return prices, translate(err)
+}
+
+func (r *priceRepository) Create(ctx context.Context, price *models.MaterialPrice) error {
+	return translate(r.db.WithContext(ctx).Create(price).Error)
+}
+
+func (r *priceRepository) Update(ctx context.Context, price *models.MaterialPrice) error {
+	result := r.db.WithContext(ctx).Model(&models.MaterialPrice{ID: price.ID}).
+		Select("material", "unit", "price", "currency", "region", "effective_date", "supplier", "updated_at").
+		Updates(price)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *priceRepository) Delete(ctx context.Context, id int64) error {
+	return deleteByID(r.db.WithContext(ctx), &models.MaterialPrice{}, id)
+}
+
+// Import inserta los precios nuevos y pisa precio y proveedor de los que ya estaban
+func (r *priceRepository) Import(ctx context.Context, prices []models.MaterialPrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "material"}, {Name: "unit"}, {Name: "currency"}, {Name: "region"}, {Name: "effective_date"},
+			},
+			DoUpdates: clause.Assignments(map[string]any{
+				"price":      gorm.Expr("excluded.price"),
+				"supplier":   gorm.Expr("excluded.supplier"),
+				"updated_at": gorm.Expr("now()"),
+			}),
+		}).CreateInBatches(prices, 500).Error
+	}))
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"gorm.io/gorm"
+)
+
+type profilePictureRepository struct {
+	db *gorm.DB
+}
+
+func (r *profilePictureRepository) FindByID(ctx context.Context, id int64) (*models.ProfilePicture, error) {
+	var picture models.ProfilePicture
+	if err := r.db.WithContext(ctx).First(&picture, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &picture, nil
+}
+
+func (r *profilePictureRepository) List(ctx context.Context) ([]models.ProfilePicture, error) {
+	var pictures []models.ProfilePicture
+	err := r.db.WithContext(ctx).Where("retired_at IS NULL").Order("position, id").Find(&pictures).Error
+	return pictures, translate(err)
+}
+
+func (r *profilePictureRepository) Create(ctx context.Context, picture *models.ProfilePicture) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// el lock evita que dos altas a la vez tomen la misma posicion
+		if err := tx.Exec("LOCK TABLE profile_pictures IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		var last int
+		err := tx.Model(&models.ProfilePicture{}).Select("COALESCE(MAX(position), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		picture.Position = last + 1
+		picture.RetiredAt = nil
+		return tx.Create(picture).Error
+	}))
+}
+
+func (r *profilePictureRepository) Retire(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Model(&models.ProfilePicture{}).Where("id = ?", id).
+		Update("retired_at", gorm.Expr("COALESCE(retired_at, ?)", time.Now()))
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *profilePictureRepository) Reorder(ctx context.Context, ids []int64) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			err := tx.Model(&models.ProfilePicture{}).Where("id = ?", id).Update("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
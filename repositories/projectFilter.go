@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+)
+
+// ProjectFilter son los filtros que acepta la busqueda de proyectos. Entre filtros distintos se piden
+// todos (AND). En MainMaterial, Environment y Owners alcanza con coincidir con alguno de los valores;
+// en los arrays (Style, Materials, Tools) depende de su ArrayMatch.
+type ProjectFilter struct {
+	Style          []string
+	StyleMatch     ArrayMatch
+	Materials      []string
+	MaterialsMatch ArrayMatch
+	Tools          []string
+	ToolsMatch     ArrayMatch
+	MainMaterial   []string
+	Environment    []string
+	Owners         []int64
+	Title          string
+
+	Height        Range[float64]
+	Width         Range[float64]
+	Length        Range[float64]
+	TimeToBuild   Range[int64]
+	AverageRating Range[float64]
+	RatingCount   Range[int64]
+	CreatedAt     Range[time.Time]
+	// FitsIn, si no es nil, pide que el proyecto entre en una caja de esas medidas en cm, en
+	// cualquier orientacion
+	FitsIn *Box
+
+	// Query es una busqueda de texto con sintaxis websearch ("frase exacta", -excluir, or). Cada
+	// resultado trae su SearchMatch y se puede ordenar por la clave relevance.
+	Query string
+	// Bayesian es el prior que usa la clave de orden bayesian_rating
+	Bayesian *BayesianPrior
+}
+
+// ArrayMatch es como se compara un filtro de varios valores contra un array del proyecto
+type ArrayMatch string
+
+// Modos de comparacion de arrays. El vacio equivale a MatchAny.
+const (
+	// MatchAny pide que el proyecto tenga alguno de los valores
+	MatchAny ArrayMatch = "any"
+	// MatchAll pide que el proyecto tenga todos los valores
+	MatchAll ArrayMatch = "all"
+	// MatchOnly pide que el proyecto no tenga nada fuera de los valores ("uso solo estas herramientas")
+	MatchOnly ArrayMatch = "only"
+)
+
+// Range es un rango cerrado [Min, Max]; cualquiera de los extremos puede faltar
+type Range[T any] struct {
+	Min *T
+	Max *T
+}
+
+// IsSet indica si el rango tiene algun extremo
+func (r Range[T]) IsSet() bool {
+	return r.Min != nil || r.Max != nil
+}
+
+// Box son las medidas de una caja en cm
+type Box struct {
+	Height, Width, Length float64
+}
+
+// BayesianPrior es el prior contra el que se pondera el promedio de cada proyecto
+type BayesianPrior struct {
+	Mean   float64
+	Weight float64
+}
+
+// sortedSides devuelve las medidas de la caja de menor a mayor
+func sortedSides(b Box) [3]float64 {
+	sides := [3]float64{b.Height, b.Width, b.Length}
+	sort.Float64s(sides[:])
+	return sides
+}
+
+// Fits indica si una pieza de esas medidas entra en la caja, en cualquier orientacion
+func (b Box) Fits(height, width, length float64) bool {
+	box, piece := sortedSides(b), sortedSides(Box{height, width, length})
+	return piece[0] <= box[0] && piece[1] <= box[1] && piece[2] <= box[2]
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import "testing"
+
+func TestBoxFits(t *testing.T) {
+	box := Box{Height: 60, Width: 40, Length: 90}
+	tests := []struct {
+		name                  string
+		height, width, length float64
+		want                  bool
+	}{
+		{"same orientation", 60, 40, 90, true},
+		{"lying down", 40, 90, 60, true},
+		{"standing on its end", 90, 60, 40, true},
+		{"smaller on every side", 10, 10, 10, true},
+		{"long side fits only along the length", 85, 20, 30, true},
+		{"too long in any orientation", 95, 10, 10, false},
+		{"two sides longer than the middle side of the box", 70, 70, 10, false},
+		{"middle side too big once the long one is placed", 89, 61, 10, false},
+		{"no sizes", 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		if got := box.Fits(tt.height, tt.width, tt.length); got != tt.want {
+			t.Errorf("%s: Fits(%g, %g, %g) = %v, want %v", tt.name, tt.height, tt.width, tt.length, got, tt.want)
+		}
+	}
+}
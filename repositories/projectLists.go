@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"gorm.io/gorm"
+)
+
+type projectListRepository struct {
+	db *gorm.DB
+}
+
+func (r *projectListRepository) FindByID(ctx context.Context, id int64) (*models.ProjectList, error) {
+	var list models.ProjectList
+	if err := r.db.WithContext(ctx).First(&list, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &list, nil
+}
+
+// projectListSortColumns son las claves de orden del listado de listas de un usuario
+var projectListSortColumns = sortColumns{
+	"created_at": column("project_lists.created_at"),
+	"name":       column("project_lists.name"),
+}
+
+// ListByUser devuelve las listas del usuario con la cantidad de proyectos de cada una
+func (r *projectListRepository) ListByUser(
+	ctx context.Context, userID int64, page pagination.Request,
+) ([]models.ProjectList, *int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.ProjectList{}).Where("project_lists.user_id = ?", userID)
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err = paginate(query.
+		Select("project_lists.*, COUNT(project_list_items.project_id) as project_count").
+		Joins("LEFT JOIN project_list_items ON project_list_items.project_list_id = project_lists.id").
+		Group("project_lists.id"), page, projectListSortColumns, "project_lists.id")
+	if err != nil {
+		return nil, nil, err
+	}
+	var lists []models.ProjectList
+	if err := query.Find(&lists).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	return lists, total, nil
+}
+
+func (r *projectListRepository) Create(ctx context.Context, list *models.ProjectList) error {
+	return translate(r.db.WithContext(ctx).Create(list).Error)
+}
+
+func (r *projectListRepository) Update(ctx context.Context, list *models.ProjectList) error {
+	return translate(r.db.WithContext(ctx).Save(list).Error)
+}
+
+func (r *projectListRepository) Delete(ctx context.Context, id int64) error {
+	return deleteByID(r.db.WithContext(ctx), &models.ProjectList{}, id)
+}
+
+func (r *projectListRepository) AddItem(ctx context.Context, item *models.ProjectListItem) error {
+	return translate(r.db.WithContext(ctx).Create(item).Error)
+}
+
+func (r *projectListRepository) FindItem(ctx context.Context, listID, projectID int64) (*models.ProjectListItem, error) {
+	var item models.ProjectListItem
+	err := r.db.WithContext(ctx).Where("project_list_id = ? AND project_id = ?", listID, projectID).First(&item).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &item, nil
+}
+
+func (r *projectListRepository) DeleteItem(ctx context.Context, id int64) error {
+	return deleteByID(r.db.WithContext(ctx), &models.ProjectListItem{}, id)
+}
+
+func (r *projectListRepository) ListItems(ctx context.Context, listID int64) ([]models.ProjectListItem, error) {
+	var items []models.ProjectListItem
+	err := r.db.WithContext(ctx).Where("project_list_id = ?", listID).Find(&items).Error
+	return items, translate(err)
+}
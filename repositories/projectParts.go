@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"gorm.io/gorm"
+)
+
+type projectPartRepository struct {
+	db *gorm.DB
+}
+
+func (r *projectPartRepository) ListByProject(ctx context.Context, projectID int64) ([]models.ProjectPart, error) {
+	return listParts(r.db.WithContext(ctx), projectID)
+}
+
+// ListByProjects devuelve las piezas de varios proyectos, ordenadas por proyecto y posicion
+func (r *projectPartRepository) ListByProjects(ctx context.Context, projectIDs []int64) ([]models.ProjectPart, error) {
+	var parts []models.ProjectPart
+	if len(projectIDs) == 0 {
+		return parts, nil
+	}
+	err := r.db.WithContext(ctx).Where("project_id IN ?", projectIDs).
+		Order("project_id, position, id").Find(&parts).Error
+	return parts, translate(err)
+}
+
+func (r *projectPartRepository) Replace(
+	ctx context.Context, projectID int64, parts []models.ProjectPart, revision Revision,
+) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, projectID); errors.Is(err, ErrNotFound) {
+			return ErrMissingReference
+		} else if err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&models.ProjectPart{}).Error; err != nil {
+			return err
+		}
+		for i := range parts {
+			parts[i].ID = 0
+			parts[i].ProjectID = projectID
+			parts[i].Position = i
+		}
+		if len(parts) > 0 {
+			if err := tx.Create(&parts).Error; err != nil {
+				return err
+			}
+		}
+		return saveRevision(tx, projectID, revision)
+	}))
+}
+
+func listParts(db *gorm.DB, projectID int64) ([]models.ProjectPart, error) {
+	var parts []models.ProjectPart
+	err := db.Where("project_id = ?", projectID).Order("position, id").Find(&parts).Error
+	return parts, translate(err)
+}
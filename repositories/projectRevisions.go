@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type projectRevisionRepository struct {
+	db *gorm.DB
+}
+
+// revisionSortColumns son las claves de orden del historial de un proyecto
+var revisionSortColumns = sortColumns{
+	"number": column("number"),
+}
+
+func (r *projectRevisionRepository) FindByNumber(
+	ctx context.Context, projectID int64, number int,
+) (*models.ProjectRevision, error) {
+	var revision models.ProjectRevision
+	err := r.db.WithContext(ctx).Where("project_id = ? AND number = ?", projectID, number).First(&revision).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &revision, nil
+}
+
+func (r *projectRevisionRepository) ListByProject(
+	ctx context.Context, projectID int64, page pagination.Request,
+) ([]models.ProjectRevision, *int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.ProjectRevision{}).Where("project_id = ?", projectID)
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err = paginate(query, page, revisionSortColumns, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	var revisions []models.ProjectRevision
+	if err := query.Omit("snapshot").Find(&revisions).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	return revisions, total, nil
+}
+
+func (r *projectRevisionRepository) Restore(
+	ctx context.Context, project *models.Project, steps []models.ProjectStep, parts []models.ProjectPart,
+	revision Revision,
+) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, project.ID); err != nil {
+			return err
+		}
+		if err := tx.Omit(ratingColumns...).Save(project).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectStep{}).Error; err != nil {
+			return err
+		}
+		for i := range steps {
+			steps[i].ID = 0
+			steps[i].ProjectID = project.ID
+			steps[i].Position = i
+		}
+		if len(steps) > 0 {
+			if err := tx.Create(&steps).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectPart{}).Error; err != nil {
+			return err
+		}
+		for i := range parts {
+			parts[i].ID = 0
+			parts[i].ProjectID = project.ID
+			parts[i].Position = i
+		}
+		if len(parts) > 0 {
+			if err := tx.Create(&parts).Error; err != nil {
+				return err
+			}
+		}
+		if err := syncTutorial(tx, project.ID); err != nil {
+			return err
+		}
+		return saveRevision(tx, project.ID, revision)
+	}))
+}
+
+// lockProject bloquea el proyecto hasta el final de la transaccion. Las escrituras que guardan
+// revisiones lo toman antes de cambiar nada, asi dos cambios a la vez se numeran en el orden en que
+// se guardaron y cada uno se compara con la revision del anterior.
+func lockProject(tx *gorm.DB, projectID int64) error {
+	var project models.Project
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&project, projectID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// saveRevision guarda como revision el estado del proyecto dentro de tx, si cambio algo desde la
+// ultima, y borra las que quedan fuera de la retencion. El proyecto ya tiene que estar bloqueado.
+func saveRevision(tx *gorm.DB, projectID int64, revision Revision) error {
+	var project models.Project
+	if err := tx.First(&project, projectID).Error; err != nil {
+		return err
+	}
+	steps, err := listSteps(tx, projectID)
+	if err != nil {
+		return err
+	}
+	parts, err := listParts(tx, projectID)
+	if err != nil {
+		return err
+	}
+	snapshot := models.NewProjectSnapshot(&project, steps, parts)
+	saved := models.ProjectRevision{
+		ProjectID:    projectID,
+		Number:       1,
+		AuthorID:     revision.AuthorID,
+		RestoredFrom: revision.RestoredFrom,
+		Changed:      []string{},
+		Snapshot:     &snapshot,
+	}
+
+	var latest models.ProjectRevision
+	err = tx.Where("project_id = ?", projectID).Order("number DESC").First(&latest).Error
+	switch {
+	case err == nil:
+		saved.Changed = models.ChangedFields(models.DiffSnapshots(latest.Snapshot, &snapshot))
+		if len(saved.Changed) == 0 {
+			return nil
+		}
+		saved.Number = latest.Number + 1
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+	if err := tx.Create(&saved).Error; err != nil {
+		return err
+	}
+
+	retention := revision.Retention
+	old := tx.Where("project_id = ? AND number < ?", projectID, saved.Number)
+	switch {
+	case retention.Keep > 0 && retention.MaxAge > 0:
+		old = old.Where("(number <= ? OR created_at < ?)",
+			saved.Number-retention.Keep, time.Now().Add(-retention.MaxAge))
+	case retention.Keep > 0:
+		old = old.Where("number <= ?", saved.Number-retention.Keep)
+	case retention.MaxAge > 0:
+		old = old.Where("created_at < ?", time.Now().Add(-retention.MaxAge))
+	default:
+		return nil
+	}
+	return old.Delete(&models.ProjectRevision{}).Error
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// TestRevisionsFollowConcurrentWrites controla que los cambios a la vez en el proyecto y en sus
+// pasos dejen revisiones numeradas sin huecos, cada una comparada con la anterior, y que la ultima
+// sea el estado guardado
+func TestRevisionsFollowConcurrentWrites(t *testing.T) {
+	repos := NewGorm(testDB(t))
+	ctx := context.Background()
+	owner := testUser(t, repos, "owner")
+	project := testProject(t, repos, owner, "Banco")
+	revision := Revision{AuthorID: &owner.ID}
+
+	const writes = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writes)
+	for i := range writes {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			update := *project
+			update.Title = fmt.Sprintf("Banco %d", i)
+			errs <- repos.Projects.Update(ctx, &update, revision)
+		}()
+		go func() {
+			defer wg.Done()
+			step := models.ProjectStep{ProjectID: project.ID, Title: fmt.Sprintf("Paso %d", i), Position: -1,
+				Images: []string{}, Tools: []string{}, SafetyNotes: []string{}}
+			errs <- repos.Steps.Create(ctx, &step, revision)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var previous *models.ProjectSnapshot
+	number := 1
+	for ; ; number++ {
+		saved, err := repos.Revisions.FindByNumber(ctx, project.ID, number)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if previous != nil {
+			want := models.ChangedFields(models.DiffSnapshots(previous, saved.Snapshot))
+			if len(want) == 0 || !slices.Equal([]string(saved.Changed), want) {
+				t.Errorf("revision %d changed %q, want %q", number, saved.Changed, want)
+			}
+		}
+		previous = saved.Snapshot
+	}
+	// la revision 1 es la de testProject; cada escritura cambia algo, asi que cada una agrega otra
+	if number-1 != 1+2*writes {
+		t.Fatalf("%d revisions, want %d", number-1, 1+2*writes)
+	}
+
+	current, err := repos.Projects.FindByID(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps, err := repos.Steps.ListByProject(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.NewProjectSnapshot(current, steps, nil)
+	if changes := models.DiffSnapshots(previous, &want); len(changes) > 0 {
+		t.Errorf("latest revision differs from the saved state: %+v", changes)
+	}
+}
+
+// TestRevisionsNeedTheProject controla que una escritura sobre un proyecto que no existe falle sin
+// guardar nada
+func TestRevisionsNeedTheProject(t *testing.T) {
+	repos := NewGorm(testDB(t))
+	ctx := context.Background()
+	owner := testUser(t, repos, "owner")
+	project := testProject(t, repos, owner, "Borrado")
+	if err := repos.Projects.Delete(ctx, project.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repos.Projects.Update(ctx, project, Revision{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update err = %v, want ErrNotFound", err)
+	}
+	step := models.ProjectStep{ProjectID: project.ID, Title: "Paso"}
+	if err := repos.Steps.Create(ctx, &step, Revision{}); !errors.Is(err, ErrMissingReference) {
+		t.Errorf("Steps.Create err = %v, want ErrMissingReference", err)
+	}
+	if err := repos.Parts.Replace(ctx, project.ID, nil, Revision{}); !errors.Is(err, ErrMissingReference) {
+		t.Errorf("Parts.Replace err = %v, want ErrMissingReference", err)
+	}
+	if _, err := repos.Projects.FindByID(ctx, project.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByID err = %v, want the project to stay deleted", err)
+	}
+}
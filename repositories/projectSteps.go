@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"gorm.io/gorm"
+)
+
+type projectStepRepository struct {
+	db *gorm.DB
+}
+
+func (r *projectStepRepository) FindByID(ctx context.Context, id int64) (*models.ProjectStep, error) {
+	var step models.ProjectStep
+	if err := r.db.WithContext(ctx).First(&step, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &step, nil
+}
+
+func (r *projectStepRepository) ListByProject(ctx context.Context, projectID int64) ([]models.ProjectStep, error) {
+	return listSteps(r.db.WithContext(ctx), projectID)
+}
+
+func (r *projectStepRepository) Create(ctx context.Context, step *models.ProjectStep, revision Revision) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// sin el proyecto el paso no tiene a donde ir
+		if err := lockProject(tx, step.ProjectID); errors.Is(err, ErrNotFound) {
+			return ErrMissingReference
+		} else if err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.ProjectStep{}).Where("project_id = ?", step.ProjectID).Count(&count).Error; err != nil {
+			return err
+		}
+		if step.Position < 0 || int64(step.Position) > count {
+			step.Position = int(count)
+		}
+		if err := tx.Model(&models.ProjectStep{}).
+			Where("project_id = ? AND position >= ?", step.ProjectID, step.Position).
+			UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(step).Error; err != nil {
+			return err
+		}
+		return syncSteps(tx, step.ProjectID, revision)
+	}))
+}
+
+func (r *projectStepRepository) Update(ctx context.Context, step *models.ProjectStep, revision Revision) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, step.ProjectID); err != nil {
+			return err
+		}
+		result := tx.Model(&models.ProjectStep{ID: step.ID}).Where("project_id = ?", step.ProjectID).
+			Select("title", "body", "body_html", "images", "estimated_minutes", "tools", "safety_notes", "updated_at").
+			Updates(step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return syncSteps(tx, step.ProjectID, revision)
+	}))
+}
+
+func (r *projectStepRepository) Delete(ctx context.Context, step *models.ProjectStep, revision Revision) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, step.ProjectID); err != nil {
+			return err
+		}
+		if err := deleteByID(tx, &models.ProjectStep{}, step.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ProjectStep{}).
+			Where("project_id = ? AND position > ?", step.ProjectID, step.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+		return syncSteps(tx, step.ProjectID, revision)
+	}))
+}
+
+func (r *projectStepRepository) Reorder(ctx context.Context, projectID int64, ids []int64, revision Revision) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, projectID); err != nil {
+			return err
+		}
+		for position, id := range ids {
+			result := tx.Model(&models.ProjectStep{}).Where("id = ? AND project_id = ?", id, projectID).
+				UpdateColumn("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrNotFound
+			}
+		}
+		return syncSteps(tx, projectID, revision)
+	}))
+}
+
+func (r *projectStepRepository) UpdateRendered(ctx context.Context, projectID int64, steps []models.ProjectStep) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, step := range steps {
+			if err := tx.Model(&models.ProjectStep{}).Where("id = ? AND project_id = ?", step.ID, projectID).
+				UpdateColumn("body_html", step.BodyHTML).Error; err != nil {
+				return err
+			}
+		}
+		return syncTutorial(tx, projectID)
+	}))
+}
+
+func listSteps(db *gorm.DB, projectID int64) ([]models.ProjectStep, error) {
+	var steps []models.ProjectStep
+	err := db.Where("project_id = ?", projectID).Order("position, id").Find(&steps).Error
+	return steps, translate(err)
+}
+
+// syncSteps vuelve a armar el tutorial despues de un cambio en los pasos y guarda la revision
+func syncSteps(tx *gorm.DB, projectID int64, revision Revision) error {
+	if err := syncTutorial(tx, projectID); err != nil {
+		return err
+	}
+	return saveRevision(tx, projectID, revision)
+}
+
+// syncTutorial vuelve a armar el tutorial y el tiempo de construccion del proyecto a partir de sus
+// pasos, sin tocar updated_at
+func syncTutorial(tx *gorm.DB, projectID int64) error {
+	steps, err := listSteps(tx, projectID)
+	if err != nil {
+		return err
+	}
+	columns := map[string]any{"tutorial": models.TutorialText(steps), "tutorial_html": models.TutorialHTML(steps)}
+	if minutes, ok := models.StepMinutes(steps); ok {
+		columns["time_to_build"] = minutes
+	}
+	return tx.Model(&models.Project{ID: projectID}).UpdateColumns(columns).Error
+}
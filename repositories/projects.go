@@ -0,0 +1,426 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+func (r *projectRepository) FindByID(ctx context.Context, id int64) (*models.Project, error) {
+	var project models.Project
+	if err := r.db.WithContext(ctx).First(&project, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) FindByIDs(ctx context.Context, ids []int64) ([]models.Project, error) {
+	var projects []models.Project
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&projects).Error
+	return projects, translate(err)
+}
+
+// projectSortColumns son las claves de orden comunes a todos los listados de proyectos
+var projectSortColumns = sortColumns{
+	"created_at":     column("projects.created_at"),
+	"average_rating": column("projects.average_rating"),
+	"rating_count":   column("projects.rating_count"),
+	"time_to_build":  column("projects.time_to_build"),
+}
+
+func (r *projectRepository) ListByOwner(
+	ctx context.Context, ownerID int64, page pagination.Request,
+) ([]models.Project, *int64, error) {
+	return r.list(r.db.WithContext(ctx).Model(&models.Project{}).Where("owner = ?", ownerID), page)
+}
+
+func (r *projectRepository) ListByList(
+	ctx context.Context, listID int64, page pagination.Request,
+) ([]models.Project, *int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Project{}).
+		Joins("JOIN project_list_items ON project_list_items.project_id = projects.id").
+		Where("project_list_items.project_list_id = ?", listID)
+	return r.list(query, page)
+}
+
+// list pagina un query de proyectos ordenado por alguna de projectSortColumns
+func (r *projectRepository) list(query *gorm.DB, page pagination.Request) ([]models.Project, *int64, error) {
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err = paginate(query.Select("projects.*"), page, projectSortColumns, "projects.id")
+	if err != nil {
+		return nil, nil, err
+	}
+	var projects []models.Project
+	if err := query.Find(&projects).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	return projects, total, nil
+}
+
+// Marcadores que ts_headline pone alrededor de cada termino encontrado. Son caracteres de control
+// para poder escapar el fragmento como HTML antes de convertirlos en <mark>.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+var (
+	titleHeadlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", HighlightAll=true"
+	bodyHeadlineOptions  = "StartSel=" + headlineStart + ", StopSel=" + headlineStop +
+		", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+	headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+)
+
+// headline arma el ts_headline de column con la configuracion cuya consulta encuentra el texto. El
+// documento indexa en las dos, pero ts_headline solo marca los lexemas de la configuracion que
+// recibe: con woodys_es fijo, "painting" encuentra "painted" y no lo resalta. Espera las opciones
+// dos veces.
+func headline(column string) string {
+	return "CASE WHEN to_tsvector('woodys_es', coalesce(" + column + ", '')) @@ search.es " +
+		"THEN ts_headline('woodys_es', " + column + ", search.es, ?) " +
+		"ELSE ts_headline('woodys_en', " + column + ", search.en, ?) END"
+}
+
+// renderHeadline escapa un fragmento de ts_headline y reemplaza los marcadores por <mark>
+func renderHeadline(raw string) string {
+	return headlineMarks.Replace(html.EscapeString(raw))
+}
+
+// projectSearchRow es un proyecto junto con las columnas calculadas por la busqueda de texto
+type projectSearchRow struct {
+	models.Project      `gorm:"embedded"`
+	SearchRank          float32
+	TitleHeadline       string
+	DescriptionHeadline string
+}
+
+// applyFilter agrega al query las condiciones del filtro. Si hay busqueda de texto agrega el join
+// "search" con la consulta ya parseada.
+func applyFilter(query *gorm.DB, filter ProjectFilter) *gorm.DB {
+	query = whereArray(query, "projects.style", filter.Style, filter.StyleMatch)
+	query = whereArray(query, "projects.materials", filter.Materials, filter.MaterialsMatch)
+	query = whereArray(query, "projects.tools", filter.Tools, filter.ToolsMatch)
+	if len(filter.MainMaterial) > 0 {
+		query = query.Where("projects.main_material IN ?", filter.MainMaterial)
+	}
+	// environment es un texto, no un array
+	if len(filter.Environment) > 0 {
+		query = query.Where("projects.environment IN ?", filter.Environment)
+	}
+	if len(filter.Owners) > 0 {
+		query = query.Where("projects.owner IN ?", filter.Owners)
+	}
+	if filter.Title != "" {
+		query = query.Where("projects.title ILIKE ?", "%"+filter.Title+"%")
+	}
+
+	query = whereRange(query, "projects.height", filter.Height)
+	query = whereRange(query, "projects.width", filter.Width)
+	query = whereRange(query, "projects.length", filter.Length)
+	query = whereRange(query, "projects.time_to_build", filter.TimeToBuild)
+	query = whereRange(query, "projects.average_rating", filter.AverageRating)
+	query = whereRange(query, "projects.rating_count", filter.RatingCount)
+	query = whereRange(query, "projects.created_at", filter.CreatedAt)
+
+	// se comparan las medidas ordenadas de menor a mayor, asi el proyecto puede rotarse; la del medio
+	// es el menor de los maximos de cada par
+	if box := filter.FitsIn; box != nil {
+		sides := sortedSides(*box)
+		query = query.Where(
+			"LEAST(projects.height, projects.width, projects.length) <= ? AND "+
+				"LEAST(GREATEST(projects.height, projects.width), GREATEST(projects.width, projects.length), "+
+				"GREATEST(projects.height, projects.length)) <= ? AND "+
+				"GREATEST(projects.height, projects.width, projects.length) <= ?",
+			sides[0], sides[1], sides[2])
+	}
+
+	// la consulta se interpreta con ambas configuraciones, igual que el documento; cada una queda
+	// aparte para los headlines
+	if filter.Query != "" {
+		query = query.
+			Joins("CROSS JOIN (SELECT es, en, es || en AS q FROM (SELECT websearch_to_tsquery('woodys_es', ?) AS es, "+
+				"websearch_to_tsquery('woodys_en', ?) AS en) AS queries) AS search",
+				filter.Query, filter.Query).
+			Where("projects.search_vector @@ search.q")
+	}
+	return query
+}
+
+// whereArray filtra un array del proyecto segun el modo de comparacion
+func whereArray(query *gorm.DB, column string, values []string, match ArrayMatch) *gorm.DB {
+	if len(values) == 0 {
+		return query
+	}
+	operator := "&&"
+	switch match {
+	case MatchAll:
+		operator = "@>"
+	case MatchOnly:
+		operator = "<@"
+	}
+	return query.Where(column+" "+operator+" ?::varchar[]", pq.StringArray(values))
+}
+
+// whereRange filtra una columna por un rango cerrado
+func whereRange[T any](query *gorm.DB, column string, r Range[T]) *gorm.DB {
+	if r.Min != nil {
+		query = query.Where(column+" >= ?", *r.Min)
+	}
+	if r.Max != nil {
+		query = query.Where(column+" <= ?", *r.Max)
+	}
+	return query
+}
+
+// publicProjects es la base de la busqueda y de sus facetas: los proyectos publicos que cumplen el
+// filtro. Los privados no aparecen nunca, haya filtros o no.
+func publicProjects(db *gorm.DB, filter ProjectFilter) *gorm.DB {
+	return applyFilter(db.Model(&models.Project{}).Where("projects.is_public = TRUE"), filter)
+}
+
+func (r *projectRepository) Search(
+	ctx context.Context, filter ProjectFilter, page pagination.Request,
+) ([]models.Project, *int64, error) {
+	query := publicProjects(r.db.WithContext(ctx), filter)
+
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := sortColumns{}
+	for key, expr := range projectSortColumns {
+		columns[key] = expr
+	}
+	// mismo calculo, en el mismo orden, que RatingHistogram.BayesianScore: el cursor compara
+	// contra el valor calculado en Go y tiene que dar exactamente igual
+	prior := BayesianPrior{}
+	if filter.Bayesian != nil {
+		prior = *filter.Bayesian
+	}
+	columns["bayesian_rating"] = clause.Expr{
+		SQL: "COALESCE((? * ? + (projects.ratings_1 + 2 * projects.ratings_2 + 3 * projects.ratings_3 + " +
+			"4 * projects.ratings_4 + 5 * projects.ratings_5))::float8 / NULLIF(? + projects.ratings_1 + " +
+			"projects.ratings_2 + projects.ratings_3 + projects.ratings_4 + projects.ratings_5, 0), 0)",
+		Vars: []any{prior.Weight, prior.Mean, prior.Weight},
+	}
+
+	if filter.Query == "" {
+		if page.Order.Key == "relevance" {
+			return nil, nil, fmt.Errorf("sort by relevance requires a text query")
+		}
+		query, err = paginate(query.Select("projects.*"), page, columns, "projects.id")
+		if err != nil {
+			return nil, nil, err
+		}
+		var projects []models.Project
+		if err := query.Find(&projects).Error; err != nil {
+			return nil, nil, translate(err)
+		}
+		return projects, total, nil
+	}
+
+	columns["relevance"] = column("ts_rank(projects.search_vector, search.q)")
+	query = query.Select("projects.*, ts_rank(projects.search_vector, search.q) AS search_rank, "+
+		headline("projects.title")+" AS title_headline, "+
+		headline("projects.description")+" AS description_headline",
+		titleHeadlineOptions, titleHeadlineOptions, bodyHeadlineOptions, bodyHeadlineOptions)
+	query, err = paginate(query, page, columns, "projects.id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []projectSearchRow
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	projects := make([]models.Project, len(rows))
+	for i, row := range rows {
+		projects[i] = row.Project
+		projects[i].Search = &models.SearchMatch{
+			Rank:        row.SearchRank,
+			Title:       renderHeadline(row.TitleHeadline),
+			Description: renderHeadline(row.DescriptionHeadline),
+		}
+	}
+	return projects, total, nil
+}
+
+// ListAfterID recorre todos los proyectos, publicos o no, de a limit y por id
+func (r *projectRepository) ListAfterID(ctx context.Context, afterID int64, limit int) ([]models.Project, error) {
+	var projects []models.Project
+	err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Find(&projects).Error
+	return projects, translate(err)
+}
+
+// UpdateTaxonomy escribe solo las columnas que apuntan a la taxonomia, sin tocar updated_at
+func (r *projectRepository) UpdateTaxonomy(ctx context.Context, project *models.Project, revision Revision) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, project.ID); err != nil {
+			return err
+		}
+		err := tx.Model(&models.Project{ID: project.ID}).UpdateColumns(map[string]any{
+			"tools":         project.Tools,
+			"materials":     project.Materials,
+			"style":         project.Style,
+			"main_material": project.MainMaterial,
+			"environment":   project.Environment,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return saveRevision(tx, project.ID, revision)
+	}))
+}
+
+func (r *projectRepository) Create(
+	ctx context.Context, project *models.Project, steps []models.ProjectStep, revision Revision,
+) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// el proyecto nuevo no lo ve nadie hasta el commit, asi que no hace falta bloquearlo
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		for i := range steps {
+			steps[i].ID = 0
+			steps[i].ProjectID = project.ID
+			steps[i].Position = i
+		}
+		if len(steps) > 0 {
+			if err := tx.Create(&steps).Error; err != nil {
+				return err
+			}
+			if err := syncTutorial(tx, project.ID); err != nil {
+				return err
+			}
+		}
+		return saveRevision(tx, project.ID, revision)
+	}))
+}
+
+// ratingColumns los escribe solo el repositorio de ratings, bajo lock; Update no debe pisarlos
+var ratingColumns = []string{
+	"average_rating", "rating_count", "ratings_1", "ratings_2", "ratings_3", "ratings_4", "ratings_5",
+}
+
+func (r *projectRepository) UpdateRendered(ctx context.Context, project *models.Project) error {
+	result := r.db.WithContext(ctx).Model(&models.Project{ID: project.ID}).
+		UpdateColumn("description_html", project.DescriptionHTML)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *projectRepository) Update(ctx context.Context, project *models.Project, revision Revision) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProject(tx, project.ID); err != nil {
+			return err
+		}
+		if err := tx.Omit(ratingColumns...).Save(project).Error; err != nil {
+			return err
+		}
+		// project pudo leerse antes de un cambio en los pasos; el tutorial sale siempre de los pasos
+		if err := syncTutorial(tx, project.ID); err != nil {
+			return err
+		}
+		return saveRevision(tx, project.ID, revision)
+	}))
+}
+
+func (r *projectRepository) Delete(ctx context.Context, id int64) error {
+	return deleteByID(r.db.WithContext(ctx), &models.Project{}, id)
+}
+
+func (r *projectRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Project{}).Count(&count).Error
+	return count, translate(err)
+}
+
+func (r *projectRepository) Facets(ctx context.Context, filter ProjectFilter) (*Facets, error) {
+	db := r.db.WithContext(ctx)
+	facets := &Facets{}
+
+	for _, facet := range valueFacets {
+		// los arrays se despliegan con unnest; un valor repetido en el mismo proyecto cuenta una vez
+		source := "SELECT projects.%s AS v"
+		if facet.array {
+			source = "SELECT DISTINCT v FROM unnest(projects.%s) AS v"
+		}
+		query := publicProjects(db, facet.without(filter)).
+			Joins(fmt.Sprintf("CROSS JOIN LATERAL ("+source+") AS facet", facet.column)).
+			Where("facet.v <> ''").
+			Select("facet.v AS value, COUNT(*) AS count").
+			Group("facet.v").
+			Order("count DESC, value").
+			Limit(maxFacetValues)
+
+		var values []FacetValue
+		if err := query.Scan(&values).Error; err != nil {
+			return nil, translate(err)
+		}
+		if values == nil {
+			values = []FacetValue{}
+		}
+		*facet.target(facets) = values
+	}
+
+	// todos los buckets de las facetas numericas salen de una sola pasada con COUNT(*) FILTER
+	var selects []string
+	var vars []any
+	for _, facet := range rangeFacets {
+		for _, bucket := range facet.buckets {
+			condition := "TRUE"
+			if bucket.Min != nil {
+				condition += fmt.Sprintf(" AND projects.%s >= ?", facet.column)
+				vars = append(vars, *bucket.Min)
+			}
+			if bucket.Max != nil {
+				condition += fmt.Sprintf(" AND projects.%s < ?", facet.column)
+				vars = append(vars, *bucket.Max)
+			}
+			selects = append(selects, "COUNT(*) FILTER (WHERE "+condition+")")
+		}
+	}
+	row := publicProjects(db, filter).
+		Select(strings.Join(selects, ", "), vars...).
+		Row()
+
+	counts := make([]int64, len(selects))
+	targets := make([]any, len(counts))
+	for i := range counts {
+		targets[i] = &counts[i]
+	}
+	if err := row.Scan(targets...); err != nil {
+		return nil, translate(err)
+	}
+
+	i := 0
+	for _, facet := range rangeFacets {
+		out := append([]Bucket{}, facet.buckets...)
+		for j := range out {
+			out[j].Count = counts[i]
+			i++
+		}
+		*facet.target(facets) = out
+	}
+	return facets, nil
+}
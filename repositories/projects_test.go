@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+// TestSearchHidesPrivateProjects controla que la busqueda sin filtros y las facetas no incluyan
+// proyectos privados
+func TestSearchHidesPrivateProjects(t *testing.T) {
+	repos := NewGorm(testDB(t))
+	ctx := context.Background()
+	owner := testUser(t, repos, "owner")
+	public := testProject(t, repos, owner, "Mesa publica")
+	private := testProject(t, repos, owner, "Mesa privada")
+	private.IsPublic = false
+	private.Style = []string{"privado-" + owner.FirebaseUID}
+	if err := repos.Projects.Update(ctx, private, Revision{}); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := pagination.Spec{
+		Scope: "search", Keys: []pagination.SortKey{{Name: "created_at", Kind: pagination.Time, Desc: true}},
+		DefaultSort: "created_at", DefaultLimit: 100, MaxLimit: 100,
+	}
+	page, err := pagination.NewRandomCodec().Parse(nil, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := ProjectFilter{Bayesian: &BayesianPrior{}}
+	results, _, err := repos.Projects.Search(ctx, filter, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, project := range results {
+		if project.ID == private.ID {
+			t.Errorf("private project %d in results", private.ID)
+		}
+		found = found || project.ID == public.ID
+	}
+	if !found && len(results) < 100 {
+		t.Errorf("public project %d missing from results", public.ID)
+	}
+
+	facets, err := repos.Projects.Facets(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range facets.Style {
+		if value.Value == private.Style[0] {
+			t.Errorf("style facet counts the private project")
+		}
+	}
+}
+
+// TestSearchHeadlines controla que el headline resalte lo que encontro cada configuracion: una
+// consulta en ingles marca las palabras que solo coinciden por el stemming en ingles
+func TestSearchHeadlines(t *testing.T) {
+	repos := NewGorm(testDB(t))
+	ctx := context.Background()
+	owner := testUser(t, repos, "owner")
+	english := testProject(t, repos, owner, "Painted shelves")
+	english.Description = "Two painted pine shelves for the hallway."
+	spanish := testProject(t, repos, owner, "Mesas rústicas")
+	spanish.Description = "Una mesa de pino para el patio."
+	for _, project := range []*models.Project{english, spanish} {
+		if err := repos.Projects.Update(ctx, project, Revision{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := pagination.Spec{
+		Scope: "search", Keys: []pagination.SortKey{{Name: "created_at", Kind: pagination.Time, Desc: true}},
+		DefaultSort: "created_at", DefaultLimit: 10, MaxLimit: 10,
+	}
+	page, err := pagination.NewRandomCodec().Parse(nil, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query       string
+		project     int64
+		title       string
+		description string
+	}{
+		{"painting", english.ID, "<mark>Painted</mark> shelves", "Two <mark>painted</mark> pine shelves"},
+		{"mesa", spanish.ID, "<mark>Mesas</mark> rústicas", "Una <mark>mesa</mark> de pino"},
+	}
+	for _, tt := range tests {
+		filter := ProjectFilter{Query: tt.query, Owners: []int64{owner.ID}, Bayesian: &BayesianPrior{}}
+		results, _, err := repos.Projects.Search(ctx, filter, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].ID != tt.project || results[0].Search == nil {
+			t.Fatalf("%s: results = %+v, want project %d", tt.query, results, tt.project)
+		}
+		// el fragmento de la descripcion puede cortar antes del final, alcanza con la marca
+		got := results[0].Search
+		if got.Title != tt.title || !strings.Contains(got.Description, tt.description) ||
+			strings.Count(got.Description, "<mark>") != 1 {
+			t.Errorf("%s: headlines = %q and %q, want %q and %q", tt.query, got.Title, got.Description,
+				tt.title, tt.description)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ratingRepository struct {
+	db *gorm.DB
+}
+
+func (r *ratingRepository) FindByID(ctx context.Context, id int64) (*models.Rating, error) {
+	var rating models.Rating
+	if err := r.db.WithContext(ctx).First(&rating, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepository) FindByUserAndProject(ctx context.Context, userID, projectID int64) (*models.Rating, error) {
+	var rating models.Rating
+	err := r.db.WithContext(ctx).Where("user_id = ? AND project_id = ?", userID, projectID).First(&rating).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepository) ListByUserAndProjects(
+	ctx context.Context, userID int64, projectIDs []int64,
+) ([]models.Rating, error) {
+	var ratings []models.Rating
+	err := r.db.WithContext(ctx).Where("user_id = ? AND project_id IN ?", userID, projectIDs).Find(&ratings).Error
+	return ratings, translate(err)
+}
+
+// ratingSortColumns son las claves de orden del listado de valoraciones de un proyecto
+var ratingSortColumns = sortColumns{
+	"created_at": column("created_at"),
+	"value":      column("value"),
+}
+
+func (r *ratingRepository) ListByProject(
+	ctx context.Context, projectID int64, page pagination.Request,
+) ([]models.Rating, *int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Rating{}).Where("project_id = ?", projectID)
+	total, err := countTotal(query, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err = paginate(query, page, ratingSortColumns, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	var ratings []models.Rating
+	if err := query.Find(&ratings).Error; err != nil {
+		return nil, nil, translate(err)
+	}
+	return ratings, total, nil
+}
+
+func (r *ratingRepository) Create(ctx context.Context, rating *models.Rating) (*RatingAggregate, error) {
+	return r.writeAndAggregate(ctx, rating.ProjectID, func(tx *gorm.DB) (int8, int8, error) {
+		return 0, rating.Value, tx.Create(rating).Error
+	})
+}
+
+func (r *ratingRepository) Update(ctx context.Context, rating *models.Rating) (*RatingAggregate, error) {
+	return r.writeAndAggregate(ctx, rating.ProjectID, func(tx *gorm.DB) (int8, int8, error) {
+		previous, err := lockRating(tx, rating.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+		return previous, rating.Value, tx.Save(rating).Error
+	})
+}
+
+func (r *ratingRepository) Delete(ctx context.Context, rating *models.Rating) (*RatingAggregate, error) {
+	return r.writeAndAggregate(ctx, rating.ProjectID, func(tx *gorm.DB) (int8, int8, error) {
+		previous, err := lockRating(tx, rating.ID)
+		if err != nil {
+			return 0, 0, err
+		}
+		return previous, 0, tx.Delete(&models.Rating{}, rating.ID).Error
+	})
+}
+
+// lockRating bloquea el rating y devuelve su valor actual dentro de la transaccion
+func lockRating(tx *gorm.DB, id int64) (int8, error) {
+	var current models.Rating
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "value").First(&current, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrNotFound
+	}
+	return current.Value, err
+}
+
+// writeAndAggregate bloquea la fila del proyecto, aplica la escritura y actualiza el histograma de forma
+// incremental con el valor anterior y el nuevo (0 si no hay). El lock serializa a los ratings concurrentes
+// del mismo proyecto, y average_rating y rating_count se derivan del histograma en la misma transaccion.
+func (r *ratingRepository) writeAndAggregate(
+	ctx context.Context, projectID int64, write func(tx *gorm.DB) (previous, current int8, err error),
+) (*RatingAggregate, error) {
+	var aggregate RatingAggregate
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "ratings_1", "ratings_2", "ratings_3", "ratings_4", "ratings_5").
+			First(&project, projectID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMissingReference
+		}
+		if err != nil {
+			return err
+		}
+
+		previous, current, err := write(tx)
+		if err != nil {
+			return err
+		}
+
+		histogram := project.Histogram
+		histogram.Add(previous, -1)
+		histogram.Add(current, 1)
+		aggregate = AggregateOf(histogram)
+
+		return tx.Model(&models.Project{}).Where("id = ?", projectID).UpdateColumns(map[string]any{
+			"ratings_1":      histogram.One,
+			"ratings_2":      histogram.Two,
+			"ratings_3":      histogram.Three,
+			"ratings_4":      histogram.Four,
+			"ratings_5":      histogram.Five,
+			"rating_count":   aggregate.RatingCount,
+			"average_rating": aggregate.AverageRating,
+		}).Error
+	})
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &aggregate, nil
+}
+
+func (r *ratingRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Rating{}).Count(&count).Error
+	return count, translate(err)
+}
+
+func (r *ratingRepository) Average(ctx context.Context) (float64, error) {
+	var average float64
+	err := r.db.WithContext(ctx).Model(&models.Rating{}).Select("COALESCE(AVG(value), 0)").Row().Scan(&average)
+	return average, translate(err)
+}
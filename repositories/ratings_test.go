@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// TestConcurrentRatingsKeepAggregates escribe valoraciones del mismo proyecto desde varias conexiones a
+// la vez y controla que el histograma guardado coincida con contar las filas de ratings
+func TestConcurrentRatingsKeepAggregates(t *testing.T) {
+	const raters, rounds = 12, 10
+	db := testDB(t)
+	repos := NewGorm(db)
+	ctx := context.Background()
+	project := testProject(t, repos, testUser(t, repos, "owner"), "Mesa")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, raters)
+	for i := range raters {
+		user := testUser(t, repos, "rater"+strconv.Itoa(i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			random := rand.New(rand.NewPCG(uint64(i), 0))
+			var rating *models.Rating
+			for range rounds {
+				value := int8(random.IntN(5) + 1)
+				var err error
+				switch {
+				case rating == nil:
+					rating = &models.Rating{UserID: user.ID, ProjectID: project.ID, Value: value}
+					_, err = repos.Ratings.Create(ctx, rating)
+				case random.IntN(3) == 0:
+					_, err = repos.Ratings.Delete(ctx, rating)
+					rating = nil
+				default:
+					rating.Value = value
+					_, err = repos.Ratings.Update(ctx, rating)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	var rows []struct {
+		Value int8
+		Count int64
+	}
+	err := db.Model(&models.Rating{}).Select("value, COUNT(*) AS count").
+		Where("project_id = ?", project.ID).Group("value").Scan(&rows).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want models.RatingHistogram
+	for _, row := range rows {
+		want.Add(row.Value, row.Count)
+	}
+
+	got, err := repos.Projects.FindByID(ctx, project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Histogram != want {
+		t.Errorf("histogram = %+v, want %+v", got.Histogram, want)
+	}
+	if aggregate := AggregateOf(want); got.RatingCount != aggregate.RatingCount ||
+		got.AverageRating != aggregate.AverageRating {
+		t.Errorf("aggregates = %d ratings avg %v, want %d avg %v",
+			got.RatingCount, got.AverageRating, aggregate.RatingCount, aggregate.AverageRating)
+	}
+}
@@ -0,0 +1,268 @@
+// Package repositories define el acceso a datos de la api detras de interfaces, con una
+// implementacion sobre gorm/Postgres y otra en memoria (subpaquete memory) para tests.
+//
+// Los listados reciben un pagination.Request, devuelven hasta page.Fetch() filas en el orden pedido
+// y, si page.WithTotal, la cantidad total de filas que cumplen el filtro (si no, nil).
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+var (
+	// ErrNotFound se devuelve cuando el registro buscado no existe
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict se devuelve ante la violacion de una restriccion unique
+	ErrConflict = errors.New("record already exists")
+	// ErrMissingReference se devuelve cuando una clave foranea apunta a un registro inexistente
+	ErrMissingReference = errors.New("referenced record not found")
+	// ErrQuotaExceeded se devuelve cuando un upload dejaria al usuario por encima de su cuota
+	ErrQuotaExceeded = errors.New("upload quota exceeded")
+)
+
+// RatingAggregate son los agregados de valoraciones que se guardan en cada proyecto
+type RatingAggregate struct {
+	AverageRating float32                `json:"average_rating"`
+	RatingCount   int                    `json:"rating_count"`
+	Histogram     models.RatingHistogram `json:"rating_histogram"`
+}
+
+// AggregateOf deriva el promedio y la cantidad de valoraciones a partir del histograma
+func AggregateOf(histogram models.RatingHistogram) RatingAggregate {
+	return RatingAggregate{
+		AverageRating: float32(histogram.Average()),
+		RatingCount:   int(histogram.Count()),
+		Histogram:     histogram,
+	}
+}
+
+// UserRepository administra los usuarios
+type UserRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.User, error)
+	FindByFirebaseUID(ctx context.Context, uid string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id int64) error
+	Count(ctx context.Context) (int64, error)
+}
+
+// ProjectRepository administra los proyectos
+type ProjectRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.Project, error)
+	FindByIDs(ctx context.Context, ids []int64) ([]models.Project, error)
+	ListByOwner(ctx context.Context, ownerID int64, page pagination.Request) ([]models.Project, *int64, error)
+	ListByList(ctx context.Context, listID int64, page pagination.Request) ([]models.Project, *int64, error)
+	Search(ctx context.Context, filter ProjectFilter, page pagination.Request) ([]models.Project, *int64, error)
+	Facets(ctx context.Context, filter ProjectFilter) (*Facets, error)
+	ListBuildable(ctx context.Context, filter BuildableFilter, page pagination.Request) ([]BuildableProject, *int64, error)
+	ListAfterID(ctx context.Context, afterID int64, limit int) ([]models.Project, error)
+	// UpdateTaxonomy escribe solo las herramientas, materiales, estilos y ambiente, sin tocar
+	// updated_at, y guarda la revision
+	UpdateTaxonomy(ctx context.Context, project *models.Project, revision Revision) error
+	// UpdateRendered escribe solo description_html, sin tocar updated_at
+	UpdateRendered(ctx context.Context, project *models.Project) error
+	// Create guarda el proyecto con sus primeros pasos, que pueden ser ninguno, y su revision 1
+	Create(ctx context.Context, project *models.Project, steps []models.ProjectStep, revision Revision) error
+	// Update guarda el proyecto; el tutorial y, con pasos estimados, time_to_build salen de los pasos
+	Update(ctx context.Context, project *models.Project, revision Revision) error
+	Delete(ctx context.Context, id int64) error
+	Count(ctx context.Context) (int64, error)
+}
+
+// CommentRepository administra los comentarios y sus respuestas
+type CommentRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.Comment, error)
+	ListByProject(ctx context.Context, projectID int64, page pagination.Request) ([]models.Comment, *int64, error)
+	ListReplies(ctx context.Context, parentID int64, page pagination.Request) ([]models.Comment, *int64, error)
+	// ListAfterID recorre todos los comentarios y respuestas de a limit y por id
+	ListAfterID(ctx context.Context, afterID int64, limit int) ([]models.Comment, error)
+	// UpdateRendered escribe solo content_html
+	UpdateRendered(ctx context.Context, comment *models.Comment) error
+	Create(ctx context.Context, comment *models.Comment) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// RatingRepository administra las valoraciones. Create, Update y Delete escriben el rating y actualizan
+// average_rating y rating_count del proyecto en una misma transaccion, devolviendo los nuevos agregados.
+type RatingRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.Rating, error)
+	FindByUserAndProject(ctx context.Context, userID, projectID int64) (*models.Rating, error)
+	ListByProject(ctx context.Context, projectID int64, page pagination.Request) ([]models.Rating, *int64, error)
+	ListByUserAndProjects(ctx context.Context, userID int64, projectIDs []int64) ([]models.Rating, error)
+	Create(ctx context.Context, rating *models.Rating) (*RatingAggregate, error)
+	Update(ctx context.Context, rating *models.Rating) (*RatingAggregate, error)
+	Delete(ctx context.Context, rating *models.Rating) (*RatingAggregate, error)
+	Count(ctx context.Context) (int64, error)
+	Average(ctx context.Context) (float64, error)
+}
+
+// ProjectListRepository administra las listas de proyectos y sus items
+type ProjectListRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.ProjectList, error)
+	ListByUser(ctx context.Context, userID int64, page pagination.Request) ([]models.ProjectList, *int64, error)
+	Create(ctx context.Context, list *models.ProjectList) error
+	Update(ctx context.Context, list *models.ProjectList) error
+	Delete(ctx context.Context, id int64) error
+	AddItem(ctx context.Context, item *models.ProjectListItem) error
+	FindItem(ctx context.Context, listID, projectID int64) (*models.ProjectListItem, error)
+	DeleteItem(ctx context.Context, id int64) error
+	ListItems(ctx context.Context, listID int64) ([]models.ProjectListItem, error)
+}
+
+// InventoryRepository administra las herramientas y materiales que tiene cada usuario
+type InventoryRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.InventoryItem, error)
+	ListByUser(ctx context.Context, userID int64) ([]models.InventoryItem, error)
+	CountByUser(ctx context.Context, userID int64) (int64, error)
+	Create(ctx context.Context, item *models.InventoryItem) error
+	Update(ctx context.Context, item *models.InventoryItem) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// ProjectPartRepository administra la lista de piezas de cada proyecto. Replace reemplaza la lista
+// entera en una transaccion, asi el orden y las piezas quedan como los mando el dueño, y guarda la
+// revision en la misma transaccion.
+type ProjectPartRepository interface {
+	ListByProject(ctx context.Context, projectID int64) ([]models.ProjectPart, error)
+	ListByProjects(ctx context.Context, projectIDs []int64) ([]models.ProjectPart, error)
+	Replace(ctx context.Context, projectID int64, parts []models.ProjectPart, revision Revision) error
+}
+
+// ProjectStepRepository administra los pasos del tutorial de cada proyecto. Las posiciones van de 0
+// a la cantidad de pasos menos uno. Cada escritura vuelve a armar, en la misma transaccion, el
+// tutorial del proyecto (models.TutorialText y models.TutorialHTML) y, si todos los pasos tienen
+// estimacion, su time_to_build (models.StepMinutes). Create, Update, Delete y Reorder guardan ademas
+// la revision.
+type ProjectStepRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.ProjectStep, error)
+	ListByProject(ctx context.Context, projectID int64) ([]models.ProjectStep, error)
+	// Create inserta el paso en step.Position, o al final si la pasa, y corre los siguientes
+	Create(ctx context.Context, step *models.ProjectStep, revision Revision) error
+	// Update cambia el contenido del paso; la posicion solo cambia con Reorder
+	Update(ctx context.Context, step *models.ProjectStep, revision Revision) error
+	Delete(ctx context.Context, step *models.ProjectStep, revision Revision) error
+	// Reorder deja los pasos del proyecto en el orden de ids, que tiene que nombrarlos a todos
+	Reorder(ctx context.Context, projectID int64, ids []int64, revision Revision) error
+	// UpdateRendered escribe solo el body_html de los pasos, sin tocar updated_at
+	UpdateRendered(ctx context.Context, projectID int64, steps []models.ProjectStep) error
+}
+
+// TaxonomyRepository administra los terminos canonicos de herramientas, materiales, estilos y ambientes
+type TaxonomyRepository interface {
+	List(ctx context.Context) ([]models.TaxonomyTerm, error)
+	Create(ctx context.Context, term *models.TaxonomyTerm) error
+	Update(ctx context.Context, term *models.TaxonomyTerm) error
+}
+
+// PriceFilter filtra el catalogo de precios; los campos vacios no filtran. Region nil no filtra y
+// "" es el precio general.
+type PriceFilter struct {
+	Material string
+	Unit     string
+	Currency string
+	Region   *string
+}
+
+// PriceRepository administra el catalogo de precios de materiales. ListEffective devuelve los
+// precios de los materiales en la moneda y las regiones pedidas con effective_date hasta la fecha;
+// elegir el vigente queda para quien llama. Import hace upsert de una lista de precios entera en una
+// transaccion, sobre material, unidad, moneda, region y fecha.
+type PriceRepository interface {
+	FindByID(ctx context.Context, id int64) (*models.MaterialPrice, error)
+	List(ctx context.Context, filter PriceFilter, page pagination.Request) ([]models.MaterialPrice, *int64, error)
+	ListEffective(
+		ctx context.Context, materials []string, currency string, regions []string, date time.Time,
+	) ([]models.MaterialPrice, error)
+	Create(ctx context.Context, price *models.MaterialPrice) error
+	Update(ctx context.Context, price *models.MaterialPrice) error
+	Delete(ctx context.Context, id int64) error
+	Import(ctx context.Context, prices []models.MaterialPrice) error
+}
+
+// UploadRepository administra las imagenes subidas. Create controla la cuota del usuario en la misma
+// transaccion que el insert, bloqueando al usuario, para que dos subidas a la vez no la pasen.
+type UploadRepository interface {
+	// FindByID y FindByIDs devuelven los uploads con sus variantes; FindByIDs omite los que no existen
+	FindByID(ctx context.Context, id string) (*models.Upload, error)
+	FindByIDs(ctx context.Context, ids []string) ([]models.Upload, error)
+	// Usage es la suma en bytes de los uploads del usuario
+	Usage(ctx context.Context, userID int64) (int64, error)
+	// Create guarda el upload si con el el usuario no pasa de quota bytes; si pasa, ErrQuotaExceeded
+	Create(ctx context.Context, upload *models.Upload, quota int64) error
+	Delete(ctx context.Context, id string) error
+	// InUse indica si el upload id, cuya url es url, es el avatar de un usuario o el portrait o una
+	// de las imagenes de algun proyecto o paso
+	InUse(ctx context.Context, id, url string) (bool, error)
+	// ListUnprocessed devuelve hasta limit uploads que todavia no pasaron por el worker, los mas viejos primero
+	ListUnprocessed(ctx context.Context, limit int) ([]models.Upload, error)
+	// SaveVariants reemplaza las variantes del upload, guarda el placeholder y lo marca procesado
+	SaveVariants(ctx context.Context, id string, variants []models.UploadVariant, blurhash, dominantColor string) error
+}
+
+// ProfilePictureRepository administra las fotos de perfil por defecto
+type ProfilePictureRepository interface {
+	// FindByID encuentra tambien las fotos retiradas
+	FindByID(ctx context.Context, id int64) (*models.ProfilePicture, error)
+	// List devuelve las fotos que no se retiraron, en orden
+	List(ctx context.Context) ([]models.ProfilePicture, error)
+	// Create agrega la foto al final
+	Create(ctx context.Context, picture *models.ProfilePicture) error
+	// Retire deja de ofrecer la foto; retirarla otra vez no cambia nada
+	Retire(ctx context.Context, id int64) error
+	// Reorder deja las fotos en el orden de ids, que tiene que nombrar a todas las que no se retiraron
+	Reorder(ctx context.Context, ids []int64) error
+}
+
+// RevisionRetention es cuantas revisiones se guardan de cada proyecto. Keep es la cantidad maxima y
+// MaxAge la antiguedad maxima; cero no limita. La ultima revision no se borra nunca.
+type RevisionRetention struct {
+	Keep   int
+	MaxAge time.Duration
+}
+
+// Revision es quien hace un cambio en el contenido de un proyecto. Las escrituras que la reciben
+// bloquean el proyecto y, en la misma transaccion que el cambio, guardan una revision con el estado
+// en que queda: numerada a continuacion de la ultima, salvo que nada haya cambiado desde esa. Despues
+// borran las que quedan fuera de Retention.
+type Revision struct {
+	AuthorID *int64
+	// RestoredFrom es el numero de la revision restaurada, si el cambio es una restauracion
+	RestoredFrom *int
+	Retention    RevisionRetention
+}
+
+// ProjectRevisionRepository administra el historial de cada proyecto. Las revisiones no se
+// modifican; solo las agregan las escrituras de proyectos, pasos y piezas que reciben una Revision.
+type ProjectRevisionRepository interface {
+	FindByNumber(ctx context.Context, projectID int64, number int) (*models.ProjectRevision, error)
+	// ListByProject devuelve las revisiones sin el snapshot
+	ListByProject(ctx context.Context, projectID int64, page pagination.Request) ([]models.ProjectRevision, *int64, error)
+	// Restore escribe project y reemplaza sus pasos y sus piezas en una transaccion, y vuelve a
+	// armar el tutorial como las escrituras de ProjectStepRepository
+	Restore(
+		ctx context.Context, project *models.Project, steps []models.ProjectStep, parts []models.ProjectPart,
+		revision Revision,
+	) error
+}
+
+// Repositories agrupa todos los repositorios que usan los handlers
+type Repositories struct {
+	Users           UserRepository
+	Projects        ProjectRepository
+	Comments        CommentRepository
+	Ratings         RatingRepository
+	ProjectLists    ProjectListRepository
+	ProfilePictures ProfilePictureRepository
+	Inventory       InventoryRepository
+	Taxonomy        TaxonomyRepository
+	Parts           ProjectPartRepository
+	Steps           ProjectStepRepository
+	Prices          PriceRepository
+	Uploads         UploadRepository
+	Revisions       ProjectRevisionRepository
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"gorm.io/gorm"
+)
+
+type taxonomyRepository struct {
+	db *gorm.DB
+}
+
+func (r *taxonomyRepository) List(ctx context.Context) ([]models.TaxonomyTerm, error) {
+	var terms []models.TaxonomyTerm
+	err := r.db.WithContext(ctx).Order("kind, id").Find(&terms).Error
+	return terms, translate(err)
+}
+
+func (r *taxonomyRepository) Create(ctx context.Context, term *models.TaxonomyTerm) error {
+	return translate(r.db.WithContext(ctx).Create(term).Error)
+}
+
+// Update no cambia kind ni slug: los proyectos guardan el slug
+func (r *taxonomyRepository) Update(ctx context.Context, term *models.TaxonomyTerm) error {
+	result := r.db.WithContext(ctx).Model(&models.TaxonomyTerm{ID: term.ID}).
+		Select("parent", "label_es", "label_en", "synonyms").
+		Updates(term)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
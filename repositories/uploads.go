@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type uploadRepository struct {
+	db *gorm.DB
+}
+
+func (r *uploadRepository) FindByID(ctx context.Context, id string) (*models.Upload, error) {
+	var upload models.Upload
+	if err := r.withVariants(ctx).Where("id = ?", id).First(&upload).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &upload, nil
+}
+
+func (r *uploadRepository) FindByIDs(ctx context.Context, ids []string) ([]models.Upload, error) {
+	var uploads []models.Upload
+	if len(ids) == 0 {
+		return uploads, nil
+	}
+	err := r.withVariants(ctx).Where("id IN ?", ids).Find(&uploads).Error
+	return uploads, translate(err)
+}
+
+// withVariants carga las variantes de menor a mayor
+func (r *uploadRepository) withVariants(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("width, name")
+	})
+}
+
+func (r *uploadRepository) Usage(ctx context.Context, userID int64) (int64, error) {
+	return usage(r.db.WithContext(ctx), userID)
+}
+
+func usage(db *gorm.DB, userID int64) (int64, error) {
+	var used int64
+	err := db.Model(&models.Upload{}).Where("user_id = ?", userID).Select("COALESCE(SUM(size), 0)").Scan(&used).Error
+	return used, translate(err)
+}
+
+func (r *uploadRepository) Create(ctx context.Context, upload *models.Upload, quota int64) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, upload.UserID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMissingReference
+		}
+		if err != nil {
+			return err
+		}
+		used, err := usage(tx, upload.UserID)
+		if err != nil {
+			return err
+		}
+		if used+upload.Size > quota {
+			return ErrQuotaExceeded
+		}
+		return tx.Create(upload).Error
+	}))
+}
+
+func (r *uploadRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Upload{})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *uploadRepository) InUse(ctx context.Context, id, url string) (bool, error) {
+	var used bool
+	err := r.db.WithContext(ctx).Raw(`SELECT
+		EXISTS (SELECT 1 FROM users WHERE avatar_upload = @id) OR
+		EXISTS (SELECT 1 FROM projects WHERE portrait = @url OR @url = ANY (images)) OR
+		EXISTS (SELECT 1 FROM project_steps WHERE @url = ANY (images))`,
+		map[string]any{"id": id, "url": url}).Scan(&used).Error
+	return used, translate(err)
+}
+
+func (r *uploadRepository) ListUnprocessed(ctx context.Context, limit int) ([]models.Upload, error) {
+	var uploads []models.Upload
+	err := r.db.WithContext(ctx).Where("processed_at IS NULL").Order("created_at, id").Limit(limit).Find(&uploads).Error
+	return uploads, translate(err)
+}
+
+func (r *uploadRepository) SaveVariants(
+	ctx context.Context, id string, variants []models.UploadVariant, blurhash, dominantColor string,
+) error {
+	return translate(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Upload{}).Where("id = ?", id).Updates(map[string]any{
+			"blurhash":       blurhash,
+			"dominant_color": dominantColor,
+			"processed_at":   time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if err := tx.Where("upload_id = ?", id).Delete(&models.UploadVariant{}).Error; err != nil {
+			return err
+		}
+		if len(variants) == 0 {
+			return nil
+		}
+		for i := range variants {
+			variants[i].UploadID = id
+		}
+		return tx.Create(&variants).Error
+	}))
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/carpentry-hub/woodys-backend/middlewares"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/policies"
+)
+
+// currentUser devuelve el usuario autenticado del request, o nil si es anonimo
+func currentUser(r *http.Request) *models.User {
+	user, _ := middlewares.UserFromContext(r.Context())
+	return user
+}
+
+// authorize responde 401 o 403 segun el error de la politica y devuelve false si el request debe cortarse
+func authorize(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	status, message := http.StatusForbidden, "You are not allowed to modify this resource"
+	if errors.Is(err, policies.ErrUnauthenticated) {
+		status, message = http.StatusUnauthorized, "Authentication required"
+	}
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/uploads"
+)
+
+// PutUserAvatar cambia la foto propia de un usuario - Requiere id y multipart/form-data con la
+// imagen en el campo "file". Se recorta al cuadrado del centro, se reduce y reemplaza a la anterior.
+func (h *Handler) PutUserAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.avatarUser(w, r)
+	if !ok {
+		return
+	}
+	if h.Storage == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Uploads are not available"})
+		return
+	}
+	data, ok := h.readUploadFile(w, r)
+	if !ok {
+		return
+	}
+	img, err := uploads.Avatar(data)
+	if errors.Is(err, uploads.ErrNoDecoder) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		json.NewEncoder(w).Encode(map[string]string{"message": "avatars must be JPEG, PNG or WebP images"})
+		return
+	}
+	if !writeImageError(w, err) {
+		return
+	}
+	upload, ok := h.storeUpload(w, r, user.ID, img)
+	if !ok {
+		return
+	}
+
+	previous := user.AvatarUpload
+	user.AvatarUpload = &upload.ID
+	if err := h.Users.Update(r.Context(), user); err != nil {
+		log.Printf("Error saving the avatar of user %d: %v", user.ID, err)
+		if err := h.removeUpload(r.Context(), upload); err != nil {
+			log.Printf("Error removing upload %s: %v", upload.ID, err)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the user"})
+		return
+	}
+	h.removeAvatar(r.Context(), previous)
+	h.writeUser(w, r, user)
+}
+
+// DeleteUserAvatar quita la foto propia de un usuario, que vuelve a ver la foto por defecto - Requiere id
+func (h *Handler) DeleteUserAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.avatarUser(w, r)
+	if !ok {
+		return
+	}
+	if previous := user.AvatarUpload; previous != nil {
+		user.AvatarUpload = nil
+		if err := h.Users.Update(r.Context(), user); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the user"})
+			return
+		}
+		h.removeAvatar(r.Context(), previous)
+	}
+	h.writeUser(w, r, user)
+}
+
+// avatarUser lee el usuario de la ruta y controla que quien pide pueda editarlo; responde 404 o 401/403
+func (h *Handler) avatarUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	id, err := pathID(r, "id")
+	var user *models.User
+	if err == nil {
+		user, err = h.Users.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "User not found"})
+		return nil, false
+	}
+	if !authorize(w, policies.ManageUser(currentUser(r), user)) {
+		return nil, false
+	}
+	return user, true
+}
+
+// removeAvatar borra el upload de un avatar reemplazado; los errores solo van al log
+func (h *Handler) removeAvatar(ctx context.Context, id *string) {
+	if id == nil {
+		return
+	}
+	upload, err := h.Uploads.FindByID(ctx, *id)
+	if err == nil {
+		err = h.removeUpload(ctx, upload)
+	}
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		log.Printf("Error removing avatar %s: %v", *id, err)
+	}
+}
+
+// resolveAvatar completa avatar_url: la foto propia, si no la foto por defecto elegida y si no la
+// primera de las fotos por defecto. Una foto elegida que despues se retiro se sigue mostrando.
+func (h *Handler) resolveAvatar(ctx context.Context, user *models.User) error {
+	user.AvatarURL = ""
+	if user.AvatarUpload != nil {
+		user.AvatarURL = h.uploadURL(*user.AvatarUpload)
+		return nil
+	}
+	if user.ProfilePicture != 0 {
+		picture, err := h.ProfilePictures.FindByID(ctx, user.ProfilePicture)
+		if err == nil {
+			user.AvatarURL = picture.Referenced
+			return nil
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+	}
+	pictures, err := h.ProfilePictures.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pictures) > 0 {
+		user.AvatarURL = pictures[0].Referenced
+	}
+	return nil
+}
+
+// writeUser responde el usuario con su avatar_url
+func (h *Handler) writeUser(w http.ResponseWriter, r *http.Request, user *models.User) {
+	if err := h.resolveAvatar(r.Context(), user); err != nil {
+		log.Printf("Error resolving the avatar of user %d: %v", user.ID, err)
+	}
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		log.Fatalf("Failed to encode: %v", err)
+	}
+}
@@ -3,24 +3,22 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"unicode/utf8"
 
-	"github.com/carpentry-hub/woodys-backend/db"
 	"github.com/carpentry-hub/woodys-backend/models"
-	"github.com/gorilla/mux"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
 )
 
 // GetProjectComments obtiene todos los comentarios de un proyecto - Requiere project_id
-func GetProjectComments(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	projectIDStr := params["id"]
-
-	// chequeo existencia del usuario
-	projectID, err := strconv.Atoi(projectIDStr) // cambio de str a int para evitar errores
+func (h *Handler) GetProjectComments(w http.ResponseWriter, r *http.Request) {
+	// chequeo existencia del proyecto
+	projectID, err := pathID(r, "id")
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		if _, err := w.Write([]byte("Project not found")); err != nil {
@@ -29,9 +27,14 @@ func GetProjectComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := h.parsePage(w, r, commentsPage)
+	if !ok {
+		return
+	}
+
 	// realizacion de la query y manejo de errores
-	var comments []models.Comment
-	if err := db.DB.Where("project_id = ?", projectID).Find(&comments).Error; err != nil {
+	comments, total, err := h.Comments.ListByProject(r.Context(), projectID, page)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := w.Write([]byte("Error fetching Comments")); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
@@ -39,98 +42,124 @@ func GetProjectComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&comments); err != nil {
-		log.Fatalf("Failed to encode json: %v", err)
-	}
+	writePage(w, pagination.NewPage(h.Cursors, page, comments, total, commentKey(page.Order)))
 }
 
-// PostProjectComment postea un comentario a un proyecto - Requiere project_id y parent_comment_id = 0
-func PostProjectComment(w http.ResponseWriter, r *http.Request) {
-	var comment models.Comment
-	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
-		log.Fatalf("Failed to decode json: %v", err)
-	}
-
-	// Quitar espacios en blanco para contar caracteres
-	trimmedContent := strings.TrimSpace(comment.Content)
-	contentLength := utf8.RuneCountInString(trimmedContent)
+// maxCommentLength es el largo maximo de un comentario o una respuesta, sin los espacios de los costados
+const maxCommentLength = 200
 
-	// Verificar que el comentario no este vacio
-	if contentLength == 0 {
-		w.WriteHeader(http.StatusBadRequest) // 400
-		json.NewEncoder(w).Encode(map[string]string{"message": "content cannot be empty"})
-		return
-	}
+// commentInput es lo que manda quien comenta; el proyecto y el comentario padre salen de la ruta
+type commentInput struct {
+	Content string `json:"content"`
+}
 
-	// Verificar que el comentario no supere los 200 caracteres
-	if contentLength > 200 {
-		w.WriteHeader(http.StatusBadRequest) // 400
-		json.NewEncoder(w).Encode(map[string]string{"message": "content cannot exceed 200 characters"})
+// PostProjectComment postea un comentario a un proyecto - Requiere id del proyecto y {"content": ...}
+func (h *Handler) PostProjectComment(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
 		return
 	}
-
-	createdComment := db.DB.Create(&comment)
-	err := createdComment.Error
-
+	projectID, err := pathID(r, "id")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest) // status code 400
-		if _, err := w.Write([]byte(err.Error())); err != nil {
-			log.Fatalf("Failed to write response: %v", err)
-		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&comment); err != nil {
-			log.Fatalf("Failed to encode json: %v", err)
-		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+		return
 	}
+	h.createComment(w, r, models.Comment{ProjectID: projectID, UserID: user.ID})
 }
 
 // DeleteComment borra un comentario de un proyecto - Requiere id
-func DeleteComment(w http.ResponseWriter, r *http.Request) {
-	var comment models.Comment
-	params := mux.Vars(r)
-	db.DB.First(&comment, params["id"])
-
-	if comment.ID == 0 {
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	var comment *models.Comment
+	if err == nil {
+		comment, err = h.Comments.FindByID(r.Context(), id)
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound) // status code 404
 		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Comment not found"}); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
 		}
-	} else {
-		db.DB.Unscoped().Delete(&comment)
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Comment deleted successfully"})
+		return
 	}
-}
 
-// PostCommentReply postea una respuesta a un comentario - Requiere project_id y parent_comment_id
-func PostCommentReply(w http.ResponseWriter, r *http.Request) {
-	var commentReply models.Comment
-	if err := json.NewDecoder(r.Body).Decode(&commentReply); err != nil {
-		log.Fatalf("Failed to decode json: %v", err)
+	if !authorize(w, policies.ManageComment(currentUser(r), comment)) {
+		return
 	}
 
-	createdComment := db.DB.Create(&commentReply)
-	err := createdComment.Error
+	if err := h.Comments.Delete(r.Context(), comment.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete the comment"})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Comment deleted successfully"})
+}
 
+// PostCommentReply postea una respuesta a un comentario - Requiere id del comentario y
+// {"content": ...}. La respuesta va al proyecto del comentario.
+func (h *Handler) PostCommentReply(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
+		return
+	}
+	id, err := pathID(r, "id")
+	var parent *models.Comment
+	if err == nil {
+		parent, err = h.Comments.FindByID(r.Context(), id)
+	}
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest) // status code 400
-		if _, err := w.Write([]byte(err.Error())); err != nil {
-			log.Fatalf("Failed to write response: %v", err)
-		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&commentReply); err != nil {
-			log.Fatalf("Failed to encode json: %v", err)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Comment not found"})
+		return
+	}
+	h.createComment(w, r, models.Comment{ProjectID: parent.ProjectID, ParentCommentID: parent.ID, UserID: user.ID})
+}
+
+// createComment lee el contenido del body, lo controla y guarda comment
+func (h *Handler) createComment(w http.ResponseWriter, r *http.Request, comment models.Comment) {
+	var input commentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	comment.Content = strings.TrimSpace(input.Content)
+
+	// Verificar que el comentario no este vacio ni supere los 200 caracteres
+	contentLength := utf8.RuneCountInString(comment.Content)
+	if contentLength == 0 {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		json.NewEncoder(w).Encode(map[string]string{"message": "content cannot be empty"})
+		return
+	}
+	if contentLength > maxCommentLength {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		json.NewEncoder(w).Encode(map[string]string{"message": "content cannot exceed 200 characters"})
+		return
+	}
+	comment.ContentHTML = h.Markdown.Render(comment.Content)
+
+	if err := h.Comments.Create(r.Context(), &comment); err != nil {
+		if errors.Is(err, repositories.ErrMissingReference) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+			return
 		}
+		log.Printf("Error saving comment: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the comment"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&comment); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
 	}
 }
 
 // GetCommentReplies obtiene las respuestas a un comentario - Requiere id
-func GetCommentReplies(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	commentIDStr := params["id"]
-
-	// chequeo existencia del usuario
-	commentID, err := strconv.Atoi(commentIDStr) // cambio de str a int para evitar errores
+func (h *Handler) GetCommentReplies(w http.ResponseWriter, r *http.Request) {
+	// chequeo existencia del comentario
+	commentID, err := pathID(r, "id")
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		if _, err := w.Write([]byte("Comment not found")); err != nil {
@@ -139,9 +168,14 @@ func GetCommentReplies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := h.parsePage(w, r, commentsPage)
+	if !ok {
+		return
+	}
+
 	// realizacion de la query y manejo de errores
-	var comments []models.Comment
-	if err := db.DB.Where("parent_comment_id = ?", commentID).Find(&comments).Error; err != nil {
+	comments, total, err := h.Comments.ListReplies(r.Context(), commentID, page)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := w.Write([]byte("Error fetching Comments")); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
@@ -149,7 +183,5 @@ func GetCommentReplies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&comments); err != nil {
-		log.Fatalf("Failed to encode json: %v", err)
-	}
+	writePage(w, pagination.NewPage(h.Cursors, page, comments, total, commentKey(page.Order)))
 }
@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+// TestCommentsComeFromTheRoute controla que el proyecto, el comentario padre y el autor salgan de la
+// ruta y de la sesion, y no del body
+func TestCommentsComeFromTheRoute(t *testing.T) {
+	api := newTestAPI(t)
+	author := api.user("author", false)
+	other := api.user("other", false)
+	project := api.project(author, "Banco")
+	elsewhere := api.project(other, "Mesa")
+	foreign := decode[models.Comment](t, api.expect(http.StatusOK, "POST",
+		urlf("/projects/%d/comments", elsewhere.ID), other, map[string]any{"content": "Otro"}))
+
+	forged := map[string]any{
+		"content": "  Muy bueno  ", "project_id": elsewhere.ID, "parent_comment_id": foreign.ID,
+		"user_id": other.ID, "rating": 5,
+	}
+	comment := decode[models.Comment](t, api.expect(http.StatusOK, "POST",
+		urlf("/projects/%d/comments", project.ID), author, forged))
+	if comment.ProjectID != project.ID || comment.ParentCommentID != 0 || comment.UserID != author.ID ||
+		comment.Rating != 0 || comment.Content != "Muy bueno" {
+		t.Errorf("comment = %+v, want a top-level comment by the author on project %d", comment, project.ID)
+	}
+
+	reply := decode[models.Comment](t, api.expect(http.StatusOK, "POST",
+		urlf("/comments/%d/reply", comment.ID), other, forged))
+	if reply.ProjectID != project.ID || reply.ParentCommentID != comment.ID || reply.UserID != other.ID ||
+		reply.Rating != 0 {
+		t.Errorf("reply = %+v, want a reply by other to comment %d on project %d", reply, comment.ID, project.ID)
+	}
+	replies := decode[pagination.Page[models.Comment]](t, api.expect(http.StatusOK, "GET",
+		urlf("/comments/%d/replies", comment.ID), nil, nil)).Items
+	if len(replies) != 1 || replies[0].ID != reply.ID {
+		t.Errorf("replies = %+v, want [%d]", replies, reply.ID)
+	}
+	if replies := decode[pagination.Page[models.Comment]](t, api.expect(http.StatusOK, "GET",
+		urlf("/comments/%d/replies", foreign.ID), nil, nil)).Items; len(replies) != 0 {
+		t.Errorf("the forged parent got replies %+v", replies)
+	}
+
+	api.expect(http.StatusNotFound, "POST", "/projects/999/comments", author, map[string]any{"content": "Hola"})
+	api.expect(http.StatusNotFound, "POST", "/comments/999/reply", author, map[string]any{"content": "Hola"})
+	api.expect(http.StatusUnauthorized, "POST", urlf("/comments/%d/reply", comment.ID), nil,
+		map[string]any{"content": "Hola"})
+}
+
+func TestCommentContentLimits(t *testing.T) {
+	api := newTestAPI(t)
+	author := api.user("author", false)
+	project := api.project(author, "Banco")
+	comment := decode[models.Comment](t, api.expect(http.StatusOK, "POST",
+		urlf("/projects/%d/comments", project.ID), author, map[string]any{"content": "Muy bueno"}))
+
+	tests := []struct {
+		content string
+		status  int
+	}{
+		{"", http.StatusBadRequest},
+		{" \n\t ", http.StatusBadRequest},
+		{strings.Repeat("ñ", 200), http.StatusOK},
+		{"  " + strings.Repeat("a", 200) + "  ", http.StatusOK},
+		{strings.Repeat("a", 201), http.StatusBadRequest},
+	}
+	for _, path := range []string{urlf("/projects/%d/comments", project.ID), urlf("/comments/%d/reply", comment.ID)} {
+		for _, tt := range tests {
+			rec := api.do("POST", path, author, map[string]any{"content": tt.content})
+			if rec.Code != tt.status {
+				t.Errorf("POST %s with %d characters: status = %d, want %d", path, len([]rune(tt.content)),
+					rec.Code, tt.status)
+			}
+		}
+		if rec := api.do("POST", path, author, "no es un objeto"); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s with invalid JSON: status = %d, want 400", path, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/carpentry-hub/woodys-backend/markdown"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/storage"
+	"github.com/carpentry-hub/woodys-backend/uploads"
+	"github.com/gorilla/mux"
+)
+
+// Handler agrupa los handlers de la api junto con los repositorios que utilizan
+type Handler struct {
+	Users           repositories.UserRepository
+	Projects        repositories.ProjectRepository
+	Comments        repositories.CommentRepository
+	Ratings         repositories.RatingRepository
+	ProjectLists    repositories.ProjectListRepository
+	ProfilePictures repositories.ProfilePictureRepository
+	Inventory       repositories.InventoryRepository
+	Taxonomy        repositories.TaxonomyRepository
+	Parts           repositories.ProjectPartRepository
+	Steps           repositories.ProjectStepRepository
+	Prices          repositories.PriceRepository
+	Uploads         repositories.UploadRepository
+	Revisions       repositories.ProjectRevisionRepository
+
+	// Storage guarda los archivos de los uploads; sin Storage POST /uploads responde 503
+	Storage storage.Storage
+	// UploadWorker genera las variantes de cada upload nuevo; sin worker quedan pendientes
+	UploadWorker *uploads.Worker
+
+	// Cursors firma los cursores de paginacion. NewHandler usa una clave aleatoria.
+	Cursors *pagination.Codec
+
+	// UploadMaxBytes y UploadQuota limitan el tamaño de cada imagen y el total por usuario.
+	// UploadBaseURL es el prefijo de la url de cada upload.
+	UploadMaxBytes int64
+	UploadQuota    int64
+	UploadBaseURL  string
+
+	// Markdown renderiza descripciones, pasos y comentarios. NewHandler solo acepta imagenes del sitio.
+	Markdown *markdown.Renderer
+
+	// RatingPriorWeight pondera el puntaje bayesiano de los proyectos
+	RatingPriorWeight float64
+	// PriceCurrency es la moneda del presupuesto de un proyecto cuando no se pide otra
+	PriceCurrency string
+	// RevisionRetention es cuantas revisiones se guardan de cada proyecto
+	RevisionRetention repositories.RevisionRetention
+	siteMean          siteMeanCache
+	taxonomy          taxonomyCache
+}
+
+// NewHandler crea un Handler a partir de los repositorios
+func NewHandler(repos *repositories.Repositories) *Handler {
+	return &Handler{
+		Users:           repos.Users,
+		Projects:        repos.Projects,
+		Comments:        repos.Comments,
+		Ratings:         repos.Ratings,
+		ProjectLists:    repos.ProjectLists,
+		ProfilePictures: repos.ProfilePictures,
+		Inventory:       repos.Inventory,
+		Taxonomy:        repos.Taxonomy,
+		Parts:           repos.Parts,
+		Steps:           repos.Steps,
+		Prices:          repos.Prices,
+		Uploads:         repos.Uploads,
+		Revisions:       repos.Revisions,
+
+		Cursors:           pagination.NewRandomCodec(),
+		Markdown:          markdown.New(nil),
+		UploadMaxBytes:    DefaultUploadMaxBytes,
+		UploadQuota:       DefaultUploadQuota,
+		UploadBaseURL:     DefaultUploadBaseURL,
+		RatingPriorWeight: DefaultRatingPriorWeight,
+		PriceCurrency:     DefaultPriceCurrency,
+		RevisionRetention: repositories.RevisionRetention{Keep: DefaultRevisionsKept},
+	}
+}
+
+// pathID lee un parametro numerico de la ruta
+func pathID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+}
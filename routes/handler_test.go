@@ -0,0 +1,177 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/middlewares"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/repositories/memory"
+	"github.com/gorilla/mux"
+)
+
+// testAPI es un Handler sobre repositorios en memoria con las rutas de main.go
+type testAPI struct {
+	t      *testing.T
+	h      *Handler
+	repos  *repositories.Repositories
+	store  *memory.Store
+	router *mux.Router
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	repos, store := memory.New()
+	h := NewHandler(repos)
+	return &testAPI{t: t, h: h, repos: repos, store: store, router: testRouter(h)}
+}
+
+// testRouter registra las rutas que usan los tests con los mismos paths que main.go
+func testRouter(h *Handler) *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/profile-picture/{id}", h.GetProfilePictureByID).Methods("GET")
+	r.HandleFunc("/uploads/{id}", h.GetUpload).Methods("GET")
+	r.HandleFunc("/uploads/{id}/{name}", h.GetUploadVariant).Methods("GET")
+	r.HandleFunc("/profile-pictures", h.PostProfilePicture).Methods("POST")
+	r.HandleFunc("/profile-pictures/{id:[0-9]+}", h.RetireProfilePicture).Methods("DELETE")
+
+	r.HandleFunc("/users/{id}", h.GetUser).Methods("GET")
+	r.HandleFunc("/users", h.PostUser).Methods("POST")
+	r.HandleFunc("/users/{id}", h.PutUser).Methods("PUT")
+	r.HandleFunc("/users/{id}", h.DeleteUser).Methods("DELETE")
+
+	r.HandleFunc("/projects/search", h.SearchProjects).Methods("GET")
+	r.HandleFunc("/projects/buildable", h.GetBuildableProjects).Methods("GET")
+	r.HandleFunc("/projects/{id:[0-9]+}", h.GetProject).Methods("GET")
+	r.HandleFunc("/projects", h.PostProject).Methods("POST")
+	r.HandleFunc("/projects/{id}", h.PutProject).Methods("PUT")
+	r.HandleFunc("/projects/{id}", h.DeleteProject).Methods("DELETE")
+	r.HandleFunc("/projects/{id}/parts", h.GetProjectParts).Methods("GET")
+	r.HandleFunc("/projects/{id}/parts", h.PutProjectParts).Methods("PUT")
+	r.HandleFunc("/projects/{id}/cutlist", h.GetProjectCutlist).Methods("GET")
+	r.HandleFunc("/projects/{id}/steps", h.GetProjectSteps).Methods("GET")
+	r.HandleFunc("/projects/{id}/steps", h.PostProjectStep).Methods("POST")
+	r.HandleFunc("/projects/{id}/steps/order", h.ReorderProjectSteps).Methods("PUT")
+	r.HandleFunc("/projects/{id}/steps/{step_id:[0-9]+}", h.PutProjectStep).Methods("PUT")
+	r.HandleFunc("/projects/{id}/steps/{step_id:[0-9]+}", h.DeleteProjectStep).Methods("DELETE")
+	r.HandleFunc("/projects/{id}/revisions", h.GetProjectRevisions).Methods("GET")
+	r.HandleFunc("/projects/{id}/revisions/{rev:[0-9]+}/restore", h.RestoreProjectRevision).Methods("POST")
+
+	r.HandleFunc("/projects/{id}/comments", h.GetProjectComments).Methods("GET")
+	r.HandleFunc("/projects/{id}/comments", h.PostProjectComment).Methods("POST")
+	r.HandleFunc("/comments/{id}", h.DeleteComment).Methods("DELETE")
+	r.HandleFunc("/comments/{id}/reply", h.PostCommentReply).Methods("POST")
+	r.HandleFunc("/comments/{id}/replies", h.GetCommentReplies).Methods("GET")
+
+	r.HandleFunc("/projects/{id}/ratings", h.PostRating).Methods("POST")
+	r.HandleFunc("/projects/{id}/ratings", h.PutRating).Methods("PUT")
+	r.HandleFunc("/projects/{id}/ratings", h.DeleteRating).Methods("DELETE")
+	r.HandleFunc("/projects/{id}/ratings/me", h.GetMyRating).Methods("GET")
+	r.HandleFunc("/users/{id}/ratings", h.GetUserRatings).Methods("GET")
+
+	r.HandleFunc("/users/{id}/inventory", h.PostInventoryItem).Methods("POST")
+
+	r.HandleFunc("/project-lists/{id}", h.GetProjectLists).Methods("GET")
+	r.HandleFunc("/project-lists", h.PostProjectLists).Methods("POST")
+	r.HandleFunc("/project-lists/{id}/projects", h.AddProjectToList).Methods("POST")
+	r.HandleFunc("/project-lists/{id}/projects", h.GetProjectsInList).Methods("GET")
+	r.HandleFunc("/project-lists/{id}/shopping-list", h.GetShoppingList).Methods("GET")
+	r.HandleFunc("/project-lists/{id}", h.PutProjectLists).Methods("PUT")
+	r.HandleFunc("/project-lists/{id}", h.DeleteProjectList).Methods("DELETE")
+	r.HandleFunc("/project-lists/{list_id}/projects/{project_id}", h.DeleteProjectFromList).Methods("DELETE")
+	return r
+}
+
+// request hace un pedido con la identidad indicada; claims nil es un pedido anonimo
+func (api *testAPI) request(
+	method, path string, claims *middlewares.Claims, user *models.User, body any,
+) *httptest.ResponseRecorder {
+	api.t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			api.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	if claims != nil {
+		req = req.WithContext(middlewares.WithIdentity(req.Context(), claims, user))
+	}
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// do hace un pedido como user; nil es un pedido anonimo
+func (api *testAPI) do(method, path string, user *models.User, body any) *httptest.ResponseRecorder {
+	api.t.Helper()
+	if user == nil {
+		return api.request(method, path, nil, nil, body)
+	}
+	return api.request(method, path, &middlewares.Claims{Subject: user.FirebaseUID}, user, body)
+}
+
+// expect hace el pedido y falla el test si el status no es el esperado
+func (api *testAPI) expect(
+	status int, method, path string, user *models.User, body any,
+) *httptest.ResponseRecorder {
+	api.t.Helper()
+	rec := api.do(method, path, user, body)
+	if rec.Code != status {
+		api.t.Fatalf("%s %s: status = %d, want %d (%s)", method, path, rec.Code, status, rec.Body)
+	}
+	return rec
+}
+
+// user guarda un usuario directo en el repositorio
+func (api *testAPI) user(name string, admin bool) *models.User {
+	api.t.Helper()
+	user := models.User{Username: name, FirebaseUID: "uid-" + name, IsAdmin: admin}
+	if err := api.repos.Users.Create(context.Background(), &user); err != nil {
+		api.t.Fatal(err)
+	}
+	return &user
+}
+
+// project guarda un proyecto publico de owner directo en el repositorio
+func (api *testAPI) project(owner *models.User, title string) *models.Project {
+	api.t.Helper()
+	project := models.Project{
+		Owner: owner.ID, Title: title, IsPublic: true,
+		Materials: []string{}, Tools: []string{}, Style: []string{}, Images: []string{},
+	}
+	if err := api.repos.Projects.Create(context.Background(), &project, nil, repositories.Revision{}); err != nil {
+		api.t.Fatal(err)
+	}
+	return &project
+}
+
+// terms guarda terminos de la taxonomia directo en el repositorio, los padres antes que los hijos
+func (api *testAPI) terms(terms ...models.TaxonomyTerm) {
+	api.t.Helper()
+	for _, term := range terms {
+		if err := api.repos.Taxonomy.Create(context.Background(), &term); err != nil {
+			api.t.Fatal(err)
+		}
+	}
+}
+
+// decode lee el cuerpo JSON de la respuesta
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.NewDecoder(rec.Body).Decode(&v); err != nil {
+		t.Fatalf("decoding %q: %v", rec.Body, err)
+	}
+	return v
+}
+
+// urlf arma el path de un pedido
+func urlf(format string, args ...any) string {
+	return fmt.Sprintf(format, args...)
+}
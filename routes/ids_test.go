@@ -0,0 +1,125 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/middlewares"
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// TestLargeIDsRoundTrip recorre las rutas con ids que no entran en int8 ni en int32
+func TestLargeIDsRoundTrip(t *testing.T) {
+	for _, start := range []int64{200, 40_000, 3_000_000_000} {
+		t.Run(strconv.FormatInt(start, 10), func(t *testing.T) {
+			api := newTestAPI(t)
+			api.store.SetNextID(start)
+			admin := api.user("admin", true)
+			checkID := func(kind string, id int64) {
+				t.Helper()
+				if id < start {
+					t.Fatalf("%s id = %d, want >= %d", kind, id, start)
+				}
+			}
+
+			// fotos de perfil
+			rec := api.expect(http.StatusCreated, "POST", "/profile-pictures", admin,
+				models.ProfilePicture{Referenced: "https://example.com/a.png"})
+			picture := decode[models.ProfilePicture](t, rec)
+			checkID("profile picture", picture.ID)
+			rec = api.do("GET", urlf("/profile-picture/%d", picture.ID), nil, nil)
+			if rec.Code != http.StatusFound {
+				t.Fatalf("GET profile picture: status = %d, want 302", rec.Code)
+			}
+
+			// usuarios
+			claims := &middlewares.Claims{Subject: "uid-new", Email: "new@example.com"}
+			rec = api.request("POST", "/users", claims, nil, models.User{Username: "new", ProfilePicture: picture.ID})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("POST /users: status = %d (%s)", rec.Code, rec.Body)
+			}
+			user := decode[models.User](t, rec)
+			checkID("user", user.ID)
+			if user.ProfilePicture != picture.ID {
+				t.Errorf("profile_picture = %d, want %d", user.ProfilePicture, picture.ID)
+			}
+			rec = api.expect(http.StatusOK, "PUT", urlf("/users/%d", user.ID), &user,
+				models.User{Username: "renamed", ProfilePicture: picture.ID})
+			if got := decode[models.User](t, rec); got.ID != user.ID || got.Username != "renamed" {
+				t.Errorf("PUT user = %d %q, want %d renamed", got.ID, got.Username, user.ID)
+			}
+			rec = api.expect(http.StatusOK, "GET", urlf("/users/%d", user.ID), nil, nil)
+			if got := decode[models.User](t, rec); got.ID != user.ID {
+				t.Errorf("GET user id = %d, want %d", got.ID, user.ID)
+			}
+
+			// proyectos
+			rec = api.expect(http.StatusOK, "POST", "/projects", &user, models.Project{Title: "Mesa"})
+			project := decode[models.Project](t, rec)
+			checkID("project", project.ID)
+			if project.Owner != user.ID {
+				t.Errorf("owner = %d, want %d", project.Owner, user.ID)
+			}
+			api.expect(http.StatusOK, "PUT", urlf("/projects/%d", project.ID), &user, models.Project{Title: "Mesa ratona"})
+			rec = api.expect(http.StatusOK, "GET", urlf("/projects/%d", project.ID), nil, nil)
+			if got := decode[models.Project](t, rec); got.ID != project.ID || got.Title != "Mesa ratona" {
+				t.Errorf("GET project = %d %q, want %d Mesa ratona", got.ID, got.Title, project.ID)
+			}
+
+			// comentarios
+			rec = api.expect(http.StatusOK, "POST", urlf("/projects/%d/comments", project.ID), &user,
+				models.Comment{ProjectID: project.ID, Content: "Muy bueno"})
+			comment := decode[models.Comment](t, rec)
+			checkID("comment", comment.ID)
+			rec = api.expect(http.StatusOK, "GET", urlf("/projects/%d/comments", project.ID), nil, nil)
+			if items := decode[struct{ Items []models.Comment }](t, rec).Items; len(items) != 1 || items[0].ID != comment.ID {
+				t.Errorf("comments = %+v, want [%d]", items, comment.ID)
+			}
+			api.expect(http.StatusOK, "DELETE", urlf("/comments/%d", comment.ID), &user, nil)
+
+			// valoraciones
+			rec = api.expect(http.StatusOK, "POST", urlf("/projects/%d/ratings", project.ID), admin, models.Rating{Value: 4})
+			rating := decode[models.Rating](t, rec)
+			checkID("rating", rating.ID)
+			if rating.ProjectID != project.ID || rating.UserID != admin.ID {
+				t.Errorf("rating = %+v, want project %d user %d", rating, project.ID, admin.ID)
+			}
+			api.expect(http.StatusOK, "PUT", urlf("/projects/%d/ratings", project.ID), admin, models.Rating{Value: 2})
+			rec = api.expect(http.StatusOK, "GET", urlf("/projects/%d/ratings/me", project.ID), admin, nil)
+			if got := decode[models.Rating](t, rec); got.ID != rating.ID || got.Value != 2 {
+				t.Errorf("GET rating = %d value %d, want %d value 2", got.ID, got.Value, rating.ID)
+			}
+			api.expect(http.StatusOK, "DELETE", urlf("/projects/%d/ratings", project.ID), admin, nil)
+
+			// listas
+			rec = api.expect(http.StatusOK, "POST", "/project-lists", &user, models.ProjectList{Name: "Favoritos"})
+			list := decode[models.ProjectList](t, rec)
+			checkID("list", list.ID)
+			rec = api.expect(http.StatusCreated, "POST", urlf("/project-lists/%d/projects", list.ID), &user,
+				models.ProjectListItem{ProjectID: project.ID})
+			if item := decode[models.ProjectListItem](t, rec); item.ProjectListID != list.ID || item.ProjectID != project.ID {
+				t.Errorf("list item = %+v, want list %d project %d", item, list.ID, project.ID)
+			}
+			rec = api.expect(http.StatusOK, "GET", urlf("/project-lists/%d/projects", list.ID), nil, nil)
+			if items := decode[struct{ Items []models.Project }](t, rec).Items; len(items) != 1 || items[0].ID != project.ID {
+				t.Errorf("projects in list = %+v, want [%d]", items, project.ID)
+			}
+			api.expect(http.StatusOK, "PUT", urlf("/project-lists/%d", list.ID), &user, models.ProjectList{Name: "Para hacer"})
+			rec = api.expect(http.StatusOK, "GET", urlf("/project-lists/%d", list.ID), nil, nil)
+			if got := decode[models.ProjectList](t, rec); got.ID != list.ID || got.Name != "Para hacer" {
+				t.Errorf("GET list = %d %q, want %d Para hacer", got.ID, got.Name, list.ID)
+			}
+			api.expect(http.StatusOK, "DELETE", urlf("/project-lists/%d/projects/%d", list.ID, project.ID), &user, nil)
+			api.expect(http.StatusOK, "DELETE", urlf("/project-lists/%d", list.ID), &user, nil)
+			api.expect(http.StatusNotFound, "GET", urlf("/project-lists/%d", list.ID), nil, nil)
+
+			// bajas
+			api.expect(http.StatusOK, "DELETE", urlf("/profile-pictures/%d", picture.ID), admin, nil)
+			api.expect(http.StatusOK, "DELETE", urlf("/projects/%d", project.ID), &user, nil)
+			api.expect(http.StatusNotFound, "GET", urlf("/projects/%d", project.ID), nil, nil)
+			api.expect(http.StatusOK, "DELETE", urlf("/users/%d", user.ID), &user, nil)
+			api.expect(http.StatusNotFound, "GET", urlf("/users/%d", user.ID), nil, nil)
+		})
+	}
+}
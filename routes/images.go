@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// immutableCache es el Cache-Control de las imagenes que nunca cambian bajo la misma url
+const immutableCache = "public, max-age=31536000, immutable"
+
+// Las rutas de imagenes no pasan por middlewares.JsonContentType, asi que sus respuestas ponen el
+// Content-Type que corresponde: el de la imagen o JSON en los errores.
+
+// writeImage manda body como una imagen de contentType y size bytes
+func writeImage(w http.ResponseWriter, contentType string, size int64, body io.Reader) error {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, err := io.Copy(w, body)
+	return err
+}
+
+// writeMessage responde {"message": message} en JSON con status
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// notModified pone el ETag y, si el cliente ya tiene esa version (If-None-Match), responde 304.
+// If-None-Match compara sin el prefijo W/, como pide la RFC 9110.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
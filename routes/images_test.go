@@ -0,0 +1,166 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/storage"
+	"github.com/carpentry-hub/woodys-backend/uploads"
+)
+
+// picture guarda una foto de perfil por defecto directo en el repositorio
+func (api *testAPI) picture(referenced string) int64 {
+	api.t.Helper()
+	picture := models.ProfilePicture{Referenced: referenced}
+	if err := api.repos.ProfilePictures.Create(context.Background(), &picture); err != nil {
+		api.t.Fatal(err)
+	}
+	return picture.ID
+}
+
+// get hace un GET anonimo con los headers indicados
+func (api *testAPI) get(path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := png.Encode(&out, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// checkImage controla una respuesta de imagen cacheable y que un If-None-Match con su ETag, con o
+// sin W/, de 304 sin cuerpo
+func checkImage(t *testing.T, api *testAPI, path, contentType string, want []byte, etag string) {
+	t.Helper()
+	rec := api.get(path, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d (%s)", path, rec.Code, rec.Body)
+	}
+	header := rec.Header()
+	if header.Get("Content-Type") != contentType || header.Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("GET %s: Content-Type = %q, nosniff = %q; want %q", path, header.Get("Content-Type"),
+			header.Get("X-Content-Type-Options"), contentType)
+	}
+	if header.Get("ETag") != etag {
+		t.Errorf("GET %s: ETag = %q, want the strong %q", path, header.Get("ETag"), etag)
+	}
+	if header.Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf("GET %s: Cache-Control = %q", path, header.Get("Cache-Control"))
+	}
+	if !bytes.Equal(rec.Body.Bytes(), want) {
+		t.Errorf("GET %s: body is %d bytes, want the %d of the image", path, rec.Body.Len(), len(want))
+	}
+
+	for _, match := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		rec := api.get(path, map[string]string{"If-None-Match": match})
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != etag {
+			t.Errorf("GET %s with If-None-Match %s: status = %d, body %q, ETag %q; want an empty 304",
+				path, match, rec.Code, rec.Body, rec.Header().Get("ETag"))
+		}
+	}
+	if rec := api.get(path, map[string]string{"If-None-Match": `"other"`}); rec.Code != http.StatusOK {
+		t.Errorf("GET %s with another ETag: status = %d, want 200", path, rec.Code)
+	}
+}
+
+func TestProfilePictureDataImage(t *testing.T) {
+	api := newTestAPI(t)
+	data := testPNG(t)
+	// el tipo sale del contenido y no de lo que declara la url
+	id := api.picture("data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data))
+	sum := sha256.Sum256(data)
+	checkImage(t, api, urlf("/profile-picture/%d", id), uploads.PNG, data, `"`+hex.EncodeToString(sum[:])+`"`)
+}
+
+func TestProfilePictureUpload(t *testing.T) {
+	api := newTestAPI(t)
+	store, err := storage.NewFilesystem(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.h.Storage = store
+	ctx := context.Background()
+
+	data := testPNG(t)
+	sum := sha256.Sum256(data)
+	owner := api.user("admin", true)
+	upload := models.Upload{ID: uploads.NewID(), UserID: owner.ID, ContentType: uploads.PNG, Size: int64(len(data)),
+		Width: 4, Height: 4, SHA256: hex.EncodeToString(sum[:])}
+	if err := api.repos.Uploads.Create(ctx, &upload, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, uploads.Key(upload.ID, uploads.OriginalName), data, uploads.PNG); err != nil {
+		t.Fatal(err)
+	}
+	id := api.picture(api.h.uploadURL(upload.ID))
+
+	etag := `"` + upload.SHA256 + `"`
+	checkImage(t, api, urlf("/profile-picture/%d", id), uploads.PNG, data, etag)
+	checkImage(t, api, "/uploads/"+upload.ID, uploads.PNG, data, etag)
+
+	// con Accept: application/json es la foto y no la imagen
+	rec := api.get(urlf("/profile-picture/%d", id), map[string]string{"Accept": "application/json"})
+	if rec.Header().Get("Content-Type") != "application/json" || !strings.Contains(rec.Body.String(), upload.ID) {
+		t.Errorf("JSON picture = %q (%s)", rec.Header().Get("Content-Type"), rec.Body)
+	}
+}
+
+func TestProfilePictureRedirect(t *testing.T) {
+	api := newTestAPI(t)
+	for _, referenced := range []string{
+		"https://cdn.example.com/avatars/a.png", "http://example.com/b.png", "/static/avatars/c.png",
+	} {
+		path := urlf("/profile-picture/%d", api.picture(referenced))
+		rec := api.get(path, nil)
+		if rec.Code != http.StatusFound || rec.Header().Get("Location") != referenced {
+			t.Errorf("%s: status = %d, Location = %q; want a redirect", referenced, rec.Code, rec.Header().Get("Location"))
+		}
+		if rec.Header().Get("Cache-Control") != immutableCache || !strings.HasPrefix(rec.Header().Get("ETag"), `"`) {
+			t.Errorf("%s: Cache-Control = %q, ETag = %q", referenced, rec.Header().Get("Cache-Control"),
+				rec.Header().Get("ETag"))
+		}
+		etag := rec.Header().Get("ETag")
+		if rec := api.get(path, map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+			t.Errorf("%s with If-None-Match: status = %d, want 304", referenced, rec.Code)
+		}
+	}
+
+	for _, referenced := range []string{
+		"javascript:alert(1)", "ftp://example.com/a.png", "file:///etc/passwd", "//evil.example.com/a.png",
+		"data:text/html;base64,PHNjcmlwdD4=", "avatar.png",
+	} {
+		rec := api.get(urlf("/profile-picture/%d", api.picture(referenced)), nil)
+		if rec.Code != http.StatusNotFound || rec.Header().Get("Location") != "" {
+			t.Errorf("%s: status = %d, Location = %q; want 404 without a redirect", referenced, rec.Code,
+				rec.Header().Get("Location"))
+		}
+		if rec.Header().Get("Content-Type") != "application/json" || rec.Header().Get("Cache-Control") != "" {
+			t.Errorf("%s: Content-Type = %q, Cache-Control = %q; want an uncached JSON error", referenced,
+				rec.Header().Get("Content-Type"), rec.Header().Get("Cache-Control"))
+		}
+	}
+
+	if rec := api.get("/profile-picture/999", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing picture: status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,309 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/carpentry-hub/woodys-backend/inventory"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// Limites del inventario y de /projects/buildable
+const (
+	maxInventoryItems = 500
+	maxInventoryName  = 100
+	defaultMaxMissing = 2
+	maxMaxMissing     = 10
+)
+
+// GetInventory obtiene las herramientas y materiales de un usuario - Requiere id, solo el dueño
+func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "User not found"})
+		return
+	}
+	if !authorize(w, policies.ManageInventory(currentUser(r), userID)) {
+		return
+	}
+
+	items, err := h.Inventory.ListByUser(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Error fetching inventory"})
+		return
+	}
+	if items == nil {
+		items = []models.InventoryItem{}
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"items": items}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PostInventoryItem agrega una herramienta o material al inventario - Requiere id, kind y name
+func (h *Handler) PostInventoryItem(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "User not found"})
+		return
+	}
+	if !authorize(w, policies.ManageInventory(currentUser(r), userID)) {
+		return
+	}
+
+	var item models.InventoryItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	item.ID = 0
+	item.UserID = userID
+	if !h.validInventoryItem(w, r, &item) {
+		return
+	}
+
+	count, err := h.Inventory.CountByUser(r.Context(), userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Error fetching inventory"})
+		return
+	}
+	if count >= maxInventoryItems {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "inventory cannot exceed " + strconv.Itoa(maxInventoryItems) + " items",
+		})
+		return
+	}
+
+	if err := h.Inventory.Create(r.Context(), &item); err != nil {
+		writeInventoryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&item); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PutInventoryItem renombra o cambia el tipo de un item - Requiere id e item_id
+func (h *Handler) PutInventoryItem(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.inventoryItem(w, r)
+	if !ok {
+		return
+	}
+
+	var updated models.InventoryItem
+	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+		w.WriteHeader(http.StatusBadRequest) // 400
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	existing.Kind = updated.Kind
+	existing.Name = updated.Name
+	if !h.validInventoryItem(w, r, existing) {
+		return
+	}
+
+	if err := h.Inventory.Update(r.Context(), existing); err != nil {
+		writeInventoryError(w, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(existing); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// DeleteInventoryItem quita un item del inventario - Requiere id e item_id
+func (h *Handler) DeleteInventoryItem(w http.ResponseWriter, r *http.Request) {
+	item, ok := h.inventoryItem(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Inventory.Delete(r.Context(), item.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete the item"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "Item deleted successfully"})
+}
+
+// inventoryItem busca el item de la ruta y controla que sea del usuario de la ruta y que el
+// usuario autenticado pueda editarlo
+func (h *Handler) inventoryItem(w http.ResponseWriter, r *http.Request) (*models.InventoryItem, bool) {
+	userID, err := pathID(r, "id")
+	var item *models.InventoryItem
+	if err == nil {
+		var itemID int64
+		if itemID, err = pathID(r, "item_id"); err == nil {
+			item, err = h.Inventory.FindByID(r.Context(), itemID)
+		}
+	}
+	if err == nil && item.UserID != userID {
+		err = repositories.ErrNotFound
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Item not found"})
+		return nil, false
+	}
+	if !authorize(w, policies.ManageInventory(currentUser(r), item.UserID)) {
+		return nil, false
+	}
+	return item, true
+}
+
+// validInventoryItem controla kind y name y resuelve el nombre contra la taxonomia
+func (h *Handler) validInventoryItem(w http.ResponseWriter, r *http.Request, item *models.InventoryItem) bool {
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		log.Printf("Error loading taxonomy: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the item"})
+		return false
+	}
+	item.Name = strings.TrimSpace(item.Name)
+	item.NormalizedName = inventory.Key(index, item.Kind, item.Name)
+
+	message := ""
+	switch {
+	case item.Kind != models.InventoryTool && item.Kind != models.InventoryMaterial:
+		message = "kind must be tool or material"
+	case item.NormalizedName == "":
+		message = "name cannot be empty"
+	case utf8.RuneCountInString(item.Name) > maxInventoryName:
+		message = "name cannot exceed " + strconv.Itoa(maxInventoryName) + " characters"
+	}
+	if message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": message})
+		return false
+	}
+	return true
+}
+
+func writeInventoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrConflict):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"message": "The item is already in the inventory"})
+	case errors.Is(err, repositories.ErrMissingReference):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "User not found"})
+	default:
+		log.Printf("Error saving inventory item: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the item"})
+	}
+}
+
+// buildableProject es un resultado de GetBuildableProjects
+type buildableProject struct {
+	Project models.Project `json:"project"`
+	inventory.Coverage
+	// rank es la posicion en el orden "missing", para el cursor
+	rank float64
+}
+
+// GetBuildableProjects lista los proyectos publicos que el usuario autenticado puede construir con su
+// inventario, de mas a menos construible. max_missing es la cantidad de herramientas que se admite
+// que falten (default 2); los materiales faltantes no descartan, solo bajan en el orden.
+func (h *Handler) GetBuildableProjects(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
+		return
+	}
+
+	maxMissing, ok := queryInt(w, r.URL.Query().Get("max_missing"), "max_missing", defaultMaxMissing, 0, maxMaxMissing)
+	if !ok {
+		return
+	}
+	page, ok := h.parsePage(w, r, buildablePage)
+	if !ok {
+		return
+	}
+
+	items, err := h.Inventory.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Error fetching inventory", http.StatusInternalServerError)
+		return
+	}
+	var toolNames, materialNames []string
+	for _, item := range items {
+		if item.Kind == models.InventoryTool {
+			toolNames = append(toolNames, item.Name)
+		} else {
+			materialNames = append(materialNames, item.Name)
+		}
+	}
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+	filter := repositories.BuildableFilter{
+		Tools:           inventory.NewSet(index, models.TaxonomyTool, toolNames...).Keys(),
+		Materials:       inventory.NewSet(index, models.TaxonomyMaterial, materialNames...).Keys(),
+		MaxMissingTools: maxMissing,
+	}
+
+	rows, total, err := h.Projects.ListBuildable(r.Context(), filter, page)
+	if err != nil {
+		log.Printf("Error listing buildable projects: %v", err)
+		http.Error(w, "Error fetching projects", http.StatusInternalServerError)
+		return
+	}
+	projects := make([]models.Project, len(rows))
+	for i := range rows {
+		projects[i] = rows[i].Project
+		if err := h.scoreProjects(r.Context(), &projects[i]); err != nil {
+			log.Printf("Error scoring project %d: %v", projects[i].ID, err)
+		}
+	}
+	if err := h.attachImageList(r.Context(), projects); err != nil {
+		log.Printf("Error loading project images: %v", err)
+	}
+
+	results := make([]buildableProject, len(rows))
+	for i, row := range rows {
+		results[i] = buildableProject{
+			Project: projects[i],
+			Coverage: inventory.Coverage{
+				Ratio:            row.Coverage,
+				MissingTools:     row.MissingTools,
+				MissingMaterials: row.MissingMaterials,
+			},
+			rank: row.Rank,
+		}
+	}
+	writePage(w, pagination.NewPage(h.Cursors, page, results, total, buildableKey(page.Order)))
+}
+
+// queryInt lee un entero opcional de la query dentro de [lower, upper]; responde 400 si no es valido
+func queryInt(w http.ResponseWriter, raw, name string, fallback, lower, upper int) (int, bool) {
+	if raw == "" {
+		return fallback, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < lower || value > upper {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": name + " must be an integer between " + strconv.Itoa(lower) + " and " + strconv.Itoa(upper),
+		})
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+func TestBuildableProjects(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	maker := api.user("maker", false)
+	powerTools, softwood := "power-tools", "softwood"
+	api.terms(
+		models.TaxonomyTerm{Kind: models.TaxonomyTool, Slug: powerTools,
+			LabelES: "Herramientas electricas", LabelEN: "Power tools"},
+		models.TaxonomyTerm{Kind: models.TaxonomyTool, Slug: "jigsaw", Parent: &powerTools,
+			LabelES: "Sierra caladora", LabelEN: "Jigsaw", Synonyms: []string{"caladora"}},
+		models.TaxonomyTerm{Kind: models.TaxonomyTool, Slug: "drill", Parent: &powerTools,
+			LabelES: "Taladro", LabelEN: "Drill"},
+		models.TaxonomyTerm{Kind: models.TaxonomyTool, Slug: "sander", Parent: &powerTools,
+			LabelES: "Lijadora", LabelEN: "Sander"},
+		models.TaxonomyTerm{Kind: models.TaxonomyMaterial, Slug: softwood, LabelES: "Madera blanda", LabelEN: "Softwood"},
+		models.TaxonomyTerm{Kind: models.TaxonomyMaterial, Slug: "pine", Parent: &softwood, LabelES: "Pino", LabelEN: "Pine"},
+	)
+	for _, item := range []models.InventoryItem{
+		{Kind: models.InventoryTool, Name: "Caladoras"},
+		{Kind: models.InventoryTool, Name: "Taladro"},
+		{Kind: models.InventoryMaterial, Name: "Madera de pino"},
+	} {
+		api.expect(http.StatusCreated, "POST", urlf("/users/%d/inventory", maker.ID), maker, item)
+	}
+	for _, project := range []models.Project{
+		{Title: "falta una herramienta", Tools: []string{"jigsaw", "sander"}, Materials: []string{"pine"}},
+		{Title: "faltan tres herramientas", Tools: []string{"router", "sander", "clamp"}},
+		{Title: "falta un material", Tools: []string{"jigsaw"}, Materials: []string{"pine", "wood-glue"}},
+		{Title: "completo", Tools: []string{"jigsaw", "drill", "jigsaw"}, Materials: []string{"pine"}},
+		{Title: "privado", Tools: []string{"jigsaw"}},
+		{Title: "generico", Tools: []string{"power-tools"}, Materials: []string{"softwood"}},
+	} {
+		project.Owner, project.IsPublic = owner.ID, project.Title != "privado"
+		if err := api.repos.Projects.Create(context.Background(), &project, nil, repositories.Revision{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type result struct {
+		Project          models.Project `json:"project"`
+		Coverage         float64        `json:"coverage"`
+		MissingTools     []string       `json:"missing_tools"`
+		MissingMaterials []string       `json:"missing_materials"`
+	}
+	// buildable recorre todas las paginas de a una y devuelve los titulos en orden
+	buildable := func(query string) ([]string, map[string]result) {
+		t.Helper()
+		var titles []string
+		results := map[string]result{}
+		cursor := ""
+		for {
+			path := "/projects/buildable?limit=1" + query
+			if cursor != "" {
+				path += "&cursor=" + url.QueryEscape(cursor)
+			}
+			page := decode[pagination.Page[result]](t, api.expect(http.StatusOK, "GET", path, maker, nil))
+			for _, item := range page.Items {
+				titles = append(titles, item.Project.Title)
+				results[item.Project.Title] = item
+			}
+			if cursor = page.NextCursor; cursor == "" {
+				return titles, results
+			}
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"completo", "generico", "falta un material", "falta una herramienta"}},
+		{"&max_missing=0", []string{"completo", "generico", "falta un material"}},
+		{"&max_missing=3", []string{"completo", "generico", "falta un material", "falta una herramienta",
+			"faltan tres herramientas"}},
+		{"&sort=missing&order=desc", []string{"falta una herramienta", "falta un material", "generico", "completo"}},
+	}
+	for _, tt := range tests {
+		if got, _ := buildable(tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("buildable%s = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	_, results := buildable("&max_missing=3")
+	if got := results["completo"]; got.Coverage != 1 || len(got.MissingTools) != 0 || len(got.MissingMaterials) != 0 {
+		t.Errorf("completo = %+v, want full coverage", got)
+	}
+	if got := results["falta un material"]; got.Coverage != 2.0/3 ||
+		!slices.Equal(got.MissingMaterials, []string{"wood-glue"}) {
+		t.Errorf("falta un material = %+v, want coverage 2/3 missing wood-glue", got)
+	}
+	if got := results["faltan tres herramientas"]; got.Coverage != 0 ||
+		!slices.Equal(got.MissingTools, []string{"router", "sander", "clamp"}) {
+		t.Errorf("faltan tres herramientas = %+v, want the three tools missing in order", got)
+	}
+
+	// el inventario resuelve por sinonimo y etiqueta, asi que repetir un item con otro nombre es un 409
+	api.expect(http.StatusConflict, "POST", urlf("/users/%d/inventory", maker.ID), maker,
+		models.InventoryItem{Kind: models.InventoryTool, Name: "jigsaw"})
+
+	api.expect(http.StatusUnauthorized, "GET", "/projects/buildable", nil, nil)
+	api.expect(http.StatusBadRequest, "GET", "/projects/buildable?max_missing=11", maker, nil)
+	api.expect(http.StatusBadRequest, "GET", "/projects/buildable?sort=title", maker, nil)
+}
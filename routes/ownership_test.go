@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// ownershipFixture son los recursos de owner sobre los que prueban los demas usuarios
+type ownershipFixture struct {
+	owner, other, admin *models.User
+	project, unlisted   *models.Project
+	comment             models.Comment
+	list                models.ProjectList
+}
+
+func newOwnershipFixture(t *testing.T, api *testAPI) ownershipFixture {
+	t.Helper()
+	ctx := context.Background()
+	f := ownershipFixture{
+		owner: api.user("owner", false),
+		other: api.user("other", false),
+		admin: api.user("admin", true),
+	}
+	f.project = api.project(f.owner, "Banco")
+	f.unlisted = api.project(f.owner, "Repisa")
+
+	f.comment = models.Comment{ProjectID: f.project.ID, UserID: f.owner.ID, Content: "Primer comentario"}
+	f.list = models.ProjectList{UserID: f.owner.ID, Name: "Favoritos"}
+	rating := models.Rating{ProjectID: f.project.ID, UserID: f.other.ID, Value: 5}
+	err := api.repos.Comments.Create(ctx, &f.comment)
+	if err == nil {
+		err = api.repos.ProjectLists.Create(ctx, &f.list)
+	}
+	if err == nil {
+		item := models.ProjectListItem{ProjectListID: f.list.ID, ProjectID: f.project.ID}
+		err = api.repos.ProjectLists.AddItem(ctx, &item)
+	}
+	if err == nil {
+		_, err = api.repos.Ratings.Create(ctx, &rating)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestOwnershipAndErrorStatuses(t *testing.T) {
+	const missing = 999_999
+	tests := []struct {
+		name   string
+		method string
+		path   func(f ownershipFixture) string
+		as     func(f ownershipFixture) *models.User
+		body   any // o func(ownershipFixture) any para bodies con ids del fixture
+		status int
+	}{
+		// proyectos
+		{"create project anonymous", "POST", fixed("/projects"), asAnonymous, models.Project{Title: "x"},
+			http.StatusUnauthorized},
+		{"update project anonymous", "PUT", projectPath, asAnonymous, models.Project{Title: "x"}, http.StatusUnauthorized},
+		{"update project other", "PUT", projectPath, asOther, models.Project{Title: "x"}, http.StatusForbidden},
+		{"update project owner", "PUT", projectPath, asOwner, models.Project{Title: "x"}, http.StatusOK},
+		{"update project admin", "PUT", projectPath, asAdmin, models.Project{Title: "x"}, http.StatusOK},
+		{"update missing project", "PUT", fixed(urlf("/projects/%d", missing)), asOwner, models.Project{},
+			http.StatusNotFound},
+		{"delete project other", "DELETE", projectPath, asOther, nil, http.StatusForbidden},
+		{"delete project owner", "DELETE", projectPath, asOwner, nil, http.StatusOK},
+		{"delete project bad id", "DELETE", fixed("/projects/abc"), asOwner, nil, http.StatusNotFound},
+
+		// usuarios
+		{"update user other", "PUT", ownerPath, asOther, models.User{Username: "x"}, http.StatusForbidden},
+		{"update user self", "PUT", ownerPath, asOwner, models.User{Username: "x"}, http.StatusOK},
+		{"delete user anonymous", "DELETE", ownerPath, asAnonymous, nil, http.StatusUnauthorized},
+		{"delete user admin", "DELETE", ownerPath, asAdmin, nil, http.StatusOK},
+		{"update missing user", "PUT", fixed(urlf("/users/%d", missing)), asAdmin, models.User{},
+			http.StatusNotFound},
+
+		// comentarios
+		{"delete comment other", "DELETE", commentPath, asOther, nil, http.StatusForbidden},
+		{"delete comment admin", "DELETE", commentPath, asAdmin, nil, http.StatusOK},
+		{"delete missing comment", "DELETE", fixed(urlf("/comments/%d", missing)), asAdmin, nil,
+			http.StatusNotFound},
+
+		// valoraciones
+		{"rate twice", "POST", ratingsPath, asOther, models.Rating{Value: 3}, http.StatusConflict},
+		{"rate missing project", "POST", fixed(urlf("/projects/%d/ratings", missing)), asOther,
+			models.Rating{Value: 3}, http.StatusNotFound},
+		{"rate anonymous", "POST", ratingsPath, asAnonymous, models.Rating{Value: 3}, http.StatusUnauthorized},
+		{"update rating without one", "PUT", ratingsPath, asOwner, models.Rating{Value: 3}, http.StatusNotFound},
+		{"update own rating", "PUT", ratingsPath, asOther, models.Rating{Value: 3}, http.StatusOK},
+		{"delete rating without one", "DELETE", ratingsPath, asAdmin, nil, http.StatusNotFound},
+
+		// listas
+		{"update list other", "PUT", listPath, asOther, models.ProjectList{Name: "x"}, http.StatusForbidden},
+		{"update list admin", "PUT", listPath, asAdmin, models.ProjectList{Name: "x"}, http.StatusOK},
+		{"delete list anonymous", "DELETE", listPath, asAnonymous, nil, http.StatusUnauthorized},
+		{"add to list other", "POST", listItemsPath, asOther, nil, http.StatusForbidden},
+		{"add to list twice", "POST", listItemsPath, asOwner, func(f ownershipFixture) any {
+			return models.ProjectListItem{ProjectID: f.project.ID}
+		}, http.StatusConflict},
+		{"add missing project to list", "POST", listItemsPath, asOwner,
+			models.ProjectListItem{ProjectID: missing}, http.StatusNotFound},
+		{"remove project not in list", "DELETE", func(f ownershipFixture) string {
+			return urlf("/project-lists/%d/projects/%d", f.list.ID, f.unlisted.ID)
+		}, asOwner, nil, http.StatusNotFound},
+		{"remove from list other", "DELETE", func(f ownershipFixture) string {
+			return urlf("/project-lists/%d/projects/%d", f.list.ID, f.project.ID)
+		}, asOther, nil, http.StatusForbidden},
+		{"get missing list", "GET", fixed(urlf("/project-lists/%d", missing)), asAnonymous, nil,
+			http.StatusNotFound},
+
+		// fotos de perfil
+		{"add profile picture not admin", "POST", fixed("/profile-pictures"), asOwner,
+			models.ProfilePicture{Referenced: "https://example.com/a.png"}, http.StatusForbidden},
+		{"retire missing profile picture", "DELETE", fixed(urlf("/profile-pictures/%d", missing)), asAdmin,
+			nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			f := newOwnershipFixture(t, api)
+			body := tt.body
+			if fn, ok := body.(func(ownershipFixture) any); ok {
+				body = fn(f)
+			}
+			api.expect(tt.status, tt.method, tt.path(f), tt.as(f), body)
+		})
+	}
+}
+
+func fixed(p string) func(ownershipFixture) string { return func(ownershipFixture) string { return p } }
+
+func projectPath(f ownershipFixture) string   { return urlf("/projects/%d", f.project.ID) }
+func ownerPath(f ownershipFixture) string     { return urlf("/users/%d", f.owner.ID) }
+func commentPath(f ownershipFixture) string   { return urlf("/comments/%d", f.comment.ID) }
+func ratingsPath(f ownershipFixture) string   { return urlf("/projects/%d/ratings", f.project.ID) }
+func listPath(f ownershipFixture) string      { return urlf("/project-lists/%d", f.list.ID) }
+func listItemsPath(f ownershipFixture) string { return urlf("/project-lists/%d/projects", f.list.ID) }
+
+func asAnonymous(ownershipFixture) *models.User { return nil }
+func asOwner(f ownershipFixture) *models.User   { return f.owner }
+func asOther(f ownershipFixture) *models.User   { return f.other }
+func asAdmin(f ownershipFixture) *models.User   { return f.admin }
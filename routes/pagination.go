@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+// Tamaños de pagina de todos los listados
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// projectSortKeys son las claves de orden comunes a los listados de proyectos
+var projectSortKeys = []pagination.SortKey{
+	{Name: "created_at", Kind: pagination.Time, Desc: true},
+	{Name: "average_rating", Kind: pagination.Float, Desc: true},
+	{Name: "rating_count", Kind: pagination.Int, Desc: true},
+	{Name: "time_to_build", Kind: pagination.Int},
+}
+
+var (
+	userProjectsPage = pagination.Spec{
+		Scope: "user_projects", Keys: projectSortKeys, DefaultSort: "created_at",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	listProjectsPage = pagination.Spec{
+		Scope: "list_projects", Keys: projectSortKeys, DefaultSort: "created_at",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	searchPage = pagination.Spec{
+		Scope: "search",
+		Keys: append(append([]pagination.SortKey{}, projectSortKeys...),
+			pagination.SortKey{Name: "bayesian_rating", Kind: pagination.Float, Desc: true}),
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	// textSearchPage es searchPage cuando hay q: suma relevance y la usa por defecto
+	textSearchPage = pagination.Spec{
+		Scope: "text_search",
+		Keys: append(append([]pagination.SortKey{}, searchPage.Keys...),
+			pagination.SortKey{Name: "relevance", Kind: pagination.Float, Desc: true}),
+		DefaultSort:  "relevance",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	commentsPage = pagination.Spec{
+		Scope: "comments",
+		Keys: []pagination.SortKey{
+			{Name: "created_at", Kind: pagination.Time},
+			{Name: "rating", Kind: pagination.Int, Desc: true},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	ratingsPage = pagination.Spec{
+		Scope: "ratings",
+		Keys: []pagination.SortKey{
+			{Name: "created_at", Kind: pagination.Time, Desc: true},
+			{Name: "value", Kind: pagination.Int, Desc: true},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	projectListsPage = pagination.Spec{
+		Scope: "project_lists",
+		Keys: []pagination.SortKey{
+			{Name: "created_at", Kind: pagination.Time, Desc: true},
+			{Name: "name", Kind: pagination.String},
+		},
+		DefaultSort:  "created_at",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	pricesPage = pagination.Spec{
+		Scope: "prices",
+		Keys: []pagination.SortKey{
+			{Name: "effective_date", Kind: pagination.Time, Desc: true},
+			{Name: "material", Kind: pagination.String},
+		},
+		DefaultSort:  "effective_date",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	// buildablePage ordena por defecto de mas a menos construible
+	buildablePage = pagination.Spec{
+		Scope: "buildable",
+		Keys: append([]pagination.SortKey{{Name: "missing", Kind: pagination.Float}},
+			projectSortKeys...),
+		DefaultSort:  "missing",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+	revisionsPage = pagination.Spec{
+		Scope:        "revisions",
+		Keys:         []pagination.SortKey{{Name: "number", Kind: pagination.Int, Desc: true}},
+		DefaultSort:  "number",
+		DefaultLimit: defaultPageSize, MaxLimit: maxPageSize,
+	}
+)
+
+// parsePage lee los parametros de paginacion y responde 400 si son invalidos
+func (h *Handler) parsePage(w http.ResponseWriter, r *http.Request, spec pagination.Spec) (pagination.Request, bool) {
+	page, err := h.Cursors.Parse(r.URL.Query(), spec)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+		return pagination.Request{}, false
+	}
+	return page, true
+}
+
+// writePage responde con el sobre de paginacion
+func writePage[T any](w http.ResponseWriter, page pagination.Page[T]) {
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// projectKey devuelve la posicion de un proyecto segun el orden pedido. El puntaje bayesiano y la
+// relevancia tienen que estar ya calculados en el proyecto.
+func projectKey(order pagination.Order) func(models.Project) pagination.Keyset {
+	return func(p models.Project) pagination.Keyset {
+		var value any
+		switch order.Key {
+		case "average_rating":
+			value = p.AverageRating
+		case "rating_count":
+			value = p.RatingCount
+		case "time_to_build":
+			value = p.TimeToBuild
+		case "bayesian_rating":
+			value = p.BayesianScore
+		case "relevance":
+			value = p.Search.Rank
+		default:
+			value = p.CreatedAt
+		}
+		return pagination.Keyset{Value: value, ID: p.ID}
+	}
+}
+
+func buildableKey(order pagination.Order) func(buildableProject) pagination.Keyset {
+	key := projectKey(order)
+	return func(b buildableProject) pagination.Keyset {
+		if order.Key == "missing" {
+			return pagination.Keyset{Value: b.rank, ID: b.Project.ID}
+		}
+		return key(b.Project)
+	}
+}
+
+func commentKey(order pagination.Order) func(models.Comment) pagination.Keyset {
+	return func(c models.Comment) pagination.Keyset {
+		if order.Key == "rating" {
+			return pagination.Keyset{Value: c.Rating, ID: c.ID}
+		}
+		return pagination.Keyset{Value: c.CreatedAt, ID: c.ID}
+	}
+}
+
+func ratingKey(order pagination.Order) func(models.Rating) pagination.Keyset {
+	return func(rt models.Rating) pagination.Keyset {
+		if order.Key == "value" {
+			return pagination.Keyset{Value: int64(rt.Value), ID: rt.ID}
+		}
+		return pagination.Keyset{Value: rt.CreatedAt, ID: rt.ID}
+	}
+}
+
+func projectListKey(order pagination.Order) func(models.ProjectList) pagination.Keyset {
+	return func(l models.ProjectList) pagination.Keyset {
+		if order.Key == "name" {
+			return pagination.Keyset{Value: l.Name, ID: l.ID}
+		}
+		return pagination.Keyset{Value: l.CreatedAt, ID: l.ID}
+	}
+}
+
+func priceKey(order pagination.Order) func(models.MaterialPrice) pagination.Keyset {
+	return func(p models.MaterialPrice) pagination.Keyset {
+		if order.Key == "material" {
+			return pagination.Keyset{Value: p.Material, ID: p.ID}
+		}
+		return pagination.Keyset{Value: p.EffectiveDate, ID: p.ID}
+	}
+}
@@ -0,0 +1,340 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/carpentry-hub/woodys-backend/cutlist"
+	"github.com/carpentry-hub/woodys-backend/cutlist/optimize"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/policies"
+)
+
+// Limites de la lista de piezas de un proyecto y del optimizador de cortes
+const (
+	maxProjectParts  = 200
+	maxPartQuantity  = 1000
+	maxPartName      = 100
+	maxPartDimension = 10000 // mm
+
+	maxStockSizes      = 10
+	maxOptimizedPieces = 1000
+	defaultKerf        = 3.0  // mm, una hoja de sierra comun
+	maxKerf            = 20.0 // mm
+)
+
+// GetProjectParts obtiene la lista de piezas de un proyecto - Requiere id; units=metric|imperial
+func (h *Handler) GetProjectParts(w http.ResponseWriter, r *http.Request) {
+	project, units, ok := h.partsRequest(w, r)
+	if !ok {
+		return
+	}
+	parts, err := h.Parts.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching parts", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]cutlist.Part, 0, len(parts))
+	for _, part := range parts {
+		items = append(items, cutlist.NewPart(part, units))
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"units": units, "items": items}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PutProjectParts reemplaza la lista de piezas de un proyecto - Requiere id, solo el dueño. Las
+// medidas se leen en las unidades de units (mm o pulgadas) y cada material tiene que ser uno de los
+// materiales del proyecto.
+func (h *Handler) PutProjectParts(w http.ResponseWriter, r *http.Request) {
+	project, units, ok := h.partsRequest(w, r)
+	if !ok {
+		return
+	}
+	if !authorize(w, policies.ManageProject(currentUser(r), project)) {
+		return
+	}
+
+	var body struct {
+		Parts []cutlist.Part `json:"parts"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	if len(body.Parts) > maxProjectParts {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": fmt.Sprintf("a project cannot have more than %d parts", maxProjectParts),
+		})
+		return
+	}
+
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+	errs := filterErrors{}
+	parts := make([]models.ProjectPart, 0, len(body.Parts))
+	for i, input := range body.Parts {
+		part := input.Model(units)
+		field := fmt.Sprintf("parts[%d]", i)
+		part.Name = strings.TrimSpace(part.Name)
+		if part.Grain == "" {
+			part.Grain = models.GrainLength
+		}
+		if slug, ok := index.Resolve(models.TaxonomyMaterial, part.Material); ok {
+			part.Material = slug
+		}
+
+		switch {
+		case part.Name == "" || utf8.RuneCountInString(part.Name) > maxPartName:
+			errs.add(field, "name must have between 1 and %d characters", maxPartName)
+		case part.Quantity < 1 || part.Quantity > maxPartQuantity:
+			errs.add(field, "quantity must be between 1 and %d", maxPartQuantity)
+		case !validDimension(part.ThicknessMM), !validDimension(part.WidthMM), !validDimension(part.LengthMM):
+			errs.add(field, "thickness, width and length must be greater than 0 and at most %d mm", maxPartDimension)
+		case part.Grain != models.GrainLength && part.Grain != models.GrainWidth && part.Grain != models.GrainAny:
+			errs.add(field, "grain must be length, width or any")
+		case !slices.Contains(project.Materials, part.Material):
+			errs.add(field, "material %q is not one of the project's materials", input.Material)
+		}
+		parts = append(parts, part)
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Invalid parts", "errors": errs})
+		return
+	}
+
+	if err := h.Parts.Replace(r.Context(), project.ID, parts, h.revision(r, nil)); err != nil {
+		log.Printf("Error saving parts of project %d: %v", project.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the parts"})
+		return
+	}
+	items := make([]cutlist.Part, 0, len(parts))
+	for _, part := range parts {
+		items = append(items, cutlist.NewPart(part, units))
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"units": units, "items": items}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// GetProjectCutlist obtiene la lista de corte de un proyecto con el volumen de madera de cada
+// material en pies tablares y metros cubicos - Requiere id; units=metric|imperial
+func (h *Handler) GetProjectCutlist(w http.ResponseWriter, r *http.Request) {
+	project, units, ok := h.partsRequest(w, r)
+	if !ok {
+		return
+	}
+	parts, err := h.Parts.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching parts", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(cutlist.Summarize(parts, units)); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// stockInput es una medida de placa o tabla que se puede comprar, en las unidades del request
+type stockInput struct {
+	Material  string  `json:"material"`
+	Thickness float64 `json:"thickness"`
+	Width     float64 `json:"width"`
+	Length    float64 `json:"length"`
+	Grain     string  `json:"grain"`
+}
+
+// placementOutput es una pieza ubicada en una placa
+type placementOutput struct {
+	PartID  int64   `json:"part_id"`
+	Name    string  `json:"name"`
+	Copy    int     `json:"copy"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+	Width   float64 `json:"width"`
+	Length  float64 `json:"length"`
+	Rotated bool    `json:"rotated"`
+}
+
+// sheetOutput es una pieza de stock del plan de corte
+type sheetOutput struct {
+	Stock      stockInput        `json:"stock"`
+	Waste      float64           `json:"waste"`
+	Placements []placementOutput `json:"placements"`
+}
+
+// OptimizeProjectCutlist acomoda las piezas del proyecto en las placas o tablas indicadas usando la
+// menor cantidad posible - Requiere id y {"stock": [...], "kerf": 3}; units=metric|imperial
+func (h *Handler) OptimizeProjectCutlist(w http.ResponseWriter, r *http.Request) {
+	project, units, ok := h.partsRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Kerf  *float64     `json:"kerf"`
+		Stock []stockInput `json:"stock"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	kerf := defaultKerf
+	if body.Kerf != nil {
+		kerf = cutlist.ToMM(*body.Kerf, units)
+	}
+
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+	errs := filterErrors{}
+	if kerf < 0 || kerf > maxKerf {
+		errs.add("kerf", "kerf must be between 0 and %g mm", maxKerf)
+	}
+	if len(body.Stock) == 0 || len(body.Stock) > maxStockSizes {
+		errs.add("stock", "stock must have between 1 and %d sizes", maxStockSizes)
+	}
+	stock := make([]optimize.Stock, 0, len(body.Stock))
+	for i, input := range body.Stock {
+		s := optimize.Stock{
+			Material:  input.Material,
+			Thickness: cutlist.ToMM(input.Thickness, units),
+			Width:     cutlist.ToMM(input.Width, units),
+			Length:    cutlist.ToMM(input.Length, units),
+			Grain:     input.Grain,
+		}
+		if slug, ok := index.Resolve(models.TaxonomyMaterial, s.Material); ok {
+			s.Material = slug
+		}
+		if s.Grain == "" {
+			s.Grain = optimize.GrainLength
+		}
+		switch {
+		case s.Material == "":
+			errs.add(fmt.Sprintf("stock[%d]", i), "material is required")
+		case s.Thickness < 0 || s.Thickness > maxPartDimension || !validDimension(s.Width) || !validDimension(s.Length):
+			errs.add(fmt.Sprintf("stock[%d]", i), "width and length must be greater than 0 and at most %d mm", maxPartDimension)
+		case s.Grain != optimize.GrainLength && s.Grain != optimize.GrainAny:
+			errs.add(fmt.Sprintf("stock[%d]", i), "grain must be length or any")
+		}
+		stock = append(stock, s)
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Invalid stock", "errors": errs})
+		return
+	}
+
+	saved, err := h.Parts.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching parts", http.StatusInternalServerError)
+		return
+	}
+	parts := make([]optimize.Part, 0, len(saved))
+	pieces := 0
+	for _, part := range saved {
+		parts = append(parts, optimize.Part{
+			Name: part.Name, Material: part.Material, Quantity: part.Quantity,
+			Thickness: part.ThicknessMM, Width: part.WidthMM, Length: part.LengthMM, Grain: part.Grain,
+		})
+		pieces += part.Quantity
+	}
+	if pieces > maxOptimizedPieces {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": fmt.Sprintf("the optimizer handles up to %d pieces", maxOptimizedPieces),
+		})
+		return
+	}
+
+	result, err := optimize.Optimize(parts, stock, kerf)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+		return
+	}
+
+	stockOutput := func(i int) stockInput {
+		s := stock[i]
+		return stockInput{
+			Material:  s.Material,
+			Thickness: cutlist.FromMM(s.Thickness, units),
+			Width:     cutlist.FromMM(s.Width, units),
+			Length:    cutlist.FromMM(s.Length, units),
+			Grain:     s.Grain,
+		}
+	}
+	sheets := make([]sheetOutput, 0, len(result.Sheets))
+	for _, sheet := range result.Sheets {
+		out := sheetOutput{Stock: stockOutput(sheet.Stock), Waste: sheet.Waste}
+		for _, p := range sheet.Placements {
+			out.Placements = append(out.Placements, placementOutput{
+				PartID: saved[p.Part].ID, Name: p.Name, Copy: p.Copy,
+				X: cutlist.FromMM(p.X, units), Y: cutlist.FromMM(p.Y, units),
+				Width: cutlist.FromMM(p.Width, units), Length: cutlist.FromMM(p.Length, units),
+				Rotated: p.Rotated,
+			})
+		}
+		sheets = append(sheets, out)
+	}
+	purchases := make([]map[string]any, 0, len(result.Purchases))
+	for _, p := range result.Purchases {
+		purchases = append(purchases, map[string]any{"stock": stockOutput(p.Stock), "count": p.Count})
+	}
+	unplaced := make([]map[string]any, 0, len(result.Unplaced))
+	for _, u := range result.Unplaced {
+		unplaced = append(unplaced, map[string]any{"part_id": saved[u.Part].ID, "name": u.Name, "copies": u.Copies})
+	}
+
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"units":     units,
+		"kerf":      cutlist.FromMM(kerf, units),
+		"sheets":    sheets,
+		"purchases": purchases,
+		"unplaced":  unplaced,
+		"waste":     result.Waste,
+	}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// partsRequest busca el proyecto de la ruta y lee el parametro units
+func (h *Handler) partsRequest(w http.ResponseWriter, r *http.Request) (*models.Project, cutlist.Units, bool) {
+	id, err := pathID(r, "id")
+	var project *models.Project
+	if err == nil {
+		project, err = h.Projects.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+		return nil, "", false
+	}
+	units, ok := cutlist.ParseUnits(r.URL.Query().Get("units"))
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "units must be metric or imperial"})
+		return nil, "", false
+	}
+	return project, units, true
+}
+
+func validDimension(mm float64) bool {
+	return mm > 0 && mm <= maxPartDimension
+}
@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/cutlist"
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+func TestCutlistUnits(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	api.terms(models.TaxonomyTerm{Kind: models.TaxonomyMaterial, Slug: "pine", LabelES: "Pino", LabelEN: "Pine"})
+	project := decode[models.Project](t, api.expect(http.StatusOK, "POST", "/projects", owner, models.Project{
+		Title: "Estante", Materials: []string{"pine"}, IsPublic: true,
+	}))
+	// cargadas en pulgadas: 3/4" x 7 7/8" x 35 7/16"
+	api.expect(http.StatusOK, "PUT", urlf("/projects/%d/parts?units=imperial", project.ID), owner, map[string]any{
+		"parts": []cutlist.Part{{Name: "lateral", Quantity: 2, Material: "Pino", Thickness: 0.75, Width: 7.875,
+			Length: 35.4375}},
+	})
+
+	tests := []struct {
+		query string
+		want  cutlist.Part
+	}{
+		{"", cutlist.Part{Thickness: 19.05, Width: 200.02, Length: 900.11}},
+		{"?units=metric", cutlist.Part{Thickness: 19.05, Width: 200.02, Length: 900.11}},
+		{"?units=imperial", cutlist.Part{Thickness: 0.75, Width: 7.875, Length: 35.438}},
+	}
+	for _, tt := range tests {
+		summary := decode[cutlist.Summary](t, api.expect(http.StatusOK, "GET",
+			urlf("/projects/%d/cutlist%s", project.ID, tt.query), nil, nil))
+		if len(summary.Parts) != 1 {
+			t.Fatalf("%q: parts = %+v, want one", tt.query, summary.Parts)
+		}
+		got := summary.Parts[0]
+		if got.Material != "pine" || got.Thickness != tt.want.Thickness || got.Width != tt.want.Width ||
+			got.Length != tt.want.Length {
+			t.Errorf("%q: part = %+v, want pine %v x %v x %v", tt.query, got, tt.want.Thickness, tt.want.Width,
+				tt.want.Length)
+		}
+		// el volumen no depende de las unidades de las piezas
+		want := []cutlist.MaterialTotal{{Material: "pine", Pieces: 2,
+			Volume: cutlist.Volume{BoardFeet: 2.907, CubicMetres: 0.00686}}}
+		if len(summary.Materials) != 1 || summary.Materials[0] != want[0] || summary.Total != want[0].Volume {
+			t.Errorf("%q: materials = %+v, total = %+v; want %+v", tt.query, summary.Materials, summary.Total, want)
+		}
+	}
+
+	api.expect(http.StatusBadRequest, "GET", urlf("/projects/%d/cutlist?units=cm", project.ID), nil, nil)
+	api.expect(http.StatusNotFound, "GET", "/projects/999/cutlist", nil, nil)
+}
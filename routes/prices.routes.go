@@ -0,0 +1,356 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/pricing"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// DefaultPriceCurrency es la moneda del presupuesto de un proyecto cuando no se pide otra
+const DefaultPriceCurrency = "USD"
+
+// Limites de la importacion de listas de precios y del presupuesto
+const (
+	maxPriceSheetBytes = 5 << 20
+	defaultWaste       = 10 // %
+	maxWaste           = 100
+)
+
+// priceInput es un precio como lo manda un administrador; la fecha va como YYYY-MM-DD
+type priceInput struct {
+	Material      string  `json:"material"`
+	Unit          string  `json:"unit"`
+	Price         float64 `json:"price"`
+	Currency      string  `json:"currency"`
+	Region        string  `json:"region"`
+	EffectiveDate string  `json:"effective_date"`
+	Supplier      string  `json:"supplier"`
+}
+
+// GetPrices lista el catalogo de precios - material, unit, currency y region filtran; region vacia
+// es el precio general
+func (h *Handler) GetPrices(w http.ResponseWriter, r *http.Request) {
+	page, ok := h.parsePage(w, r, pricesPage)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	filter := repositories.PriceFilter{
+		Unit:     query.Get("unit"),
+		Currency: strings.ToUpper(query.Get("currency")),
+	}
+	if material := query.Get("material"); material != "" {
+		index, err := h.taxonomyIndex(r.Context())
+		if err != nil {
+			http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+			return
+		}
+		filter.Material = material
+		if slug, ok := index.Resolve(models.TaxonomyMaterial, material); ok {
+			filter.Material = slug
+		}
+	}
+	if query.Has("region") {
+		region := strings.ToUpper(query.Get("region"))
+		filter.Region = &region
+	}
+
+	prices, total, err := h.Prices.List(r.Context(), filter, page)
+	if err != nil {
+		http.Error(w, "Error fetching prices", http.StatusInternalServerError)
+		return
+	}
+	writePage(w, pagination.NewPage(h.Cursors, page, prices, total, priceKey(page.Order)))
+}
+
+// PostPrice agrega un precio al catalogo - Solo administradores
+func (h *Handler) PostPrice(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManagePrices(currentUser(r))) {
+		return
+	}
+	var input priceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	price, ok := h.priceFromInput(r.Context(), w, input)
+	if !ok {
+		return
+	}
+
+	if err := h.Prices.Create(r.Context(), &price); err != nil {
+		writePriceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&price); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PutPrice reemplaza un precio del catalogo - Requiere id, solo administradores
+func (h *Handler) PutPrice(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManagePrices(currentUser(r))) {
+		return
+	}
+	id, err := pathID(r, "id")
+	if err == nil {
+		_, err = h.Prices.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Price not found"})
+		return
+	}
+
+	var input priceInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	price, ok := h.priceFromInput(r.Context(), w, input)
+	if !ok {
+		return
+	}
+	price.ID = id
+
+	if err := h.Prices.Update(r.Context(), &price); err != nil {
+		writePriceError(w, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&price); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// DeletePrice borra un precio del catalogo - Requiere id, solo administradores
+func (h *Handler) DeletePrice(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManagePrices(currentUser(r))) {
+		return
+	}
+	id, err := pathID(r, "id")
+	if err != nil {
+		err = repositories.ErrNotFound
+	} else {
+		err = h.Prices.Delete(r.Context(), id)
+	}
+	if err != nil {
+		writePriceError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "Price deleted successfully"})
+}
+
+// ImportPrices carga una lista de precios en CSV (ver pricing.ParseCSV) - Solo administradores. Es
+// todo o nada: si alguna linea tiene problemas no se guarda ninguna y se devuelven todos los
+// problemas por linea. Los precios que ya estaban para el mismo material, unidad, moneda, region y
+// fecha se actualizan.
+func (h *Handler) ImportPrices(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManagePrices(currentUser(r))) {
+		return
+	}
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+
+	rows, errs := pricing.ParseCSV(http.MaxBytesReader(w, r.Body, maxPriceSheetBytes))
+	prices := make([]models.MaterialPrice, 0, len(rows))
+	seen := map[models.MaterialPrice]int{}
+	for _, row := range rows {
+		slugs, unknown := index.Canonicalize(models.TaxonomyMaterial, []string{row.Price.Material})
+		if len(unknown) > 0 || len(slugs) == 0 {
+			errs = append(errs, pricing.RowError{Line: row.Line, Message: "unknown material: " + row.Price.Material})
+			continue
+		}
+		row.Price.Material = slugs[0]
+
+		key := models.MaterialPrice{
+			Material: row.Price.Material, Unit: row.Price.Unit, Currency: row.Price.Currency,
+			Region: row.Price.Region, EffectiveDate: row.Price.EffectiveDate,
+		}
+		if line, ok := seen[key]; ok {
+			errs = append(errs, pricing.RowError{Line: row.Line, Message: fmt.Sprintf("repeats line %d", line)})
+			continue
+		}
+		seen[key] = row.Line
+		prices = append(prices, row.Price)
+	}
+	if len(errs) > 0 {
+		slices.SortStableFunc(errs, func(a, b pricing.RowError) int { return a.Line - b.Line })
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Invalid price list", "errors": errs})
+		return
+	}
+	if len(prices) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "The price list has no prices"})
+		return
+	}
+
+	if err := h.Prices.Import(r.Context(), prices); err != nil {
+		log.Printf("Error importing prices: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to import the price list"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"message": "Price list imported successfully", "imported": len(prices)})
+}
+
+// GetProjectEstimate estima el costo de los materiales de un proyecto - Requiere id.
+//
+//	currency   moneda de los precios (por defecto PriceCurrency del handler)
+//	region     region de los precios; si no hay precio regional se usa el general
+//	date       fecha de los precios, YYYY-MM-DD (por defecto hoy)
+//	waste      desperdicio que se suma a lo medido sobre las piezas, en % (0 a 100, por defecto 10)
+func (h *Handler) GetProjectEstimate(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	var project *models.Project
+	if err == nil {
+		project, err = h.Projects.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+		return
+	}
+
+	query := r.URL.Query()
+	errs := filterErrors{}
+	opts := pricing.Options{
+		Currency: strings.ToUpper(query.Get("currency")),
+		Region:   strings.ToUpper(query.Get("region")),
+		Date:     time.Now().UTC().Truncate(24 * time.Hour),
+	}
+	if opts.Currency == "" {
+		opts.Currency = h.PriceCurrency
+	}
+	if !pricing.ValidCurrency(opts.Currency) {
+		errs.add("currency", "currency must be an ISO 4217 code such as ARS or USD")
+	}
+	if !pricing.ValidRegion(opts.Region) {
+		errs.add("region", "region must be an ISO 3166 code such as AR or AR-B")
+	}
+	if raw := query.Get("date"); raw != "" {
+		if opts.Date, err = time.Parse(time.DateOnly, raw); err != nil {
+			errs.add("date", "date must be YYYY-MM-DD")
+		}
+	}
+	waste, ok := queryInt(w, query.Get("waste"), "waste", defaultWaste, 0, maxWaste)
+	if !ok {
+		return
+	}
+	opts.Waste = float64(waste) / 100
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Invalid estimate parameters", "errors": errs})
+		return
+	}
+
+	parts, err := h.Parts.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching parts", http.StatusInternalServerError)
+		return
+	}
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+	ancestors := func(material string) []string { return index.Ancestors(models.TaxonomyMaterial, material) }
+
+	// se piden tambien los precios de los padres, que se usan si el material no tiene precio propio
+	var materials []string
+	for _, material := range pricing.Materials(*project, parts) {
+		materials = append(append(materials, material), ancestors(material)...)
+	}
+	regions := []string{""}
+	if opts.Region != "" {
+		regions = append(regions, opts.Region)
+	}
+	prices, err := h.Prices.ListEffective(r.Context(), materials, opts.Currency, regions, opts.Date)
+	if err != nil {
+		http.Error(w, "Error fetching prices", http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(pricing.Estimate(*project, parts, prices, ancestors, opts)); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// priceFromInput arma el precio de un request: pasa el material a su slug, moneda y region a
+// mayusculas y lee la fecha. Responde 400 si algo no es valido.
+func (h *Handler) priceFromInput(ctx context.Context, w http.ResponseWriter, input priceInput) (models.MaterialPrice, bool) {
+	price := models.MaterialPrice{
+		Material: strings.TrimSpace(input.Material),
+		Unit:     input.Unit,
+		Price:    input.Price,
+		Currency: strings.ToUpper(strings.TrimSpace(input.Currency)),
+		Region:   strings.ToUpper(strings.TrimSpace(input.Region)),
+		Supplier: strings.TrimSpace(input.Supplier),
+	}
+
+	message := ""
+	if input.EffectiveDate != "" {
+		date, err := time.Parse(time.DateOnly, input.EffectiveDate)
+		if err != nil {
+			message = "effective_date must be YYYY-MM-DD"
+		}
+		price.EffectiveDate = date
+	}
+	if message == "" {
+		message = pricing.Validate(price)
+	}
+	if message == "" {
+		index, err := h.taxonomyIndex(ctx)
+		if err != nil {
+			http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+			return price, false
+		}
+		if slugs, unknown := index.Canonicalize(models.TaxonomyMaterial, []string{price.Material}); len(unknown) > 0 || len(slugs) == 0 {
+			message = "unknown material: " + price.Material
+		} else {
+			price.Material = slugs[0]
+		}
+	}
+	if message != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": message})
+		return price, false
+	}
+	return price, true
+}
+
+func writePriceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrConflict):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "A price for that material, unit, currency, region and date already exists",
+		})
+	case errors.Is(err, repositories.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Price not found"})
+	default:
+		log.Printf("Error saving price: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the price"})
+	}
+}
@@ -1,42 +1,245 @@
 package routes
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 
-	"github.com/carpentry-hub/woodys-backend/db"
 	"github.com/carpentry-hub/woodys-backend/models"
-	"github.com/gorilla/mux"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/uploads"
 )
 
 // GetProfilePictures obtiene todas las fotos de perfil
-func GetProfilePictures(w http.ResponseWriter, r *http.Request) {
-	var profilePictures []models.ProfilePicture
-    if err := db.DB.Find(&profilePictures).Error; err != nil {
-        log.Printf("Error fetching profile pictures: %v", err)
-        w.WriteHeader(http.StatusInternalServerError)
-        json.NewEncoder(w).Encode(map[string]string{"error": "Error fetching profile pictures"})
-        return
-    }
-    if err := json.NewEncoder(w).Encode(&profilePictures); err != nil {
-        log.Fatalf("Failed to encode profile pictures: %v", err)
-    } 
+func (h *Handler) GetProfilePictures(w http.ResponseWriter, r *http.Request) {
+	profilePictures, err := h.ProfilePictures.List(r.Context())
+	if err != nil {
+		log.Printf("Error fetching profile pictures: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Error fetching profile pictures"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&profilePictures); err != nil {
+		log.Fatalf("Failed to encode profile pictures: %v", err)
+	}
 }
 
-// GetProfilepicture obtiene una foto de perfil segun id
-func GetProfilePictureByID(w http.ResponseWriter, r *http.Request) {
-    var picture models.ProfilePicture
-    params := mux.Vars(r)
-    db.DB.First(&picture, params["id"])
-    if picture.ID == 0 {
-        w.WriteHeader(http.StatusNotFound)
-        if _, err := w.Write([]byte("404: Profile Picture Not Found")); err != nil {
-			log.Fatalf("Failed to write Response: %v", err)
+// GetProfilePictureByID devuelve la imagen de una foto de perfil por defecto - Requiere id. Si es
+// un upload o una imagen data: se manda la imagen; si es una url se redirige a ella. Con
+// Accept: application/json devuelve la foto como JSON. Las fotos no se editan, solo se retiran, asi
+// que cualquiera de las respuestas se puede guardar en cache para siempre.
+func (h *Handler) GetProfilePictureByID(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	var picture *models.ProfilePicture
+	if err == nil {
+		picture, err = h.ProfilePictures.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		writeMessage(w, http.StatusNotFound, "Profile picture not found")
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Vary", "Accept")
+		if err := json.NewEncoder(w).Encode(picture); err != nil {
+			log.Fatalf("Failed to encode json: %v", err)
+		}
+		return
+	}
+	w.Header().Set("Vary", "Accept")
+
+	if uploadID, ok := h.uploadID(picture.Referenced); ok {
+		upload, err := h.Uploads.FindByID(r.Context(), uploadID)
+		if errors.Is(err, repositories.ErrNotFound) {
+			writeMessage(w, http.StatusNotFound, "The profile picture image no longer exists")
+			return
+		}
+		if err != nil {
+			http.Error(w, "Error fetching profile picture", http.StatusInternalServerError)
+			return
+		}
+		h.serveUploadFile(w, r, upload.ID, uploads.OriginalName, upload.ContentType, upload.Size, upload.SHA256)
+		return
+	}
+
+	if data, contentType, ok := dataURIImage(picture.Referenced); ok {
+		sum := sha256.Sum256(data)
+		w.Header().Set("Cache-Control", immutableCache)
+		if notModified(w, r, `"`+hex.EncodeToString(sum[:])+`"`) {
+			return
 		}
-    } else {
-		if _, err := w.Write([]byte(picture.Referenced)); err != nil {
-			log.Fatalf("Failed to write response: %v", err)
+		if err := writeImage(w, contentType, int64(len(data)), bytes.NewReader(data)); err != nil {
+			log.Printf("Error writing profile picture %d: %v", picture.ID, err)
 		}
-    }
-}
\ No newline at end of file
+		return
+	}
+
+	target, err := url.Parse(picture.Referenced)
+	if err != nil || !(target.Scheme == "https" || target.Scheme == "http" ||
+		target.Scheme == "" && target.Host == "" && strings.HasPrefix(target.Path, "/")) {
+		writeMessage(w, http.StatusNotFound, "The profile picture has no image")
+		return
+	}
+	sum := sha256.Sum256([]byte(picture.Referenced))
+	w.Header().Set("Cache-Control", immutableCache)
+	if notModified(w, r, `"`+hex.EncodeToString(sum[:])+`"`) {
+		return
+	}
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// dataURIImage decodifica una url data:image/...;base64. El tipo se toma del contenido, no de lo
+// que declara la url.
+func dataURIImage(value string) ([]byte, string, bool) {
+	header, payload, ok := strings.Cut(value, ",")
+	if !ok || !strings.HasPrefix(header, "data:image/") || !strings.HasSuffix(header, ";base64") {
+		return nil, "", false
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(data) == 0 {
+		return nil, "", false
+	}
+	contentType := http.DetectContentType(data)
+	if contentType != uploads.JPEG && contentType != uploads.PNG && contentType != uploads.WebP &&
+		contentType != "image/gif" {
+		return nil, "", false
+	}
+	return data, contentType, true
+}
+
+// PostProfilePicture agrega una foto de perfil por defecto al final - {"referenced": url}, solo admins
+func (h *Handler) PostProfilePicture(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManageProfilePictures(currentUser(r))) {
+		return
+	}
+	var picture models.ProfilePicture
+	if err := json.NewDecoder(r.Body).Decode(&picture); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	picture.Referenced = strings.TrimSpace(picture.Referenced)
+	if picture.Referenced == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{
+			"message": "Invalid profile picture",
+			"errors":  filterErrors{"referenced": "referenced is required"},
+		})
+		return
+	}
+	if err := h.ProfilePictures.Create(r.Context(), &picture); err != nil {
+		log.Printf("Error saving profile picture: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the profile picture"})
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&picture); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// RetireProfilePicture deja de ofrecer una foto de perfil por defecto - Requiere id, solo admins.
+// Los usuarios que la tienen la conservan.
+func (h *Handler) RetireProfilePicture(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManageProfilePictures(currentUser(r))) {
+		return
+	}
+	id, err := pathID(r, "id")
+	if err == nil {
+		err = h.ProfilePictures.Retire(r.Context(), id)
+	} else {
+		err = repositories.ErrNotFound
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Profile picture not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to retire the profile picture"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "Profile picture retired successfully"})
+}
+
+// ReorderProfilePictures cambia el orden de las fotos de perfil por defecto - {"ids": [...]} con
+// todas las que no se retiraron en el nuevo orden, solo admins
+func (h *Handler) ReorderProfilePictures(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManageProfilePictures(currentUser(r))) {
+		return
+	}
+	var body struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+
+	pictures, err := h.ProfilePictures.List(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching profile pictures", http.StatusInternalServerError)
+		return
+	}
+	current := make([]int64, len(pictures))
+	for i, picture := range pictures {
+		current[i] = picture.ID
+	}
+	wanted := slices.Clone(body.IDs)
+	slices.Sort(current)
+	slices.Sort(wanted)
+	if !slices.Equal(current, wanted) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "ids must list every profile picture exactly once"})
+		return
+	}
+
+	if err := h.ProfilePictures.Reorder(r.Context(), body.IDs); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to reorder the profile pictures"})
+		return
+	}
+	pictures, err = h.ProfilePictures.List(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching profile pictures", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"items": pictures}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// validProfilePicture controla que id sea una de las fotos de perfil por defecto que se ofrecen. 0
+// (sin foto) y la que el usuario ya tenia (current) se aceptan siempre. Responde 400 si no.
+func (h *Handler) validProfilePicture(w http.ResponseWriter, r *http.Request, id, current int64) bool {
+	if id == 0 || id == current {
+		return true
+	}
+	picture, err := h.ProfilePictures.FindByID(r.Context(), id)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		http.Error(w, "Error fetching profile picture", http.StatusInternalServerError)
+		return false
+	}
+	if err != nil || picture.RetiredAt != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{
+			"message": "Invalid user",
+			"errors":  filterErrors{"profile_picture": "profile picture does not exist"},
+		})
+		return false
+	}
+	return true
+}
@@ -6,209 +6,173 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"unicode/utf8"
 
-	"github.com/carpentry-hub/woodys-backend/db"
 	"github.com/carpentry-hub/woodys-backend/models"
-	"github.com/gorilla/mux"
-	"github.com/jackc/pgx/v5/pgconn"
-	"gorm.io/gorm"
-)
-
-const (
-    ErrCodeForeignKeyViolation = "23503" // "Not Found"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
 )
 
 // GetUsersProjectLists obtiene todas las listas de un usuario - Requiere user_id
-func GetUsersProjectLists(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	userIDString := params["id"]
-
-	userID, err := strconv.Atoi(userIDString)
+func (h *Handler) GetUsersProjectLists(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "id")
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"message": "User not found"})
 		return
 	}
 
-	var lists []models.ProjectList
-
-	err = db.DB.Model(&models.ProjectList{}).
-		Select("project_lists.*, COUNT(project_list_items.project_id) as project_count").
-		Joins("LEFT JOIN project_list_items ON project_list_items.project_list_id = project_lists.id").
-		Where("project_lists.user_id = ?", userID).
-		Group("project_lists.id").
-		Order("project_lists.created_at DESC").
-		Find(&lists).Error
+	page, ok := h.parsePage(w, r, projectListsPage)
+	if !ok {
+		return
+	}
 
+	lists, total, err := h.ProjectLists.ListByUser(r.Context(), userID, page)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Error fetching Project Lists"})
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&lists); err != nil {
-		log.Fatalf("Failed to Encode json: %v", err)
-	}
+	writePage(w, pagination.NewPage(h.Cursors, page, lists, total, projectListKey(page.Order)))
 }
 
 // GetProjectLists obtiene una lista - Requier id
-func GetProjectLists(w http.ResponseWriter, r *http.Request) {
-	var list models.ProjectList
-	params := mux.Vars(r)
-	db.DB.First(&list, params["id"])
-
-	if list.ID == 0 {
-		w.WriteHeader(http.StatusNotFound)
-		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Project list not found"}); err != nil {
-			log.Fatalf("Failed to write response: %v", err)
-		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&list); err != nil {
-			log.Fatalf("Failed to Encode json: %v", err)
-		}
+func (h *Handler) GetProjectLists(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.findProjectList(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(list); err != nil {
+		log.Fatalf("Failed to Encode json: %v", err)
 	}
 }
 
 // GetProjectsInList obtiene todos los proyectos dentro de una lista especifica
-func GetProjectsInList(w http.ResponseWriter, r *http.Request) {
-    params := mux.Vars(r)
-    listIDStr := params["id"]
-
-    listID, err := strconv.ParseInt(listIDStr, 10, 64)
-    if err != nil {
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Invalid list ID format"})
-        return
-    }
-
-    var items []models.ProjectListItem
-    // Encontrar todos los items que pertenecen a esta lista
-    if err := db.DB.Where("project_list_id = ?", listID).Find(&items).Error; err != nil {
-        w.WriteHeader(http.StatusInternalServerError)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Could not fetch list items"})
-        return
-    }
-
-    if len(items) == 0 {
-        json.NewEncoder(w).Encode([]models.Project{})
-        return
-    }
-
-    // Extraer todos los project_id de esos items
-    var projectIDs []int8
-    for _, item := range items {
-        projectIDs = append(projectIDs, item.ProjectID)
-    }
-
-    // Buscar todos los proyectos que coincidan con esos id
-    var projects []models.Project
-    if err := db.DB.Where("id IN ?", projectIDs).Find(&projects).Error; err != nil {
-        w.WriteHeader(http.StatusInternalServerError)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Could not fetch projects"})
-        return
-    }
-
-    json.NewEncoder(w).Encode(&projects)
+func (h *Handler) GetProjectsInList(w http.ResponseWriter, r *http.Request) {
+	listID, err := pathID(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid list ID format"})
+		return
+	}
+
+	page, ok := h.parsePage(w, r, listProjectsPage)
+	if !ok {
+		return
+	}
+
+	// Buscar los proyectos de la lista, una pagina a la vez
+	projects, total, err := h.Projects.ListByList(r.Context(), listID, page)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Could not fetch projects"})
+		return
+	}
+
+	if err := h.attachImageList(r.Context(), projects); err != nil {
+		log.Printf("Error loading project images: %v", err)
+	}
+	writePage(w, pagination.NewPage(h.Cursors, page, projects, total, projectKey(page.Order)))
 }
 
-// PostProjectLists postea una lista
-func PostProjectLists(w http.ResponseWriter, r *http.Request) {
+// PostProjectLists postea una lista - El user_id es el usuario autenticado
+func (h *Handler) PostProjectLists(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
+		return
+	}
+
 	var list models.ProjectList
 	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
-		log.Fatalf("Failed to Decode json: %v", err)
 		w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
 	}
+	list.UserID = user.ID
 
 	trimmedName := strings.TrimSpace(list.Name)
-    nameLength := utf8.RuneCountInString(trimmedName)
-
-    if nameLength == 0 {
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "name cannot be empty"})
-        return
-    }
+	nameLength := utf8.RuneCountInString(trimmedName)
 
-    if nameLength > 50 {
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "name cannot exceed 50 characters"})
-        return
-    }
+	if nameLength == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "name cannot be empty"})
+		return
+	}
 
-	createdList := db.DB.Create(&list)
-	err := createdList.Error
+	if nameLength > 50 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "name cannot exceed 50 characters"})
+		return
+	}
 
-	if err != nil {
+	if err := h.ProjectLists.Create(r.Context(), &list); err != nil {
 		w.WriteHeader(http.StatusBadRequest) // satatus code 400
 		if _, err := w.Write([]byte(err.Error())); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
 		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&list); err != nil {
-			log.Fatalf("Failed to Encode json: %v", err)
-		}
+		return
+	}
+	if err := json.NewEncoder(w).Encode(&list); err != nil {
+		log.Fatalf("Failed to Encode json: %v", err)
 	}
 }
 
-// AddProjectToList postea un project list item (anadir un proyecto a una lista)
- func AddProjectToList(w http.ResponseWriter, r *http.Request) {
-    var item models.ProjectListItem
-    if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-        log.Fatalf("Failed to Decode json: %v", err)
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
-        return
-    }
-
-
-    createdItem := db.DB.Create(&item)
-    err := createdItem.Error
-    if err != nil {
-        var pgErr *pgconn.PgError
-        if errors.As(err, &pgErr) {
-            switch pgErr.Code {
-            case ErrCodeForeignKeyViolation: // Error para "Not Found"
-                w.WriteHeader(http.StatusNotFound)
-                json.NewEncoder(w).Encode(map[string]string{"message": "Project or List not found"})
-                return
-            case ErrCodeUniqueViolation: // Error para "Duplicate"
-                w.WriteHeader(http.StatusConflict)
-                json.NewEncoder(w).Encode(map[string]string{"message": "Project is already in this list"})
-                return
-            }
-        }
-
-        // Para otros errores
-        w.WriteHeader(http.StatusInternalServerError)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Could not add project to list"})
-        log.Printf("Failed to write response: %v", err)
-        return
-    }
-
-    w.WriteHeader(http.StatusCreated)
-    if err := json.NewEncoder(w).Encode(&item); err != nil {
-        log.Printf("Failed to Encode json: %v", err)
-    }
-
-} 
+// AddProjectToList postea un project list item (anadir un proyecto a una lista) - Requiere id de la lista
+func (h *Handler) AddProjectToList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.findProjectList(w, r, "id")
+	if !ok {
+		return
+	}
 
-// PutProjectLists actualiza una lista - Requiere id
-func PutProjectLists(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
+	if !authorize(w, policies.ManageProjectList(currentUser(r), list)) {
+		return
+	}
 
-	// chequeo que el proyecto ya exista
-	var existing models.ProjectList
-	if err := db.DB.First(&existing, params["id"]).Error; err != nil {
-		w.WriteHeader(http.StatusNotFound) // status code 404
-		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Project list not found"}); err != nil {
-			log.Fatalf("Failed to write response: %v", err)
+	var item models.ProjectListItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	item.ProjectListID = list.ID
+
+	if err := h.ProjectLists.AddItem(r.Context(), &item); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrMissingReference): // Error para "Not Found"
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Project or List not found"})
+		case errors.Is(err, repositories.ErrConflict): // Error para "Duplicate"
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Project is already in this list"})
+		default: // Para otros errores
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Could not add project to list"})
+			log.Printf("Failed to write response: %v", err)
 		}
 		return
 	}
 
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&item); err != nil {
+		log.Printf("Failed to Encode json: %v", err)
+	}
+}
+
+// PutProjectLists actualiza una lista - Requiere id
+func (h *Handler) PutProjectLists(w http.ResponseWriter, r *http.Request) {
+	// chequeo que la lista ya exista
+	existing, ok := h.findProjectList(w, r, "id")
+	if !ok {
+		return
+	}
+
+	if !authorize(w, policies.ManageProjectList(currentUser(r), existing)) {
+		return
+	}
+
 	// leo el updated
 	var updated models.ProjectList
 	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
@@ -224,7 +188,7 @@ func PutProjectLists(w http.ResponseWriter, r *http.Request) {
 	existing.IsPublic = updated.IsPublic
 
 	// guardar en DB
-	if err := db.DB.Save(&existing).Error; err != nil {
+	if err := h.ProjectLists.Update(r.Context(), existing); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save project list"}); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
@@ -232,72 +196,96 @@ func PutProjectLists(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&existing); err != nil {
+	if err := json.NewEncoder(w).Encode(existing); err != nil {
 		log.Fatalf("Failed to Encode json: %v", err)
 	}
 }
 
 // DeleteProjectList borra una lista - Requiere id
-func DeleteProjectList(w http.ResponseWriter, r *http.Request) {
-	var list models.ProjectList
-	params := mux.Vars(r)
-	db.DB.First(&list, params["id"])
+func (h *Handler) DeleteProjectList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.findProjectList(w, r, "id")
+	if !ok {
+		return
+	}
 
-	if list.ID == 0 {
-		w.WriteHeader(http.StatusNotFound) // status code 404
-		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Project list not found"}); err != nil {
-			log.Fatalf("Failed to write response: %v", err)
-		}
-	} else {
-		db.DB.Unscoped().Delete(&list)
+	if !authorize(w, policies.ManageProjectList(currentUser(r), list)) {
+		return
+	}
+
+	if err := h.ProjectLists.Delete(r.Context(), list.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete project list"})
+		return
 	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "Project list deleted successfully"})
 }
 
-// DeleteProjectFromList borra un proyecto de una lista - Requiere id
-func DeleteProjectFromList(w http.ResponseWriter, r *http.Request) {
-	var item models.ProjectListItem
-	params := mux.Vars(r)
-	listIDStr := params["list_id"]
-    projectIDStr := params["project_id"]
-
-	listID, err := strconv.Atoi(listIDStr)
-    if err != nil {
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Invalid list ID format"})
-        return
-    }
-
-	projectID, err := strconv.Atoi(projectIDStr)
-    if err != nil {
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Invalid project ID format"})
-        return
-    }
-
-	result := db.DB.Where("project_list_id = ? AND project_id = ?", listID, projectID).First(&item)
-
-	// Respuestas errores 
-	if result.Error != nil {
-        if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-            w.WriteHeader(http.StatusNotFound)
-            json.NewEncoder(w).Encode(map[string]string{"message": "Project is not in this list"}) // 404 not found
-        } else {
-            w.WriteHeader(http.StatusInternalServerError)
-            json.NewEncoder(w).Encode(map[string]string{"message": "Database error"})
-            log.Printf("DB error finding item: %v", result.Error)
-        }
-        return
-    }
+// DeleteProjectFromList borra un proyecto de una lista - Requiere list_id y project_id
+func (h *Handler) DeleteProjectFromList(w http.ResponseWriter, r *http.Request) {
+	listID, err := pathID(r, "list_id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid list ID format"})
+		return
+	}
+
+	projectID, err := pathID(r, "project_id")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid project ID format"})
+		return
+	}
+
+	list, ok := h.findProjectList(w, r, "list_id")
+	if !ok {
+		return
+	}
+
+	if !authorize(w, policies.ManageProjectList(currentUser(r), list)) {
+		return
+	}
+
+	item, err := h.ProjectLists.FindItem(r.Context(), listID, projectID)
+
+	// Respuestas errores
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Project is not in this list"}) // 404 not found
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Database error"})
+			log.Printf("DB error finding item: %v", err)
+		}
+		return
+	}
 
 	// Eliminar item
-	if err := db.DB.Unscoped().Delete(&item).Error; err != nil {
-        w.WriteHeader(http.StatusInternalServerError)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete item from list"}) // Respuesta de error
-        log.Printf("DB error deleting item: %v", err)
-        return
-    }
-
-    // Respuesta de exito
-    w.WriteHeader(http.StatusOK)
-    json.NewEncoder(w).Encode(map[string]string{"message": "Project removed from list successfully"})
+	if err := h.ProjectLists.DeleteItem(r.Context(), item.ID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete item from list"}) // Respuesta de error
+		log.Printf("DB error deleting item: %v", err)
+		return
+	}
+
+	// Respuesta de exito
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Project removed from list successfully"})
+}
+
+// findProjectList busca la lista del parametro de ruta indicado y responde 404 si no existe
+func (h *Handler) findProjectList(w http.ResponseWriter, r *http.Request, param string) (*models.ProjectList, bool) {
+	id, err := pathID(r, param)
+	var list *models.ProjectList
+	if err == nil {
+		list, err = h.ProjectLists.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Project list not found"}); err != nil {
+			log.Fatalf("Failed to write response: %v", err)
+		}
+		return nil, false
+	}
+	return list, true
 }
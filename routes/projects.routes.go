@@ -3,106 +3,189 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/carpentry-hub/woodys-backend/db"
 	"github.com/carpentry-hub/woodys-backend/models"
-	"github.com/gorilla/mux"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
 )
 
 // GetProject obtiene un proyecto - Requiere id
-func GetProject(w http.ResponseWriter, r *http.Request) {
-	var project models.Project
-	params := mux.Vars(r)
-	db.DB.First(&project, params["id"])
-
-	if project.ID == 0 {
+func (h *Handler) GetProject(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	var project *models.Project
+	if err == nil {
+		project, err = h.Projects.FindByID(r.Context(), id)
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound) // status code 404
 		if _, err := w.Write([]byte("Project Not Found")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
 		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&project); err != nil {
-			log.Fatalf("Failed to Encode json: %v", err)
-		}
+		return
+	}
+
+	if err := h.scoreProjects(r.Context(), project); err != nil {
+		log.Printf("Error scoring project %d: %v", project.ID, err)
+	}
+	if err := h.attachImages(r.Context(), project); err != nil {
+		log.Printf("Error loading the images of project %d: %v", project.ID, err)
+	}
+	if err := json.NewEncoder(w).Encode(project); err != nil {
+		log.Fatalf("Failed to Encode json: %v", err)
 	}
 }
 
 // SearchProjects obtene  lista proyectos segun una busqueda - Requiere
-func SearchProjects(w http.ResponseWriter, r *http.Request) {
-	query := db.DB.Model(&models.Project{})
-
-	style := r.URL.Query().Get("style")
-	if style != "" {
-		query = query.Where("? = ANY(style)", style).Where("is_public = TRUE")
+func (h *Handler) SearchProjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, errs := parseProjectFilter(query, time.Now())
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Invalid search filters", "errors": errs})
+		return
 	}
 
-	env := r.URL.Query().Get("environment")
-	if env != "" {
-		query = query.Where("? = ANY(environment)", env).Where("is_public = TRUE")
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error al buscar proyectos", http.StatusInternalServerError)
+		return
 	}
+	resolveFilter(index, &filter)
 
-	mat := r.URL.Query().Get("materials")
-	if mat != "" {
-		query = query.Where("? = ANY(materials)", mat).Where("is_public = TRUE")
+	spec := searchPage
+	if filter.Query != "" {
+		spec = textSearchPage
 	}
-
-	title := r.URL.Query().Get("title")
-	if title != "" {
-		query = query.Where("title ILIKE ?", "%"+title+"%").Where("is_public = TRUE")
+	page, ok := h.parsePage(w, r, spec)
+	if !ok {
+		return
 	}
 
-	maxTimeStr := r.URL.Query().Get("max_time_to_build")
-	if maxTimeStr != "" {
-		maxTime, err := strconv.Atoi(maxTimeStr)
+	// el mismo promedio global se usa para ordenar y para el puntaje que va en la respuesta,
+	// asi el cursor de sort=bayesian_rating coincide con el orden de la base. El de la primera
+	// pagina queda en el cursor: si cambiara entre paginas se saltearian o repetirian proyectos.
+	mean, ok := pinnedSiteMean(page)
+	if !ok {
+		mean, err = h.siteMeanRating(r.Context())
 		if err != nil {
-			http.Error(w, "max_time_to_build debe ser un número", http.StatusBadRequest)
+			http.Error(w, "Error al buscar proyectos", http.StatusInternalServerError)
 			return
 		}
-		query = query.Where("time_to_build <= ?", maxTime).Where("is_public = TRUE")
+		page.Pin(siteMeanParam, strconv.FormatFloat(mean, 'g', -1, 64))
 	}
+	filter.Bayesian = &repositories.BayesianPrior{Mean: mean, Weight: h.RatingPriorWeight}
 
-	var results []models.Project
-	if err := query.Find(&results).Error; err != nil {
+	results, total, err := h.Projects.Search(r.Context(), filter, page)
+	if err != nil {
+		log.Printf("Error searching projects: %v", err)
 		http.Error(w, "Error al buscar proyectos", http.StatusInternalServerError)
 		return
 	}
+	for i := range results {
+		results[i].BayesianScore = results[i].Histogram.BayesianScore(mean, h.RatingPriorWeight)
+	}
+	if err := h.attachImageList(r.Context(), results); err != nil {
+		log.Printf("Error loading project images: %v", err)
+	}
+
+	// facets=true agrega los conteos por filtro para el explorador
+	response := searchResponse{Page: pagination.NewPage(h.Cursors, page, results, total, projectKey(page.Order))}
+	if query.Get("facets") == "true" {
+		response.Facets, err = h.Projects.Facets(r.Context(), filter)
+		if err != nil {
+			log.Printf("Error counting facets: %v", err)
+			http.Error(w, "Error al buscar proyectos", http.StatusInternalServerError)
+			return
+		}
+	}
 
-	if err := json.NewEncoder(w).Encode(results); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Fatalf("Failed to Encode json: %v", err)
 	}
 }
 
-// PostProject postea un proyecto
-func PostProject(w http.ResponseWriter, r *http.Request) {
+// searchResponse es la pagina de resultados de SearchProjects con sus facetas, si se pidieron
+type searchResponse struct {
+	pagination.Page[models.Project]
+	Facets *repositories.Facets `json:"facets,omitempty"`
+}
+
+// PostProject postea un proyecto - El owner es el usuario autenticado
+func (h *Handler) PostProject(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
+		return
+	}
+
 	var project models.Project
 	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
-		log.Fatalf("Failed to Decode json: %v", err)
+		w.WriteHeader(http.StatusBadRequest) // status code 400
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
 	}
+	project.Owner = user.ID
 
-	createdProject := db.DB.Create(&project)
-	err := createdProject.Error
-	if err != nil {
+	// los agregados de valoraciones solo los escribe el repositorio de ratings
+	project.AverageRating = 0
+	project.RatingCount = 0
+	project.Histogram = models.RatingHistogram{}
+
+	// el HTML lo arma la api a partir del Markdown; el del tutorial sale de los pasos
+	project.DescriptionHTML = h.Markdown.Render(project.Description)
+	project.TutorialHTML = ""
+
+	// herramientas, materiales, estilos y ambiente se guardan como slugs de la taxonomia
+	if !h.canonicalize(w, r, &project) {
+		return
+	}
+	// las imagenes tienen que ser uploads
+	if !h.validProjectImages(w, r, &project, nil) {
+		return
+	}
+
+	// el tutorial se escribe por pasos; un tutorial de texto entra como primer paso, como en la migracion
+	var steps []models.ProjectStep
+	if tutorial := strings.TrimSpace(project.Tutorial); tutorial != "" {
+		steps = append(steps, models.ProjectStep{
+			Title: "Tutorial", Body: tutorial, BodyHTML: h.Markdown.Render(tutorial),
+			Images: []string{}, Tools: []string{}, SafetyNotes: []string{},
+		})
+	}
+	project.Tutorial = models.TutorialText(steps)
+	project.TutorialHTML = models.TutorialHTML(steps)
+
+	if err := h.Projects.Create(r.Context(), &project, steps, h.revision(r, nil)); err != nil {
 		w.WriteHeader(http.StatusBadRequest) // status code 400
 		if _, err := w.Write([]byte(err.Error())); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
 		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&project); err != nil {
-			log.Fatalf("Failed to Encode json: %v", err)
-		}
+		return
+	}
+
+	if err := h.attachImages(r.Context(), &project); err != nil {
+		log.Printf("Error loading the images of project %d: %v", project.ID, err)
+	}
+	if err := json.NewEncoder(w).Encode(&project); err != nil {
+		log.Fatalf("Failed to Encode json: %v", err)
 	}
 }
 
 // PutProject actualiza un proyecto
-func PutProject(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-
+func (h *Handler) PutProject(w http.ResponseWriter, r *http.Request) {
 	// chequeo que el proyecto ya exista
-	var existing models.Project
-	if err := db.DB.First(&existing, params["id"]).Error; err != nil {
+	id, err := pathID(r, "id")
+	var existing *models.Project
+	if err == nil {
+		existing, err = h.Projects.FindByID(r.Context(), id)
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound) // status code 404
 		if _, err := w.Write([]byte("Project Not Found")); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
@@ -110,6 +193,10 @@ func PutProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorize(w, policies.ManageProject(currentUser(r), existing)) {
+		return
+	}
+
 	// lee el proyecto updated
 	var updated models.Project
 	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
@@ -121,24 +208,41 @@ func PutProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// actualizar campos
+	previous := *existing
 	existing.Title = updated.Title
 	existing.Description = updated.Description
+	existing.DescriptionHTML = h.Markdown.Render(updated.Description)
 	existing.Images = updated.Images
 	existing.MainMaterial = updated.MainMaterial
 	existing.Materials = updated.Materials
 	existing.Height = updated.Height
 	existing.Width = updated.Width
 	existing.Length = updated.Length
-	existing.TimeToBuild = updated.TimeToBuild
+	// con todos los pasos estimados el tiempo de construccion es la suma de los pasos
+	steps, err := h.Steps.ListByProject(r.Context(), existing.ID)
+	if err != nil {
+		http.Error(w, "Error fetching steps", http.StatusInternalServerError)
+		return
+	}
+	if _, derived := models.StepMinutes(steps); !derived {
+		existing.TimeToBuild = updated.TimeToBuild
+	}
 	existing.Portrait = updated.Portrait
 	existing.Style = updated.Style
 	existing.Environment = updated.Environment
 	existing.Tools = updated.Tools
-	existing.Tutorial = updated.Tutorial
+	// el tutorial es el texto de los pasos (/projects/{id}/steps) y no se edita aca
 	existing.IsPublic = updated.IsPublic
 
+	if !h.canonicalize(w, r, existing) {
+		return
+	}
+	if !h.validProjectImages(w, r, existing, &previous) {
+		return
+	}
+
 	// guardar en DB
-	if err := db.DB.Save(&existing).Error; err != nil {
+	if err := h.Projects.Update(r.Context(), existing, h.revision(r, nil)); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := w.Write([]byte("Failed to save the project")); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
@@ -146,23 +250,37 @@ func PutProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&existing); err != nil {
+	if err := h.attachImages(r.Context(), existing); err != nil {
+		log.Printf("Error loading the images of project %d: %v", existing.ID, err)
+	}
+	if err := json.NewEncoder(w).Encode(existing); err != nil {
 		log.Fatalf("Failed to encode json: %v", err)
 	}
 }
 
 // DeleteProject borra un proyecto - Requiere id
-func DeleteProject(w http.ResponseWriter, r *http.Request) {
-	var project models.Project
-	params := mux.Vars(r)
-	db.DB.First(&project, params["id"])
-
-	if project.ID == 0 {
+func (h *Handler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	var project *models.Project
+	if err == nil {
+		project, err = h.Projects.FindByID(r.Context(), id)
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound) // status code 404
 		if _, err := w.Write([]byte("Project Not Found")); err != nil {
 			log.Fatalf("Failed to write response: %v", err)
 		}
-	} else {
-		db.DB.Unscoped().Delete(&project)
+		return
+	}
+
+	if !authorize(w, policies.ManageProject(currentUser(r), project)) {
+		return
+	}
+
+	if err := h.Projects.Delete(r.Context(), project.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete the project"})
+		return
 	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "Project deleted successfully"})
 }
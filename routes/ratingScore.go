@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+// siteMeanTTL es cada cuanto se recalcula el promedio global usado como prior bayesiano
+const siteMeanTTL = 5 * time.Minute
+
+// DefaultRatingPriorWeight es la cantidad de votos "promedio" que se mezclan en cada puntaje bayesiano
+const DefaultRatingPriorWeight = 10
+
+// siteMeanCache guarda el promedio global de valoraciones (el mismo que calcula GetStats)
+type siteMeanCache struct {
+	mu      sync.Mutex
+	value   float64
+	expires time.Time
+}
+
+// siteMeanRating devuelve el promedio global de valoraciones, recalculandolo si el cache expiro
+func (h *Handler) siteMeanRating(ctx context.Context) (float64, error) {
+	h.siteMean.mu.Lock()
+	defer h.siteMean.mu.Unlock()
+
+	if time.Now().Before(h.siteMean.expires) {
+		return h.siteMean.value, nil
+	}
+	mean, err := h.Ratings.Average(ctx)
+	if err != nil {
+		return 0, err
+	}
+	h.siteMean.value = mean
+	h.siteMean.expires = time.Now().Add(siteMeanTTL)
+	return mean, nil
+}
+
+// siteMeanParam es el nombre con que el promedio global queda fijo en el cursor de la busqueda
+const siteMeanParam = "site_mean"
+
+// pinnedSiteMean devuelve el promedio global que fijo la primera pagina de la busqueda, si hay
+func pinnedSiteMean(page pagination.Request) (float64, bool) {
+	raw, ok := page.Pinned(siteMeanParam)
+	if !ok {
+		return 0, false
+	}
+	mean, err := strconv.ParseFloat(raw, 64)
+	return mean, err == nil
+}
+
+// scoreProjects completa el puntaje bayesiano de cada proyecto
+func (h *Handler) scoreProjects(ctx context.Context, projects ...*models.Project) error {
+	mean, err := h.siteMeanRating(ctx)
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		project.BayesianScore = project.Histogram.BayesianScore(mean, h.RatingPriorWeight)
+	}
+	return nil
+}
+
+// scoreProjectList es scoreProjects para un slice de proyectos
+func (h *Handler) scoreProjectList(ctx context.Context, projects []models.Project) error {
+	ptrs := make([]*models.Project, len(projects))
+	for i := range projects {
+		ptrs[i] = &projects[i]
+	}
+	return h.scoreProjects(ctx, ptrs...)
+}
+
+// validRatingValue indica si el valor esta entre 1 y 5 estrellas
+func validRatingValue(value int8) bool {
+	return value >= models.MinRatingValue && value <= models.MaxRatingValue
+}
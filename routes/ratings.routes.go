@@ -7,64 +7,87 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
-	"github.com/carpentry-hub/woodys-backend/db"
-	"github.com/carpentry-hub/woodys-backend/middlewares"
 	"github.com/carpentry-hub/woodys-backend/models"
-	"github.com/gorilla/mux"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
 )
 
-// Constante para chequear no haya ratings con el par user_id y project_id duplicados
-const (
-    ErrCodeUniqueViolation = "23505"
-)
+// ratingResponse es el rating junto con los agregados del proyecto ya actualizados
+type ratingResponse struct {
+	models.Rating
+	repositories.RatingAggregate
+}
+
+// maxBatchRatings limita la cantidad de proyectos que se pueden consultar en GetUserRatings
+const maxBatchRatings = 100
+
+// PostRating postea un rating de un proyecto - Requiere id del proyecto
+func (h *Handler) PostRating(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
+		return
+	}
+
+	projectID, err := pathID(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+		return
+	}
 
-// PostRating postea un rating de un proyecto
-func PostRating(w http.ResponseWriter, r *http.Request) {
 	var rating models.Rating
 	if err := json.NewDecoder(r.Body).Decode(&rating); err != nil {
-        log.Printf("Failed to decode json: %v", err)
-        w.WriteHeader(http.StatusBadRequest)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
-        return
-    }
-
-	createdRating := db.DB.Create(&rating)
-	err := createdRating.Error
+		log.Printf("Failed to decode json: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	rating.UserID = user.ID
+	rating.ProjectID = projectID
+
+	if !validRatingValue(rating.Value) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "value must be between 1 and 5"})
+		return
+	}
+
+	// El rating y los agregados del proyecto se escriben en la misma transaccion
+	aggregate, err := h.Ratings.Create(r.Context(), &rating)
 	if err != nil {
-		var pgErr *pgconn.PgError
-        if errors.As(err, &pgErr) {
-            if pgErr.Code == ErrCodeUniqueViolation {
-                w.WriteHeader(http.StatusConflict)
-                json.NewEncoder(w).Encode(map[string]string{"message": "You have already rated this project"})
-                return
-            }
-        }
-        w.WriteHeader(http.StatusInternalServerError)
-        json.NewEncoder(w).Encode(map[string]string{"message": "Could not create rating"})
-        log.Printf("Failed to write response: %v", err)
-	} else {
-		// Ante nuevo rating actualizo average_rating y rating_count en proyecto
-		go middlewares.UpdateAverageRating(rating.ProjectID)
-		go middlewares.UpdateRatingCount(rating.ProjectID)
-		if err := json.NewEncoder(w).Encode(&rating); err != nil {
-			log.Fatalf("Failed to encode json: %v", err)
+		// chequeo no haya ratings con el par user_id y project_id duplicados
+		if errors.Is(err, repositories.ErrConflict) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"message": "You have already rated this project"})
+			return
+		}
+		if errors.Is(err, repositories.ErrMissingReference) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+			return
 		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Could not create rating"})
+		log.Printf("Failed to write response: %v", err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(ratingResponse{rating, *aggregate}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
 	}
 }
 
-// PutRating actualiza el rating de un proyecto - Require id
-func PutRating(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
+// PutRating actualiza el rating del usuario autenticado sobre un proyecto - Requiere id del proyecto
+func (h *Handler) PutRating(w http.ResponseWriter, r *http.Request) {
+	// chequeo que el rating ya exista
+	existing, ok := h.findCallerRating(w, r)
+	if !ok {
+		return
+	}
 
-	// chequeo que el proyecto ya exista
-	var existing models.Rating
-	if err := db.DB.First(&existing, params["id"]).Error; err != nil {
-		w.WriteHeader(http.StatusNotFound) // status code 404
-		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"}); err != nil {
-			log.Fatalf("Failed to write response: %v", err)
-		}
+	if !authorize(w, policies.ManageRating(currentUser(r), existing)) {
 		return
 	}
 
@@ -78,12 +101,24 @@ func PutRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !validRatingValue(updated.Value) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "value must be between 1 and 5"})
+		return
+	}
+
 	// actualizar campos
 	existing.Value = updated.Value
-	existing.UpdatedAt = updated.UpdatedAt
 
-	// guardar en DB
-	if err := db.DB.Save(&existing).Error; err != nil {
+	// guardar en DB junto con los agregados del proyecto
+	aggregate, err := h.Ratings.Update(r.Context(), existing)
+	if errors.Is(err, repositories.ErrNotFound) || errors.Is(err, repositories.ErrMissingReference) {
+		// se retiro el rating o se borro el proyecto despues de leerlo
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Rating not found"})
+		return
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := w.Write([]byte("Failed to save the rating")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
@@ -91,21 +126,15 @@ func PutRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Ante actulizacion de rating actualizo average_rating en proyecto
-	go middlewares.UpdateAverageRating(existing.ProjectID)
-	
-	if err := json.NewEncoder(w).Encode(&existing); err != nil {
+	if err := json.NewEncoder(w).Encode(ratingResponse{*existing, *aggregate}); err != nil {
 		log.Fatalf("Failed to encode json: %v", err)
 	}
 }
 
 // GetRating obtiene lista de todos los ratings de un proyecto - Requiere project_id
-func GetRating(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	projectIDStr := params["id"]
-
+func (h *Handler) GetRating(w http.ResponseWriter, r *http.Request) {
 	// chequeo existencia del proyecto
-	projectID, err := strconv.Atoi(projectIDStr) // cambio de str a int para evitar errores
+	projectID, err := pathID(r, "id")
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"}); err != nil {
@@ -114,9 +143,14 @@ func GetRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := h.parsePage(w, r, ratingsPage)
+	if !ok {
+		return
+	}
+
 	// realizacion de la query y manejo de errores
-	var ratings []models.Rating
-	if err := db.DB.Where("project_id = ?", projectID).Find(&ratings).Error; err != nil {
+	ratings, total, err := h.Ratings.ListByProject(r.Context(), projectID, page)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := w.Write([]byte("Error fetching ratings")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
@@ -124,7 +158,118 @@ func GetRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	writePage(w, pagination.NewPage(h.Cursors, page, ratings, total, ratingKey(page.Order)))
+}
+
+// DeleteRating borra el rating del usuario autenticado sobre un proyecto - Requiere id del proyecto
+func (h *Handler) DeleteRating(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.findCallerRating(w, r)
+	if !ok {
+		return
+	}
+
+	if !authorize(w, policies.ManageRating(currentUser(r), existing)) {
+		return
+	}
+
+	// borrar y recalcular los agregados del proyecto en la misma transaccion
+	aggregate, err := h.Ratings.Delete(r.Context(), existing)
+	if errors.Is(err, repositories.ErrNotFound) || errors.Is(err, repositories.ErrMissingReference) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Rating not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete the rating"})
+		log.Printf("Failed to delete rating %d: %v", existing.ID, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(aggregate); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// GetMyRating obtiene el rating del usuario autenticado sobre un proyecto - Requiere id del proyecto
+func (h *Handler) GetMyRating(w http.ResponseWriter, r *http.Request) {
+	rating, ok := h.findCallerRating(w, r)
+	if !ok {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(rating); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// GetUserRatings obtiene los ratings de un usuario para varios proyectos - Requiere id y project_ids=1,2,3,
+// solo el mismo usuario o un admin
+func (h *Handler) GetUserRatings(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "id")
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "User not found"})
+		return
+	}
+
+	if !authorize(w, policies.ViewRatings(currentUser(r), userID)) {
+		return
+	}
+
+	raw := strings.Split(r.URL.Query().Get("project_ids"), ",")
+	projectIDs := make([]int64, 0, len(raw))
+	for _, value := range raw {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		projectID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"message": "project_ids must be a comma separated list of ids"})
+			return
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	if len(projectIDs) == 0 || len(projectIDs) > maxBatchRatings {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "project_ids must contain between 1 and " + strconv.Itoa(maxBatchRatings) + " ids",
+		})
+		return
+	}
+
+	ratings, err := h.Ratings.ListByUserAndProjects(r.Context(), userID, projectIDs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Error fetching ratings"})
+		return
+	}
+
 	if err := json.NewEncoder(w).Encode(&ratings); err != nil {
 		log.Fatalf("Failed to encode json: %v", err)
 	}
 }
+
+// findCallerRating busca el rating del usuario autenticado sobre el proyecto de la ruta.
+// Responde 401 si no hay usuario y 404 si no hay rating.
+func (h *Handler) findCallerRating(w http.ResponseWriter, r *http.Request) (*models.Rating, bool) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
+		return nil, false
+	}
+
+	projectID, err := pathID(r, "id")
+	var rating *models.Rating
+	if err == nil {
+		rating, err = h.Ratings.FindByUserAndProject(r.Context(), user.ID, projectID)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound) // status code 404
+		if err := json.NewEncoder(w).Encode(map[string]string{"message": "Rating not found"}); err != nil {
+			log.Fatalf("Failed to write response: %v", err)
+		}
+		return nil, false
+	}
+	return rating, true
+}
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// TestConcurrentRatingsKeepAggregates califica, cambia y retira valoraciones de un mismo proyecto en
+// paralelo y controla que los agregados del proyecto coincidan con recalcularlos desde cero
+func TestConcurrentRatingsKeepAggregates(t *testing.T) {
+	const raters, rounds = 20, 15
+	api := newTestAPI(t)
+	project := api.project(api.user("owner", false), "Mesa")
+	users := make([]*models.User, raters)
+	for i := range users {
+		users[i] = api.user("rater"+strconv.Itoa(i), false)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, raters*rounds)
+	ratings := urlf("/projects/%d/ratings", project.ID)
+	for i, user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			random := rand.New(rand.NewPCG(uint64(i), 0))
+			rated := false
+			for range rounds {
+				value := models.Rating{Value: int8(random.IntN(5) + 1)}
+				method := "PUT"
+				switch {
+				case !rated:
+					method = "POST"
+				case random.IntN(3) == 0:
+					method = "DELETE"
+				}
+				if rec := api.do(method, ratings, user, value); rec.Code != http.StatusOK {
+					errs <- method + " " + ratings + ": " + strconv.Itoa(rec.Code) + " " + rec.Body.String()
+					return
+				}
+				rated = method != "DELETE"
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	var want models.RatingHistogram
+	for _, user := range users {
+		rating, err := api.repos.Ratings.FindByUserAndProject(context.Background(), user.ID, project.ID)
+		if err == nil {
+			want.Add(rating.Value, 1)
+		}
+	}
+	got := decode[models.Project](t, api.expect(http.StatusOK, "GET", urlf("/projects/%d", project.ID), nil, nil))
+	if got.Histogram != want {
+		t.Errorf("histogram = %+v, want %+v", got.Histogram, want)
+	}
+	if got.RatingCount != int(want.Count()) || got.AverageRating != float32(want.Average()) {
+		t.Errorf("aggregates = %d ratings avg %v, want %d avg %v",
+			got.RatingCount, got.AverageRating, want.Count(), float32(want.Average()))
+	}
+}
+
+// withdrawnRatings simula que el rating se retiro entre que el handler lo lee y lo escribe
+type withdrawnRatings struct {
+	repositories.RatingRepository
+}
+
+func (withdrawnRatings) Update(context.Context, *models.Rating) (*repositories.RatingAggregate, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func (withdrawnRatings) Delete(context.Context, *models.Rating) (*repositories.RatingAggregate, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func TestRatingWithdrawnDuringWrite(t *testing.T) {
+	api := newTestAPI(t)
+	rater := api.user("rater", false)
+	project := api.project(api.user("owner", false), "Mesa")
+	ratings := urlf("/projects/%d/ratings", project.ID)
+	api.expect(http.StatusOK, "POST", ratings, rater, models.Rating{Value: 4})
+
+	api.h.Ratings = withdrawnRatings{api.h.Ratings}
+	api.expect(http.StatusNotFound, "PUT", ratings, rater, models.Rating{Value: 2})
+	api.expect(http.StatusNotFound, "DELETE", ratings, rater, nil)
+}
+
+func TestGetUserRatingsAccess(t *testing.T) {
+	api := newTestAPI(t)
+	rater := api.user("rater", false)
+	other := api.user("other", false)
+	admin := api.user("admin", true)
+	project := api.project(other, "Mesa")
+	api.expect(http.StatusOK, "POST", urlf("/projects/%d/ratings", project.ID), rater, models.Rating{Value: 4})
+	path := urlf("/users/%d/ratings?project_ids=%d", rater.ID, project.ID)
+
+	api.expect(http.StatusUnauthorized, "GET", path, nil, nil)
+	api.expect(http.StatusForbidden, "GET", path, other, nil)
+	for _, user := range []*models.User{rater, admin} {
+		got := decode[[]models.Rating](t, api.expect(http.StatusOK, "GET", path, user, nil))
+		if len(got) != 1 || got[0].Value != 4 {
+			t.Errorf("ratings as %s = %+v, want one rating of 4", user.Username, got)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/gorilla/mux"
+)
+
+// DefaultRevisionsKept es cuantas revisiones se guardan de cada proyecto si no se configura otra cosa
+const DefaultRevisionsKept = 50
+
+// revisionDiff son los cambios de la revision From a la revision To
+type revisionDiff struct {
+	From    int                  `json:"from"`
+	To      int                  `json:"to"`
+	Changes []models.FieldChange `json:"changes"`
+}
+
+// GetProjectRevisions lista el historial de un proyecto, de la revision mas nueva a la mas vieja,
+// sin el contenido de cada una - Requiere id, solo el dueño
+func (h *Handler) GetProjectRevisions(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.revisionsProject(w, r)
+	if !ok {
+		return
+	}
+	page, ok := h.parsePage(w, r, revisionsPage)
+	if !ok {
+		return
+	}
+	revisions, total, err := h.Revisions.ListByProject(r.Context(), project.ID, page)
+	if err != nil {
+		log.Printf("Error listing revisions of project %d: %v", project.ID, err)
+		http.Error(w, "Error fetching revisions", http.StatusInternalServerError)
+		return
+	}
+	writePage(w, pagination.NewPage(h.Cursors, page, revisions, total, revisionKey))
+}
+
+// GetProjectRevision obtiene una revision con todo su contenido - Requiere id y rev, solo el dueño
+func (h *Handler) GetProjectRevision(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.revisionsProject(w, r)
+	if !ok {
+		return
+	}
+	revision, ok := h.findRevision(w, r, project, mux.Vars(r)["rev"])
+	if !ok {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(revision); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// GetProjectRevisionDiff devuelve los campos que cambiaron de la revision from a la revision rev -
+// Requiere id y rev, solo el dueño; sin from compara con la revision anterior
+func (h *Handler) GetProjectRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.revisionsProject(w, r)
+	if !ok {
+		return
+	}
+	to, ok := h.findRevision(w, r, project, mux.Vars(r)["rev"])
+	if !ok {
+		return
+	}
+	from := r.URL.Query().Get("from")
+	if from == "" {
+		from = strconv.Itoa(to.Number - 1)
+	} else if _, err := strconv.Atoi(from); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "from must be a revision number"})
+		return
+	}
+	previous, ok := h.findRevision(w, r, project, from)
+	if !ok {
+		return
+	}
+	diff := revisionDiff{
+		From:    previous.Number,
+		To:      to.Number,
+		Changes: models.DiffSnapshots(previous.Snapshot, to.Snapshot),
+	}
+	if err := json.NewEncoder(w).Encode(diff); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// RestoreProjectRevision vuelve el proyecto, sus pasos y sus piezas al contenido de una revision -
+// Requiere id y rev, solo el dueño. El historial no se pierde: la restauracion queda como una
+// revision nueva. Las imagenes de uploads que se borraron despues no se restauran.
+func (h *Handler) RestoreProjectRevision(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.revisionsProject(w, r)
+	if !ok {
+		return
+	}
+	revision, ok := h.findRevision(w, r, project, mux.Vars(r)["rev"])
+	if !ok {
+		return
+	}
+	snapshot := revision.Snapshot
+
+	restored := *project
+	snapshot.ApplyTo(&restored)
+	restored.DescriptionHTML = h.Markdown.Render(restored.Description)
+	if !h.canonicalize(w, r, &restored) {
+		return
+	}
+	steps := snapshot.ProjectSteps(project.ID)
+	parts := snapshot.ProjectParts(project.ID)
+
+	// lo que se guardo en la revision ya se valido al guardarlo; solo pueden faltar uploads
+	err := h.keepExistingUploads(r.Context(), &restored.Portrait, (*[]string)(&restored.Images))
+	for i := 0; err == nil && i < len(steps); i++ {
+		steps[i].BodyHTML = h.Markdown.Render(steps[i].Body)
+		err = h.keepExistingUploads(r.Context(), nil, (*[]string)(&steps[i].Images))
+	}
+	if err != nil {
+		log.Printf("Error checking the images of revision %d of project %d: %v", revision.Number, project.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to restore the revision"})
+		return
+	}
+
+	if err := h.Revisions.Restore(r.Context(), &restored, steps, parts, h.revision(r, &revision.Number)); err != nil {
+		log.Printf("Error restoring revision %d of project %d: %v", revision.Number, project.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to restore the revision"})
+		return
+	}
+
+	// el tutorial y el tiempo de construccion los vuelve a armar el repositorio a partir de los pasos
+	updated, err := h.Projects.FindByID(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching the project", http.StatusInternalServerError)
+		return
+	}
+	if err := h.attachImages(r.Context(), updated); err != nil {
+		log.Printf("Error loading the images of project %d: %v", updated.ID, err)
+	}
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// revision es la revision que guardan las escrituras del pedido, con quien lo hace como autor;
+// restoredFrom es la revision restaurada, si la hay
+func (h *Handler) revision(r *http.Request, restoredFrom *int) repositories.Revision {
+	revision := repositories.Revision{RestoredFrom: restoredFrom, Retention: h.RevisionRetention}
+	if user := currentUser(r); user != nil {
+		revision.AuthorID = &user.ID
+	}
+	return revision
+}
+
+// keepExistingUploads saca de portrait e images las urls de uploads que ya no existen. Las demas
+// urls quedan como estan.
+func (h *Handler) keepExistingUploads(ctx context.Context, portrait *string, images *[]string) error {
+	var urls, ids []string
+	if portrait != nil {
+		urls = append(urls, *portrait)
+	}
+	urls = append(urls, *images...)
+	for _, url := range urls {
+		if id, ok := h.uploadID(url); ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	found, err := h.Uploads.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(found))
+	for _, upload := range found {
+		exists[upload.ID] = true
+	}
+	missing := func(url string) bool {
+		id, ok := h.uploadID(url)
+		return ok && !exists[id]
+	}
+	if portrait != nil && missing(*portrait) {
+		*portrait = ""
+	}
+	kept := make([]string, 0, len(*images))
+	for _, url := range *images {
+		if !missing(url) {
+			kept = append(kept, url)
+		}
+	}
+	*images = kept
+	return nil
+}
+
+// revisionsProject lee el proyecto de la ruta y controla que quien pide pueda ver su historial
+func (h *Handler) revisionsProject(w http.ResponseWriter, r *http.Request) (*models.Project, bool) {
+	id, err := pathID(r, "id")
+	var project *models.Project
+	if err == nil {
+		project, err = h.Projects.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+		return nil, false
+	}
+	if !authorize(w, policies.ManageProject(currentUser(r), project)) {
+		return nil, false
+	}
+	return project, true
+}
+
+// findRevision lee la revision number del proyecto; responde 404 si no existe o ya se borro
+func (h *Handler) findRevision(
+	w http.ResponseWriter, r *http.Request, project *models.Project, number string,
+) (*models.ProjectRevision, bool) {
+	n, err := strconv.Atoi(number)
+	var revision *models.ProjectRevision
+	if err == nil {
+		revision, err = h.Revisions.FindByNumber(r.Context(), project.ID, n)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			log.Printf("Error fetching revision %d of project %d: %v", n, project.ID, err)
+			http.Error(w, "Error fetching the revision", http.StatusInternalServerError)
+			return nil, false
+		}
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Revision " + number + " not found"})
+		return nil, false
+	}
+	return revision, true
+}
+
+func revisionKey(revision models.ProjectRevision) pagination.Keyset {
+	return pagination.Keyset{Value: int64(revision.Number), ID: revision.ID}
+}
@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/cutlist"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+)
+
+// revisions devuelve el historial del proyecto, de la revision mas vieja a la mas nueva
+func (api *testAPI) revisions(project *models.Project, owner *models.User) []models.ProjectRevision {
+	api.t.Helper()
+	path := urlf("/projects/%d/revisions?limit=100&order=asc", project.ID)
+	return decode[pagination.Page[models.ProjectRevision]](api.t, api.expect(http.StatusOK, "GET", path, owner, nil)).Items
+}
+
+func TestRevisionsRecordEachChange(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	api.terms(models.TaxonomyTerm{Kind: models.TaxonomyMaterial, Slug: "pine", LabelES: "Pino", LabelEN: "Pine"})
+
+	project := decode[models.Project](t, api.expect(http.StatusOK, "POST", "/projects", owner, models.Project{
+		Title: "Estante", Tutorial: "Cortar las tablas.", Materials: []string{"pine"}, IsPublic: true,
+	}))
+	// guardar lo mismo no agrega revision
+	api.expect(http.StatusOK, "PUT", urlf("/projects/%d", project.ID), owner, project)
+	project.Title = "Estante de pared"
+	api.expect(http.StatusOK, "PUT", urlf("/projects/%d", project.ID), owner, project)
+	api.expect(http.StatusCreated, "POST", urlf("/projects/%d/steps", project.ID), owner,
+		map[string]any{"title": "Lijar", "body": "Con grano 120."})
+	api.expect(http.StatusOK, "PUT", urlf("/projects/%d/parts", project.ID), owner, map[string]any{
+		"parts": []cutlist.Part{{Name: "lateral", Quantity: 2, Material: "pine", Thickness: 18, Width: 200, Length: 900}},
+	})
+	api.expect(http.StatusOK, "POST", urlf("/projects/%d/revisions/1/restore", project.ID), owner, nil)
+
+	revisions := api.revisions(&project, owner)
+	want := [][]string{{}, {"title"}, {"steps"}, {"parts"}, {"title", "steps", "parts"}}
+	if len(revisions) != len(want) {
+		t.Fatalf("%d revisions, want %d: %+v", len(revisions), len(want), revisions)
+	}
+	for i, revision := range revisions {
+		if revision.Number != i+1 || revision.AuthorID == nil || *revision.AuthorID != owner.ID {
+			t.Errorf("revision %d = %+v, want number %d by the owner", i, revision, i+1)
+		}
+		if !slices.Equal(revision.Changed, want[i]) {
+			t.Errorf("revision %d changed %q, want %q", revision.Number, revision.Changed, want[i])
+		}
+	}
+	if restored := revisions[4].RestoredFrom; restored == nil || *restored != 1 {
+		t.Errorf("restored_from = %v, want 1", restored)
+	}
+
+	first, err := api.repos.Revisions.FindByNumber(context.Background(), project.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Snapshot.Title != "Estante" || len(first.Snapshot.Steps) != 1 || first.Snapshot.Steps[0].Body != "Cortar las tablas." {
+		t.Errorf("revision 1 = %+v, want the project as created with its tutorial step", first.Snapshot)
+	}
+}
+
+// TestRevisionsConcurrentSaves controla que con varios PUT a la vez cada revision se numere en orden
+// y se compare con la anterior, y que la ultima sea el estado guardado
+func TestRevisionsConcurrentSaves(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	project := api.project(owner, "Banco")
+
+	const saves = 20
+	var wg sync.WaitGroup
+	for i := range saves {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body := *project
+			body.Title = fmt.Sprintf("Banco %d", i)
+			if rec := api.do("PUT", urlf("/projects/%d", project.ID), owner, body); rec.Code != http.StatusOK {
+				t.Errorf("PUT %d: status = %d (%s)", i, rec.Code, rec.Body)
+			}
+		}()
+	}
+	wg.Wait()
+
+	revisions := api.revisions(project, owner)
+	if len(revisions) != saves+1 {
+		t.Fatalf("%d revisions, want %d", len(revisions), saves+1)
+	}
+	titles := map[string]bool{}
+	previous := "Banco"
+	for i, revision := range revisions {
+		full, err := api.repos.Revisions.FindByNumber(context.Background(), project.ID, revision.Number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revision.Number != i+1 {
+			t.Errorf("revision %d has number %d", i+1, revision.Number)
+		}
+		if i > 0 && (full.Snapshot.Title == previous || !slices.Equal(revision.Changed, []string{"title"})) {
+			t.Errorf("revision %d: title %q after %q, changed %q", revision.Number, full.Snapshot.Title, previous,
+				revision.Changed)
+		}
+		titles[full.Snapshot.Title] = true
+		previous = full.Snapshot.Title
+	}
+	if len(titles) != saves+1 {
+		t.Errorf("%d distinct titles in the history, want every save once", len(titles))
+	}
+	current, err := api.repos.Projects.FindByID(context.Background(), project.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Title != previous {
+		t.Errorf("latest revision has title %q, the project %q", previous, current.Title)
+	}
+}
@@ -0,0 +1,222 @@
+package routes
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// maxSearchQueryLength limita el largo del parametro q de SearchProjects
+const maxSearchQueryLength = 200
+
+// filterErrors junta los errores de validacion de la busqueda, uno por parametro
+type filterErrors map[string]string
+
+func (e filterErrors) add(param, format string, args ...any) {
+	if _, ok := e[param]; !ok {
+		e[param] = fmt.Sprintf(format, args...)
+	}
+}
+
+// parseProjectFilter arma el filtro de SearchProjects a partir de la query string.
+//
+//	style, materials, tools             varios valores; {style,materials,tools}_match=any|all|only
+//	main_material, environment, owner   varios valores, alcanza con alguno
+//	min_/max_ height, width, length     cm
+//	min_/max_ time_to_build             minutos
+//	min_/max_ rating, rating_count      promedio (0 a 5) y cantidad de valoraciones
+//	created_after, created_before       RFC 3339 o AAAA-MM-DD; created_within_days=N
+//	fits_in=60x40x90                    entra en la caja en cualquier orientacion
+//	title, q                            titulo parcial y busqueda de texto
+func parseProjectFilter(query url.Values, now time.Time) (repositories.ProjectFilter, filterErrors) {
+	errs := filterErrors{}
+	filter := repositories.ProjectFilter{
+		Style:          queryList(query, "style"),
+		StyleMatch:     parseArrayMatch(query, "style_match", errs),
+		Materials:      queryList(query, "materials"),
+		MaterialsMatch: parseArrayMatch(query, "materials_match", errs),
+		Tools:          queryList(query, "tools"),
+		ToolsMatch:     parseArrayMatch(query, "tools_match", errs),
+		MainMaterial:   queryList(query, "main_material"),
+		Environment:    queryList(query, "environment"),
+		Title:          query.Get("title"),
+		Query:          strings.TrimSpace(query.Get("q")),
+	}
+
+	for _, raw := range queryList(query, "owner") {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			errs.add("owner", "owner must be a list of user ids")
+			continue
+		}
+		filter.Owners = append(filter.Owners, id)
+	}
+
+	if utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		errs.add("q", "q cannot exceed %d characters", maxSearchQueryLength)
+	}
+
+	filter.Height = parseFloatRange(query, "height", 0, math.Inf(1), errs)
+	filter.Width = parseFloatRange(query, "width", 0, math.Inf(1), errs)
+	filter.Length = parseFloatRange(query, "length", 0, math.Inf(1), errs)
+	filter.AverageRating = parseFloatRange(query, "rating", 0, models.MaxRatingValue, errs)
+	filter.TimeToBuild = parseIntRange(query, "time_to_build", errs)
+	filter.RatingCount = parseIntRange(query, "rating_count", errs)
+	filter.CreatedAt = parseCreatedAt(query, now, errs)
+
+	if raw := query.Get("fits_in"); raw != "" {
+		box, ok := parseBox(raw)
+		if !ok {
+			errs.add("fits_in", "fits_in must be three positive sizes in cm, like 60x40x90")
+		} else {
+			filter.FitsIn = &box
+		}
+	}
+	return filter, errs
+}
+
+// queryList lee un filtro de varios valores, que se puede repetir (style=a&style=b) o separar con
+// comas (style=a,b)
+func queryList(query url.Values, name string) []string {
+	var values []string
+	for _, raw := range query[name] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" && !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func parseArrayMatch(query url.Values, name string, errs filterErrors) repositories.ArrayMatch {
+	switch match := repositories.ArrayMatch(query.Get(name)); match {
+	case "":
+		return repositories.MatchAny
+	case repositories.MatchAny, repositories.MatchAll, repositories.MatchOnly:
+		return match
+	}
+	errs.add(name, "%s must be any, all or only", name)
+	return repositories.MatchAny
+}
+
+// parseFloatRange lee min_<name> y max_<name>, que deben estar entre lower y upper
+func parseFloatRange(query url.Values, name string, lower, upper float64, errs filterErrors) repositories.Range[float64] {
+	var r repositories.Range[float64]
+	for _, bound := range []struct {
+		param  string
+		target **float64
+	}{{"min_" + name, &r.Min}, {"max_" + name, &r.Max}} {
+		raw := query.Get(bound.param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || value < lower || value > upper {
+			if math.IsInf(upper, 1) {
+				errs.add(bound.param, "%s must be a number greater than or equal to %g", bound.param, lower)
+			} else {
+				errs.add(bound.param, "%s must be a number between %g and %g", bound.param, lower, upper)
+			}
+			continue
+		}
+		*bound.target = &value
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		errs.add("min_"+name, "min_%s cannot be greater than max_%s", name, name)
+	}
+	return r
+}
+
+// parseIntRange lee min_<name> y max_<name> como enteros no negativos
+func parseIntRange(query url.Values, name string, errs filterErrors) repositories.Range[int64] {
+	var r repositories.Range[int64]
+	for _, bound := range []struct {
+		param  string
+		target **int64
+	}{{"min_" + name, &r.Min}, {"max_" + name, &r.Max}} {
+		raw := query.Get(bound.param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || value < 0 {
+			errs.add(bound.param, "%s must be a whole number greater than or equal to 0", bound.param)
+			continue
+		}
+		*bound.target = &value
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		errs.add("min_"+name, "min_%s cannot be greater than max_%s", name, name)
+	}
+	return r
+}
+
+func parseCreatedAt(query url.Values, now time.Time, errs filterErrors) repositories.Range[time.Time] {
+	var r repositories.Range[time.Time]
+	for _, bound := range []struct {
+		param  string
+		target **time.Time
+	}{{"created_after", &r.Min}, {"created_before", &r.Max}} {
+		raw := query.Get(bound.param)
+		if raw == "" {
+			continue
+		}
+		value, err := parseDate(raw)
+		if err != nil {
+			errs.add(bound.param, "%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", bound.param)
+			continue
+		}
+		*bound.target = &value
+	}
+
+	if raw := query.Get("created_within_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		switch {
+		case err != nil || days <= 0:
+			errs.add("created_within_days", "created_within_days must be a whole number greater than 0")
+		case r.Min != nil:
+			errs.add("created_within_days", "created_within_days cannot be combined with created_after")
+		default:
+			since := now.AddDate(0, 0, -days)
+			r.Min = &since
+		}
+	}
+	if r.Min != nil && r.Max != nil && r.Min.After(*r.Max) {
+		errs.add("created_after", "created_after cannot be later than created_before")
+	}
+	return r
+}
+
+func parseDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+// parseBox lee medidas como "60x40x90" (tambien acepta "×" y "X")
+func parseBox(raw string) (repositories.Box, bool) {
+	raw = strings.NewReplacer("×", "x", "X", "x").Replace(raw)
+	parts := strings.Split(raw, "x")
+	if len(parts) != 3 {
+		return repositories.Box{}, false
+	}
+	var sides [3]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || !(value > 0) || math.IsInf(value, 0) {
+			return repositories.Box{}, false
+		}
+		sides[i] = value
+	}
+	return repositories.Box{Height: sides[0], Width: sides[1], Length: sides[2]}, true
+}
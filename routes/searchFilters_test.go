@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+func TestParseProjectFilterErrors(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query string
+		want  filterErrors
+	}{
+		{"min_height=alto", filterErrors{"min_height": "min_height must be a number greater than or equal to 0"}},
+		{"max_width=-1", filterErrors{"max_width": "max_width must be a number greater than or equal to 0"}},
+		{"min_length=NaN", filterErrors{"min_length": "min_length must be a number greater than or equal to 0"}},
+		{"max_height=Inf", filterErrors{"max_height": "max_height must be a number greater than or equal to 0"}},
+		{"max_length=%2BInf", filterErrors{"max_length": "max_length must be a number greater than or equal to 0"}},
+		{"min_width=1e400", filterErrors{"min_width": "min_width must be a number greater than or equal to 0"}},
+		{"max_rating=6", filterErrors{"max_rating": "max_rating must be a number between 0 and 5"}},
+		{"min_rating=-Inf", filterErrors{"min_rating": "min_rating must be a number between 0 and 5"}},
+		{"min_time_to_build=1.5", filterErrors{
+			"min_time_to_build": "min_time_to_build must be a whole number greater than or equal to 0",
+		}},
+		{"min_height=50&max_height=20", filterErrors{"min_height": "min_height cannot be greater than max_height"}},
+		{"min_rating_count=10&max_rating_count=2", filterErrors{
+			"min_rating_count": "min_rating_count cannot be greater than max_rating_count",
+		}},
+		{"style_match=some", filterErrors{"style_match": "style_match must be any, all or only"}},
+		{"materials_match=ALL&tools_match=none", filterErrors{
+			"materials_match": "materials_match must be any, all or only",
+			"tools_match":     "tools_match must be any, all or only",
+		}},
+		{"created_within_days=7&created_after=2026-01-01", filterErrors{
+			"created_within_days": "created_within_days cannot be combined with created_after",
+		}},
+		{"created_within_days=0", filterErrors{
+			"created_within_days": "created_within_days must be a whole number greater than 0",
+		}},
+		{"created_after=2026-03-01&created_before=2026-02-01", filterErrors{
+			"created_after": "created_after cannot be later than created_before",
+		}},
+		{"created_before=ayer", filterErrors{
+			"created_before": "created_before must be an RFC 3339 timestamp or a YYYY-MM-DD date",
+		}},
+		{"fits_in=60x40", filterErrors{"fits_in": "fits_in must be three positive sizes in cm, like 60x40x90"}},
+		{"fits_in=60x40xInf", filterErrors{"fits_in": "fits_in must be three positive sizes in cm, like 60x40x90"}},
+		{"owner=1,dos", filterErrors{"owner": "owner must be a list of user ids"}},
+	}
+	for _, tt := range tests {
+		query, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, errs := parseProjectFilter(query, now); !reflect.DeepEqual(errs, tt.want) {
+			t.Errorf("%s: errors = %v, want %v", tt.query, errs, tt.want)
+		}
+	}
+}
+
+func TestParseProjectFilter(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	query, err := url.ParseQuery("style=rustic,modern&style=rustic&style_match=all&min_height=20&max_height=80.5" +
+		"&max_rating=4&min_time_to_build=30&created_within_days=7&fits_in=60×40X90&owner=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter, errs := parseProjectFilter(query, now)
+	if len(errs) > 0 {
+		t.Fatalf("errors = %v", errs)
+	}
+	low, high, rating, minutes, since := 20.0, 80.5, 4.0, int64(30), now.AddDate(0, 0, -7)
+	want := repositories.ProjectFilter{
+		Style: []string{"rustic", "modern"}, StyleMatch: repositories.MatchAll,
+		MaterialsMatch: repositories.MatchAny, ToolsMatch: repositories.MatchAny,
+		Owners:        []int64{3},
+		Height:        repositories.Range[float64]{Min: &low, Max: &high},
+		AverageRating: repositories.Range[float64]{Max: &rating},
+		TimeToBuild:   repositories.Range[int64]{Min: &minutes},
+		CreatedAt:     repositories.Range[time.Time]{Min: &since},
+		FitsIn:        &repositories.Box{Height: 60, Width: 40, Length: 90},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Errorf("filter = %+v, want %+v", filter, want)
+	}
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// setSiteMean fija el promedio global que devuelve el cache del Handler
+func setSiteMean(h *Handler, mean float64) {
+	h.siteMean.mu.Lock()
+	defer h.siteMean.mu.Unlock()
+	h.siteMean.value = mean
+	h.siteMean.expires = time.Now().Add(time.Hour)
+}
+
+func TestBayesianSortKeepsSiteMeanAcrossPages(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	histograms := map[string]models.RatingHistogram{
+		"una de cinco":   {Five: 1},
+		"cien de cuatro": {Four: 100},
+		"dos de tres":    {Three: 2},
+		"sin votos":      {},
+	}
+	for title, histogram := range histograms {
+		project := models.Project{Owner: owner.ID, Title: title, IsPublic: true, Histogram: histogram}
+		if err := api.repos.Projects.Create(context.Background(), &project, nil, repositories.Revision{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type page = pagination.Page[models.Project]
+	search := "/projects/search?sort=bayesian_rating&limit=2"
+	setSiteMean(api.h, 1)
+	first := decode[page](t, api.expect(http.StatusOK, "GET", search, nil, nil))
+	if first.NextCursor == "" {
+		t.Fatal("first page has no next_cursor")
+	}
+
+	// con promedio 5 "una de cinco" pasaria adelante de "cien de cuatro"
+	setSiteMean(api.h, 5)
+	second := decode[page](t, api.expect(http.StatusOK, "GET",
+		search+"&cursor="+url.QueryEscape(first.NextCursor), nil, nil))
+
+	var titles []string
+	for _, project := range append(first.Items, second.Items...) {
+		titles = append(titles, project.Title)
+		want := project.Histogram.BayesianScore(1, api.h.RatingPriorWeight)
+		if project.BayesianScore != want {
+			t.Errorf("%s: bayesian_rating = %v, want %v (site mean of the first page)",
+				project.Title, project.BayesianScore, want)
+		}
+	}
+	want := []string{"cien de cuatro", "una de cinco", "dos de tres", "sin votos"}
+	if len(titles) != len(want) {
+		t.Fatalf("projects = %q, want %q", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Fatalf("projects = %q, want %q", titles, want)
+		}
+	}
+}
+
+// TestSearchHidesPrivateProjects controla que los privados no aparezcan ni en los resultados ni en las
+// facetas, con y sin filtros
+func TestSearchHidesPrivateProjects(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	api.project(owner, "Mesa publica")
+	private := models.Project{Owner: owner.ID, Title: "Mesa privada", Style: []string{"nordico"}}
+	if err := api.repos.Projects.Create(context.Background(), &private, nil, repositories.Revision{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, search := range []string{"/projects/search?facets=true", "/projects/search?facets=true&style=nordico"} {
+		got := decode[searchResponse](t, api.expect(http.StatusOK, "GET", search, nil, nil))
+		for _, project := range got.Items {
+			if !project.IsPublic {
+				t.Errorf("%s: private project %q in results", search, project.Title)
+			}
+		}
+		if got.Facets == nil {
+			t.Fatalf("%s: no facets", search)
+		}
+		for _, value := range got.Facets.Style {
+			if value.Value == "nordico" {
+				t.Errorf("%s: style facet counts the private project", search)
+			}
+		}
+	}
+}
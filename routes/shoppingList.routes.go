@@ -0,0 +1,92 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/shopping"
+)
+
+// hardwareSlug es el termino de la taxonomia del que cuelgan los herrajes
+const hardwareSlug = "hardware"
+
+// GetShoppingList arma la lista de compras de una lista de proyectos - Requiere id;
+// format=json|csv|text. Si quien pide es el dueño de la lista, lo que ya esta en su inventario va
+// aparte en owned.
+func (h *Handler) GetShoppingList(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.findProjectList(w, r, "id")
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if !slices.Contains([]string{"", "json", "csv", "text"}, format) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "format must be json, csv or text"})
+		return
+	}
+
+	items, err := h.ProjectLists.ListItems(r.Context(), list.ID)
+	if err != nil {
+		http.Error(w, "Error fetching list items", http.StatusInternalServerError)
+		return
+	}
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.ProjectID
+	}
+	projects, err := h.Projects.FindByIDs(r.Context(), ids)
+	if err != nil {
+		http.Error(w, "Error fetching projects", http.StatusInternalServerError)
+		return
+	}
+	parts, err := h.Parts.ListByProjects(r.Context(), ids)
+	if err != nil {
+		http.Error(w, "Error fetching parts", http.StatusInternalServerError)
+		return
+	}
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+
+	input := shopping.Input{
+		Projects: projects,
+		Parts:    parts,
+		Index:    index,
+		IsHardware: func(material string) bool {
+			slug, ok := index.Resolve(models.TaxonomyMaterial, material)
+			return ok && (slug == hardwareSlug || slices.Contains(index.Ancestors(models.TaxonomyMaterial, slug), hardwareSlug))
+		},
+	}
+	// el inventario es privado: solo se descuenta cuando lo pide el dueño, no un admin ni otro usuario
+	if user := currentUser(r); user != nil && user.ID == list.UserID {
+		owned, err := h.Inventory.ListByUser(r.Context(), list.UserID)
+		if err != nil {
+			http.Error(w, "Error fetching inventory", http.StatusInternalServerError)
+			return
+		}
+		input.Inventory = append([]models.InventoryItem{}, owned...)
+	}
+	shoppingList := shopping.Build(*list, input)
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="shopping-list-%d.csv"`, list.ID))
+		err = shopping.WriteCSV(w, shoppingList)
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		err = shopping.WriteText(w, shoppingList)
+	default:
+		err = json.NewEncoder(w).Encode(shoppingList)
+	}
+	if err != nil {
+		log.Printf("Error writing shopping list: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/shopping"
+)
+
+// TestShoppingListInventory controla que el inventario del dueño de la lista se descuente solo
+// cuando la pide el dueño: ni otro usuario, ni un admin, ni un pedido anonimo lo ven
+func TestShoppingListInventory(t *testing.T) {
+	api := newTestAPI(t)
+	ctx := context.Background()
+	owner := api.user("owner", false)
+	api.terms(
+		models.TaxonomyTerm{Kind: models.TaxonomyTool, Slug: "drill", LabelES: "Taladro", LabelEN: "Drill"},
+		models.TaxonomyTerm{Kind: models.TaxonomyTool, Slug: "jigsaw", LabelES: "Sierra caladora", LabelEN: "Jigsaw"},
+	)
+	project := api.project(owner, "Banco")
+	project.Tools = []string{"drill", "jigsaw"}
+	api.expect(http.StatusOK, "PUT", urlf("/projects/%d", project.ID), owner, project)
+	api.expect(http.StatusCreated, "POST", urlf("/users/%d/inventory", owner.ID), owner,
+		models.InventoryItem{Kind: models.InventoryTool, Name: "Taladro"})
+
+	list := models.ProjectList{UserID: owner.ID, Name: "Finde", IsPublic: true}
+	if err := api.repos.ProjectLists.Create(ctx, &list); err != nil {
+		t.Fatal(err)
+	}
+	item := models.ProjectListItem{ProjectListID: list.ID, ProjectID: project.ID}
+	if err := api.repos.ProjectLists.AddItem(ctx, &item); err != nil {
+		t.Fatal(err)
+	}
+	path := urlf("/project-lists/%d/shopping-list", list.ID)
+
+	got := decode[shopping.List](t, api.expect(http.StatusOK, "GET", path, owner, nil))
+	if !got.InventoryApplied || len(got.Owned) != 1 || got.Owned[0].Name != "drill" ||
+		len(got.Tools) != 1 || got.Tools[0].Name != "jigsaw" {
+		t.Errorf("owner: list = %+v, want drill owned and jigsaw to buy", got)
+	}
+
+	for name, user := range map[string]*models.User{
+		"another user": api.user("other", false),
+		"admin":        api.user("admin", true),
+		"anonymous":    nil,
+	} {
+		got := decode[shopping.List](t, api.expect(http.StatusOK, "GET", path, user, nil))
+		if got.InventoryApplied || len(got.Owned) != 0 || len(got.Tools) != 2 {
+			t.Errorf("%s: list = %+v, want both tools to buy and no inventory", name, got)
+		}
+	}
+
+	// la lista en texto tampoco deja ver el inventario
+	rec := api.expect(http.StatusOK, "GET", path+"?format=text", nil, nil)
+	if strings.Contains(rec.Body.String(), "INVENTORY") {
+		t.Errorf("anonymous text list shows the inventory:\n%s", rec.Body)
+	}
+	rec = api.expect(http.StatusOK, "GET", path+"?format=csv", owner, nil)
+	if row := urlf("tools,drill,,,,%d,,true\n", project.ID); !strings.Contains(rec.Body.String(), row) {
+		t.Errorf("owner csv = %s, want the row %q", rec.Body, row)
+	}
+}
@@ -0,0 +1,349 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// Limites de los pasos del tutorial
+const (
+	maxProjectSteps    = 100
+	maxStepTitle       = 150
+	maxStepBody        = 20000
+	maxStepImages      = 10
+	maxStepImageURL    = 2048
+	maxStepMinutes     = 10000
+	maxStepSafetyNotes = 10
+	maxStepSafetyNote  = 500
+)
+
+// stepInput es un paso como lo manda el dueño del proyecto. Position solo se usa al crear.
+type stepInput struct {
+	Position         *int     `json:"position"`
+	Title            string   `json:"title"`
+	Body             string   `json:"body"`
+	Images           []string `json:"images"`
+	EstimatedMinutes *int     `json:"estimated_minutes"`
+	Tools            []string `json:"tools"`
+	SafetyNotes      []string `json:"safety_notes"`
+}
+
+// GetProjectSteps lista los pasos del tutorial de un proyecto en orden - Requiere id
+func (h *Handler) GetProjectSteps(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.stepsProject(w, r)
+	if !ok {
+		return
+	}
+	steps, err := h.Steps.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching steps", http.StatusInternalServerError)
+		return
+	}
+	if steps == nil {
+		steps = []models.ProjectStep{}
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"items": steps}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// GetProjectStep obtiene un paso - Requiere id y step_id
+func (h *Handler) GetProjectStep(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.stepsProject(w, r)
+	if !ok {
+		return
+	}
+	step, ok := h.findStep(w, r, project)
+	if !ok {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(step); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PostProjectStep agrega un paso al tutorial - Requiere id, solo el dueño. Sin position va al final.
+func (h *Handler) PostProjectStep(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.stepsProject(w, r)
+	if !ok {
+		return
+	}
+	if !authorize(w, policies.ManageProject(currentUser(r), project)) {
+		return
+	}
+	var input stepInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+
+	steps, err := h.Steps.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching steps", http.StatusInternalServerError)
+		return
+	}
+	if len(steps) >= maxProjectSteps {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "a tutorial cannot have more than " + strconv.Itoa(maxProjectSteps) + " steps",
+		})
+		return
+	}
+
+	step := models.ProjectStep{ProjectID: project.ID, Position: len(steps)}
+	if input.Position != nil {
+		step.Position = *input.Position
+	}
+	if !h.validStep(w, r, project, input, &step) {
+		return
+	}
+	if err := h.Steps.Create(r.Context(), &step, h.revision(r, nil)); err != nil {
+		writeStepError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&step); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PutProjectStep reemplaza el contenido de un paso - Requiere id y step_id, solo el dueño. Para
+// moverlo se usa PUT /projects/{id}/steps/order.
+func (h *Handler) PutProjectStep(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.stepsProject(w, r)
+	if !ok {
+		return
+	}
+	if !authorize(w, policies.ManageProject(currentUser(r), project)) {
+		return
+	}
+	step, ok := h.findStep(w, r, project)
+	if !ok {
+		return
+	}
+	var input stepInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	if !h.validStep(w, r, project, input, step) {
+		return
+	}
+	if err := h.Steps.Update(r.Context(), step, h.revision(r, nil)); err != nil {
+		writeStepError(w, err)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(step); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// DeleteProjectStep borra un paso - Requiere id y step_id, solo el dueño
+func (h *Handler) DeleteProjectStep(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.stepsProject(w, r)
+	if !ok {
+		return
+	}
+	if !authorize(w, policies.ManageProject(currentUser(r), project)) {
+		return
+	}
+	step, ok := h.findStep(w, r, project)
+	if !ok {
+		return
+	}
+	if err := h.Steps.Delete(r.Context(), step, h.revision(r, nil)); err != nil {
+		writeStepError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "Step deleted successfully"})
+}
+
+// ReorderProjectSteps cambia el orden de los pasos - Requiere id y {"ids": [...]} con todos los
+// pasos del proyecto en el nuevo orden, solo el dueño
+func (h *Handler) ReorderProjectSteps(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.stepsProject(w, r)
+	if !ok {
+		return
+	}
+	if !authorize(w, policies.ManageProject(currentUser(r), project)) {
+		return
+	}
+	var body struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+
+	steps, err := h.Steps.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching steps", http.StatusInternalServerError)
+		return
+	}
+	current := make([]int64, len(steps))
+	for i, step := range steps {
+		current[i] = step.ID
+	}
+	wanted := slices.Clone(body.IDs)
+	slices.Sort(current)
+	slices.Sort(wanted)
+	if !slices.Equal(current, wanted) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "ids must list every step of the project exactly once"})
+		return
+	}
+
+	if err := h.Steps.Reorder(r.Context(), project.ID, body.IDs, h.revision(r, nil)); err != nil {
+		writeStepError(w, err)
+		return
+	}
+	steps, err = h.Steps.ListByProject(r.Context(), project.ID)
+	if err != nil {
+		http.Error(w, "Error fetching steps", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"items": steps}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// validStep limpia y controla el contenido de un paso y lo copia en step. Las herramientas se pasan
+// a slugs y tienen que ser herramientas del proyecto. Responde 400 con los errores por campo.
+func (h *Handler) validStep(
+	w http.ResponseWriter, r *http.Request, project *models.Project, input stepInput, step *models.ProjectStep,
+) bool {
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return false
+	}
+
+	// las imagenes que el paso ya tenia se aceptan aunque no sean uploads
+	kept := step.Images
+	step.Title = strings.TrimSpace(input.Title)
+	step.Body = strings.TrimSpace(input.Body)
+	step.BodyHTML = h.Markdown.Render(step.Body)
+	step.EstimatedMinutes = input.EstimatedMinutes
+	step.Images = cleanStrings(input.Images)
+	step.SafetyNotes = cleanStrings(input.SafetyNotes)
+	tools, unknown := index.Canonicalize(models.TaxonomyTool, input.Tools)
+	step.Tools = tools
+
+	errs := filterErrors{}
+	if step.Title == "" || utf8.RuneCountInString(step.Title) > maxStepTitle {
+		errs.add("title", "title must have between 1 and %d characters", maxStepTitle)
+	}
+	if utf8.RuneCountInString(step.Body) > maxStepBody {
+		errs.add("body", "body cannot exceed %d characters", maxStepBody)
+	}
+	if len(step.Images) > maxStepImages {
+		errs.add("images", "a step cannot have more than %d images", maxStepImages)
+	}
+	for _, image := range step.Images {
+		if len(image) > maxStepImageURL {
+			errs.add("images", "image URLs cannot exceed %d characters", maxStepImageURL)
+		}
+	}
+	owners := uploadOwners(r, project.Owner)
+	if err := h.checkUploads(r.Context(), errs, "images", step.Images, kept, owners...); err != nil {
+		http.Error(w, "Error checking images", http.StatusInternalServerError)
+		return false
+	}
+	if m := step.EstimatedMinutes; m != nil && (*m < 0 || *m > maxStepMinutes) {
+		errs.add("estimated_minutes", "estimated_minutes must be between 0 and %d", maxStepMinutes)
+	}
+	if len(unknown) > 0 {
+		errs.add("tools", "unknown tools: %s", strings.Join(unknown, ", "))
+	}
+	for _, tool := range step.Tools {
+		if !slices.Contains(project.Tools, tool) {
+			errs.add("tools", "tool %q is not one of the project's tools", tool)
+		}
+	}
+	if len(step.SafetyNotes) > maxStepSafetyNotes {
+		errs.add("safety_notes", "a step cannot have more than %d safety notes", maxStepSafetyNotes)
+	}
+	for _, note := range step.SafetyNotes {
+		if utf8.RuneCountInString(note) > maxStepSafetyNote {
+			errs.add("safety_notes", "safety notes cannot exceed %d characters", maxStepSafetyNote)
+		}
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Invalid step", "errors": errs})
+		return false
+	}
+	return true
+}
+
+// stepsProject lee el proyecto de la ruta; responde 404 si no existe
+func (h *Handler) stepsProject(w http.ResponseWriter, r *http.Request) (*models.Project, bool) {
+	id, err := pathID(r, "id")
+	var project *models.Project
+	if err == nil {
+		project, err = h.Projects.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+		return nil, false
+	}
+	return project, true
+}
+
+// findStep lee el paso de la ruta; responde 404 si no existe o es de otro proyecto
+func (h *Handler) findStep(w http.ResponseWriter, r *http.Request, project *models.Project) (*models.ProjectStep, bool) {
+	id, err := pathID(r, "step_id")
+	var step *models.ProjectStep
+	if err == nil {
+		step, err = h.Steps.FindByID(r.Context(), id)
+	}
+	if err != nil || step.ProjectID != project.ID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Step not found"})
+		return nil, false
+	}
+	return step, true
+}
+
+func writeStepError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Step not found"})
+	case errors.Is(err, repositories.ErrMissingReference):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Project not found"})
+	default:
+		log.Printf("Error saving step: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the step"})
+	}
+}
+
+// cleanStrings saca los valores vacios y los espacios de los costados; nunca devuelve nil, porque
+// las columnas de arrays no aceptan NULL
+func cleanStrings(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
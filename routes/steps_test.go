@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// steps devuelve los titulos de los pasos del proyecto en orden, controlando que las posiciones
+// vayan de 0 en adelante sin huecos
+func (api *testAPI) steps(project *models.Project) []string {
+	api.t.Helper()
+	items := decode[struct {
+		Items []models.ProjectStep `json:"items"`
+	}](api.t, api.expect(http.StatusOK, "GET", urlf("/projects/%d/steps", project.ID), nil, nil)).Items
+	titles := make([]string, len(items))
+	for i, step := range items {
+		if step.Position != i {
+			api.t.Errorf("step %q at position %d, want %d", step.Title, step.Position, i)
+		}
+		titles[i] = step.Title
+	}
+	return titles
+}
+
+// addStep agrega un paso; position y minutes pueden ser nil
+func (api *testAPI) addStep(project *models.Project, owner *models.User, title string, position, minutes *int) int64 {
+	api.t.Helper()
+	body := map[string]any{"title": title, "position": position, "estimated_minutes": minutes}
+	path := urlf("/projects/%d/steps", project.ID)
+	return decode[models.ProjectStep](api.t, api.expect(http.StatusCreated, "POST", path, owner, body)).ID
+}
+
+func TestStepPositions(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	project := api.project(owner, "Banco")
+	for _, title := range []string{"Cortar", "Lijar", "Pintar"} {
+		api.addStep(project, owner, title, nil, nil)
+	}
+
+	// un paso en el medio corre a los que siguen
+	middle := api.addStep(project, owner, "Encolar", ptr(1), nil)
+	if titles := api.steps(project); !slices.Equal(titles, []string{"Cortar", "Encolar", "Lijar", "Pintar"}) {
+		t.Errorf("after inserting at 1: %q", titles)
+	}
+
+	// borrarlo cierra el hueco
+	api.expect(http.StatusOK, "DELETE", urlf("/projects/%d/steps/%d", project.ID, middle), owner, nil)
+	if titles := api.steps(project); !slices.Equal(titles, []string{"Cortar", "Lijar", "Pintar"}) {
+		t.Errorf("after deleting: %q", titles)
+	}
+}
+
+func TestReorderSteps(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	project := api.project(owner, "Banco")
+	cut := api.addStep(project, owner, "Cortar", nil, nil)
+	sand := api.addStep(project, owner, "Lijar", nil, nil)
+	paint := api.addStep(project, owner, "Pintar", nil, nil)
+	other := api.addStep(api.project(owner, "Mesa"), owner, "Medir", nil, nil)
+
+	path := urlf("/projects/%d/steps/order", project.ID)
+	for name, ids := range map[string][]int64{
+		"missing a step":       {paint, cut},
+		"repeated step":        {paint, cut, cut},
+		"repeated and missing": {paint, paint, cut, sand, sand},
+		"another project":      {paint, cut, other},
+		"no ids":               nil,
+	} {
+		if rec := api.do("PUT", path, owner, map[string]any{"ids": ids}); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, rec.Code)
+		}
+	}
+	if titles := api.steps(project); !slices.Equal(titles, []string{"Cortar", "Lijar", "Pintar"}) {
+		t.Errorf("a rejected reorder changed the steps: %q", titles)
+	}
+
+	api.expect(http.StatusOK, "PUT", path, owner, map[string]any{"ids": []int64{paint, cut, sand}})
+	if titles := api.steps(project); !slices.Equal(titles, []string{"Pintar", "Cortar", "Lijar"}) {
+		t.Errorf("after reordering: %q", titles)
+	}
+	api.expect(http.StatusForbidden, "PUT", path, api.user("other", false),
+		map[string]any{"ids": []int64{cut, sand, paint}})
+}
+
+// TestStepsTimeToBuild controla que time_to_build sea la suma de los pasos solo cuando todos tienen
+// estimacion, y que mientras tanto PUT /projects/{id} no lo cambie
+func TestStepsTimeToBuild(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+	project := api.project(owner, "Banco")
+	path := urlf("/projects/%d", project.ID)
+	put := func(minutes int) int {
+		t.Helper()
+		body := *project
+		body.TimeToBuild = minutes
+		return decode[models.Project](t, api.expect(http.StatusOK, "PUT", path, owner, body)).TimeToBuild
+	}
+	current := func() int {
+		t.Helper()
+		return decode[models.Project](t, api.expect(http.StatusOK, "GET", path, nil, nil)).TimeToBuild
+	}
+
+	if got := put(90); got != 90 {
+		t.Errorf("without steps: time_to_build = %d, want the author's 90", got)
+	}
+	cut := api.addStep(project, owner, "Cortar", nil, ptr(30))
+	if got := current(); got != 30 {
+		t.Errorf("one estimated step: time_to_build = %d, want 30", got)
+	}
+	sand := api.addStep(project, owner, "Lijar", nil, nil)
+	if got := put(120); got != 120 {
+		t.Errorf("a step without estimate: time_to_build = %d, want the author's 120", got)
+	}
+
+	api.expect(http.StatusOK, "PUT", urlf("/projects/%d/steps/%d", project.ID, sand), owner,
+		map[string]any{"title": "Lijar", "estimated_minutes": 45})
+	if got := current(); got != 75 {
+		t.Errorf("every step estimated: time_to_build = %d, want 75", got)
+	}
+	if got := put(500); got != 75 {
+		t.Errorf("PUT with derived time: time_to_build = %d, want 75", got)
+	}
+	api.expect(http.StatusOK, "DELETE", urlf("/projects/%d/steps/%d", project.ID, cut), owner, nil)
+	if got := current(); got != 45 {
+		t.Errorf("after deleting a step: time_to_build = %d, want 45", got)
+	}
+}
+
+func TestProjectTutorialBecomesStep(t *testing.T) {
+	api := newTestAPI(t)
+	owner := api.user("owner", false)
+
+	project := decode[models.Project](t, api.expect(http.StatusOK, "POST", "/projects", owner, models.Project{
+		Title: "Estante", Tutorial: "  Cortar las tablas y lijarlas.  ", IsPublic: true,
+	}))
+	items := decode[struct {
+		Items []models.ProjectStep `json:"items"`
+	}](t, api.expect(http.StatusOK, "GET", urlf("/projects/%d/steps", project.ID), nil, nil)).Items
+	if len(items) != 1 || items[0].Title != "Tutorial" || items[0].Body != "Cortar las tablas y lijarlas." {
+		t.Fatalf("steps = %+v, want one step named Tutorial with the text", items)
+	}
+	if project.Tutorial != "1. Tutorial\n\nCortar las tablas y lijarlas." {
+		t.Errorf("tutorial = %q, want the text of the step", project.Tutorial)
+	}
+
+	empty := decode[models.Project](t, api.expect(http.StatusOK, "POST", "/projects", owner, models.Project{
+		Title: "Mesa", Tutorial: "   ", IsPublic: true,
+	}))
+	if titles := api.steps(&empty); len(titles) != 0 {
+		t.Errorf("blank tutorial made steps %q", titles)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/taxonomy"
+)
+
+// taxonomyTTL es cada cuanto se vuelve a leer la taxonomia; los cambios hechos por esta instancia
+// se ven enseguida
+const taxonomyTTL = 5 * time.Minute
+
+// taxonomyCache guarda el indice de la taxonomia, que cambia muy poco y se consulta en cada
+// alta o edicion de proyecto y en cada busqueda
+type taxonomyCache struct {
+	mu      sync.Mutex
+	index   *taxonomy.Index
+	expires time.Time
+}
+
+// taxonomyIndex devuelve el indice de la taxonomia, releyendolo si el cache expiro
+func (h *Handler) taxonomyIndex(ctx context.Context) (*taxonomy.Index, error) {
+	h.taxonomy.mu.Lock()
+	defer h.taxonomy.mu.Unlock()
+
+	if h.taxonomy.index != nil && time.Now().Before(h.taxonomy.expires) {
+		return h.taxonomy.index, nil
+	}
+	terms, err := h.Taxonomy.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h.taxonomy.index = taxonomy.NewIndex(terms)
+	h.taxonomy.expires = time.Now().Add(taxonomyTTL)
+	return h.taxonomy.index, nil
+}
+
+// invalidateTaxonomy descarta el indice despues de escribir un termino
+func (h *Handler) invalidateTaxonomy() {
+	h.taxonomy.mu.Lock()
+	defer h.taxonomy.mu.Unlock()
+	h.taxonomy.index = nil
+}
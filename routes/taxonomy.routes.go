@@ -0,0 +1,286 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/carpentry-hub/woodys-backend/inventory"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/taxonomy"
+	"github.com/gorilla/mux"
+)
+
+// Limites de un termino de la taxonomia
+const (
+	maxTaxonomySlug     = 50
+	maxTaxonomyLabel    = 100
+	maxTaxonomySynonyms = 50
+)
+
+var taxonomySlug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// taxonomyPaths va del segmento de la ruta (/taxonomy/{kind}) al tipo de termino
+var taxonomyPaths = map[string]string{
+	"tools":        models.TaxonomyTool,
+	"materials":    models.TaxonomyMaterial,
+	"styles":       models.TaxonomyStyle,
+	"environments": models.TaxonomyEnvironment,
+}
+
+// GetTaxonomy lista todos los terminos agrupados por tipo: tools, materials, styles y environments
+func (h *Handler) GetTaxonomy(w http.ResponseWriter, r *http.Request) {
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+	response := map[string][]models.TaxonomyTerm{}
+	for path, kind := range taxonomyPaths {
+		response[path] = termsOrEmpty(index.Terms(kind))
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// GetTaxonomyKind lista los terminos de un tipo - Requiere kind
+func (h *Handler) GetTaxonomyKind(w http.ResponseWriter, r *http.Request) {
+	kind, ok := taxonomyKind(w, r)
+	if !ok {
+		return
+	}
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]any{"items": termsOrEmpty(index.Terms(kind))}); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PostTaxonomyTerm agrega un termino - Requiere kind, solo administradores
+func (h *Handler) PostTaxonomyTerm(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManageTaxonomy(currentUser(r))) {
+		return
+	}
+	kind, ok := taxonomyKind(w, r)
+	if !ok {
+		return
+	}
+
+	var term models.TaxonomyTerm
+	if err := json.NewDecoder(r.Body).Decode(&term); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	term.ID = 0
+	term.Kind = kind
+	if !taxonomySlug.MatchString(term.Slug) || len(term.Slug) > maxTaxonomySlug {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "slug must be lowercase words separated by hyphens, up to " + strconv.Itoa(maxTaxonomySlug) + " characters",
+		})
+		return
+	}
+	if !h.validTaxonomyTerm(r.Context(), w, &term) {
+		return
+	}
+
+	if err := h.Taxonomy.Create(r.Context(), &term); err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	h.invalidateTaxonomy()
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(&term); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// PutTaxonomyTerm cambia etiquetas, sinonimos o padre de un termino - Requiere kind y slug, solo
+// administradores. El slug no cambia porque es lo que guardan los proyectos.
+func (h *Handler) PutTaxonomyTerm(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, policies.ManageTaxonomy(currentUser(r))) {
+		return
+	}
+	kind, ok := taxonomyKind(w, r)
+	if !ok {
+		return
+	}
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return
+	}
+	existing, ok := index.Term(kind, mux.Vars(r)["slug"])
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Term not found"})
+		return
+	}
+
+	var updated models.TaxonomyTerm
+	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	existing.Parent = updated.Parent
+	existing.LabelES = updated.LabelES
+	existing.LabelEN = updated.LabelEN
+	existing.Synonyms = updated.Synonyms
+	if !h.validTaxonomyTerm(r.Context(), w, &existing) {
+		return
+	}
+
+	if err := h.Taxonomy.Update(r.Context(), &existing); err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	h.invalidateTaxonomy()
+	if err := json.NewEncoder(w).Encode(&existing); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// validTaxonomyTerm limpia y controla etiquetas, sinonimos y padre. Ningun nombre puede ser ya de
+// otro termino del mismo tipo, porque entonces un valor resolveria a dos slugs.
+func (h *Handler) validTaxonomyTerm(ctx context.Context, w http.ResponseWriter, term *models.TaxonomyTerm) bool {
+	index, err := h.taxonomyIndex(ctx)
+	if err != nil {
+		http.Error(w, "Error fetching taxonomy", http.StatusInternalServerError)
+		return false
+	}
+
+	term.LabelES = strings.TrimSpace(term.LabelES)
+	term.LabelEN = strings.TrimSpace(term.LabelEN)
+	synonyms := make([]string, 0, len(term.Synonyms))
+	for _, synonym := range term.Synonyms {
+		if synonym = strings.TrimSpace(synonym); synonym != "" {
+			synonyms = append(synonyms, synonym)
+		}
+	}
+	term.Synonyms = synonyms
+	if term.Parent != nil && *term.Parent == "" {
+		term.Parent = nil
+	}
+
+	status, message := http.StatusBadRequest, ""
+	switch {
+	case inventory.Fold(term.LabelES) == "" || inventory.Fold(term.LabelEN) == "":
+		message = "label_es and label_en cannot be empty"
+	case utf8.RuneCountInString(term.LabelES) > maxTaxonomyLabel, utf8.RuneCountInString(term.LabelEN) > maxTaxonomyLabel:
+		message = "labels cannot exceed " + strconv.Itoa(maxTaxonomyLabel) + " characters"
+	case len(term.Synonyms) > maxTaxonomySynonyms:
+		message = "a term cannot have more than " + strconv.Itoa(maxTaxonomySynonyms) + " synonyms"
+	}
+	if message == "" && term.Parent != nil {
+		if _, ok := index.Term(term.Kind, *term.Parent); !ok {
+			message = "parent " + *term.Parent + " does not exist"
+		} else if *term.Parent == term.Slug || slices.Contains(index.Ancestors(term.Kind, *term.Parent), term.Slug) {
+			message = "parent cannot be the term itself or one of its children"
+		}
+	}
+	if message == "" {
+		for _, name := range append([]string{term.Slug}, taxonomy.Names(*term)...) {
+			if slug := index.Lookup(term.Kind, name); slug != "" && slug != term.Slug {
+				status, message = http.StatusConflict, `"`+name+`" already names `+slug
+				break
+			}
+		}
+	}
+	if message != "" {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"message": message})
+		return false
+	}
+	return true
+}
+
+func writeTaxonomyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrConflict):
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"message": "A term with that slug already exists"})
+	case errors.Is(err, repositories.ErrMissingReference):
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "parent does not exist"})
+	case errors.Is(err, repositories.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Term not found"})
+	default:
+		log.Printf("Error saving taxonomy term: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the term"})
+	}
+}
+
+// taxonomyKind lee el tipo de termino de la ruta; responde 404 si no existe
+func taxonomyKind(w http.ResponseWriter, r *http.Request) (string, bool) {
+	kind, ok := taxonomyPaths[mux.Vars(r)["kind"]]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "kind must be tools, materials, styles or environments"})
+	}
+	return kind, ok
+}
+
+func termsOrEmpty(terms []models.TaxonomyTerm) []models.TaxonomyTerm {
+	if terms == nil {
+		return []models.TaxonomyTerm{}
+	}
+	return terms
+}
+
+// canonicalize pasa el proyecto a slugs de la taxonomia; responde 400 si algun valor no esta
+func (h *Handler) canonicalize(w http.ResponseWriter, r *http.Request, project *models.Project) bool {
+	index, err := h.taxonomyIndex(r.Context())
+	if err != nil {
+		log.Printf("Error loading taxonomy: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the project"})
+		return false
+	}
+	unknown := index.CanonicalizeProject(project, false)
+	if len(unknown) > 0 {
+		errs := filterErrors{}
+		for field, values := range unknown {
+			errs.add(field, "unknown %s: %s", field, strings.Join(values, ", "))
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Unknown taxonomy values", "errors": errs})
+		return false
+	}
+	return true
+}
+
+// resolveFilter pasa a slugs los valores de los filtros de la busqueda. Los que no estan en la
+// taxonomia quedan como vinieron, para no romper busquedas sobre proyectos todavia sin migrar.
+func resolveFilter(index *taxonomy.Index, filter *repositories.ProjectFilter) {
+	resolve := func(kind string, values []string) []string {
+		for i, value := range values {
+			if slug, ok := index.Resolve(kind, value); ok {
+				values[i] = slug
+			}
+		}
+		return values
+	}
+	filter.Tools = resolve(models.TaxonomyTool, filter.Tools)
+	filter.Materials = resolve(models.TaxonomyMaterial, filter.Materials)
+	filter.MainMaterial = resolve(models.TaxonomyMaterial, filter.MainMaterial)
+	filter.Style = resolve(models.TaxonomyStyle, filter.Style)
+	filter.Environment = resolve(models.TaxonomyEnvironment, filter.Environment)
+}
@@ -0,0 +1,455 @@
+// Package routes proporciona los servicios de la api
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/storage"
+	"github.com/carpentry-hub/woodys-backend/uploads"
+	"github.com/gorilla/mux"
+)
+
+// Valores por defecto de los uploads
+const (
+	DefaultUploadMaxBytes = 10 << 20
+	DefaultUploadQuota    = 200 << 20
+	DefaultUploadBaseURL  = "/uploads"
+)
+
+// multipartOverhead es lo que se tolera en el cuerpo de POST /uploads ademas del archivo: limites,
+// headers de cada parte y otros campos del formulario
+const multipartOverhead = 64 << 10
+
+// PostUpload sube una imagen - multipart/form-data con el archivo en el campo "file". La imagen se
+// reconoce por su contenido, se le sacan los metadatos y cuenta para la cuota de quien la sube.
+func (h *Handler) PostUpload(w http.ResponseWriter, r *http.Request) {
+	user := currentUser(r)
+	if !authorize(w, policies.Authenticated(user)) {
+		return
+	}
+	if h.Storage == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Uploads are not available"})
+		return
+	}
+	data, ok := h.readUploadFile(w, r)
+	if !ok {
+		return
+	}
+
+	img, err := uploads.Prepare(data)
+	if !writeImageError(w, err) {
+		return
+	}
+	upload, ok := h.storeUpload(w, r, user.ID, img)
+	if !ok {
+		return
+	}
+
+	h.resolveUpload(upload)
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(upload); err != nil {
+		log.Fatalf("Failed to encode json: %v", err)
+	}
+}
+
+// writeImageError responde 415 si la imagen no es de un formato aceptado y 400 si no se puede leer;
+// devuelve true si no hubo error
+func writeImageError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, uploads.ErrUnsupportedType):
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+	}
+	return false
+}
+
+// storeUpload guarda img en el storage y la registra como upload de userID, dentro de su cuota, y
+// la encola para generar las variantes. Responde 403 si no entra en la cuota.
+func (h *Handler) storeUpload(
+	w http.ResponseWriter, r *http.Request, userID int64, img *uploads.Image,
+) (*models.Upload, bool) {
+	upload := &models.Upload{
+		ID:          uploads.NewID(),
+		UserID:      userID,
+		ContentType: img.ContentType,
+		Size:        int64(len(img.Data)),
+		Width:       img.Width,
+		Height:      img.Height,
+		SHA256:      img.SHA256,
+	}
+	// la cuota se vuelve a controlar al guardar; esto evita escribir archivos que no van a entrar
+	used, err := h.Uploads.Usage(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Error fetching upload usage", http.StatusInternalServerError)
+		return nil, false
+	}
+	if used+upload.Size > h.UploadQuota {
+		writeQuotaExceeded(w, used, h.UploadQuota)
+		return nil, false
+	}
+
+	key := uploads.Key(upload.ID, uploads.OriginalName)
+	if err := h.Storage.Put(r.Context(), key, img.Data, img.ContentType); err != nil {
+		log.Printf("Error storing upload %s: %v", upload.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to store the image"})
+		return nil, false
+	}
+	if err := h.Uploads.Create(r.Context(), upload, h.UploadQuota); err != nil {
+		if err := h.Storage.Delete(context.WithoutCancel(r.Context()), key); err != nil {
+			log.Printf("Error removing upload %s: %v", upload.ID, err)
+		}
+		if errors.Is(err, repositories.ErrQuotaExceeded) {
+			used, _ := h.Uploads.Usage(r.Context(), userID)
+			writeQuotaExceeded(w, used, h.UploadQuota)
+			return nil, false
+		}
+		log.Printf("Error saving upload %s: %v", upload.ID, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the upload"})
+		return nil, false
+	}
+
+	if h.UploadWorker != nil {
+		h.UploadWorker.Enqueue(upload.ID)
+	}
+	return upload, true
+}
+
+// GetUpload devuelve la imagen de un upload - Requiere id. Un upload no cambia nunca, asi que se
+// puede guardar en cache para siempre.
+func (h *Handler) GetUpload(w http.ResponseWriter, r *http.Request) {
+	upload, ok := h.findUpload(w, r)
+	if !ok {
+		return
+	}
+	h.serveUploadFile(w, r, upload.ID, uploads.OriginalName, upload.ContentType, upload.Size, upload.SHA256)
+}
+
+// GetUploadVariant devuelve una version reducida de un upload - Requiere id y el nombre de la
+// variante (w480.jpg), como vienen en variants
+func (h *Handler) GetUploadVariant(w http.ResponseWriter, r *http.Request) {
+	upload, ok := h.findUpload(w, r)
+	if !ok {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	for _, variant := range upload.Variants {
+		if variant.Name == name {
+			// el contenido de una variante depende solo del original y del nombre
+			h.serveUploadFile(w, r, upload.ID, name, variant.ContentType, variant.Size, upload.SHA256+"-"+name)
+			return
+		}
+	}
+	writeMessage(w, http.StatusNotFound, "Variant not found")
+}
+
+// serveUploadFile manda el archivo name de un upload con cache inmutable y ETag
+func (h *Handler) serveUploadFile(
+	w http.ResponseWriter, r *http.Request, id, name, contentType string, size int64, etag string,
+) {
+	if h.Storage == nil {
+		writeMessage(w, http.StatusServiceUnavailable, "Uploads are not available")
+		return
+	}
+	w.Header().Set("Cache-Control", immutableCache)
+	if notModified(w, r, `"`+etag+`"`) {
+		return
+	}
+
+	body, err := h.Storage.Get(r.Context(), uploads.Key(id, name))
+	if err != nil {
+		w.Header().Del("Cache-Control")
+		w.Header().Del("ETag")
+		if errors.Is(err, storage.ErrNotFound) {
+			writeMessage(w, http.StatusNotFound, "Upload not found")
+			return
+		}
+		log.Printf("Error reading %s of upload %s: %v", name, id, err)
+		http.Error(w, "Error reading the upload", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+	if err := writeImage(w, contentType, size, body); err != nil {
+		log.Printf("Error writing %s of upload %s: %v", name, id, err)
+	}
+}
+
+// DeleteUpload borra un upload - Requiere id, solo quien lo subio. Si un proyecto o un paso
+// todavia lo usa responde 409.
+func (h *Handler) DeleteUpload(w http.ResponseWriter, r *http.Request) {
+	upload, ok := h.findUpload(w, r)
+	if !ok {
+		return
+	}
+	if !authorize(w, policies.ManageUpload(currentUser(r), upload)) {
+		return
+	}
+	inUse, err := h.Uploads.InUse(r.Context(), upload.ID, h.uploadURL(upload.ID))
+	if err != nil {
+		http.Error(w, "Error checking the upload", http.StatusInternalServerError)
+		return
+	}
+	if inUse {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"message": "The upload is still used by a project or as an avatar"})
+		return
+	}
+	if err := h.removeUpload(r.Context(), upload); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete the upload"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "Upload deleted successfully"})
+}
+
+// removeUpload borra el registro del upload y despues sus archivos. Los archivos que no se pueden
+// borrar solo se registran en el log: el upload ya no existe para la api.
+func (h *Handler) removeUpload(ctx context.Context, upload *models.Upload) error {
+	if err := h.Uploads.Delete(ctx, upload.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+	if h.Storage == nil {
+		return nil
+	}
+	names := []string{uploads.OriginalName}
+	for _, variant := range upload.Variants {
+		names = append(names, variant.Name)
+	}
+	for _, name := range names {
+		if err := h.Storage.Delete(context.WithoutCancel(ctx), uploads.Key(upload.ID, name)); err != nil {
+			log.Printf("Error removing %s of upload %s: %v", name, upload.ID, err)
+		}
+	}
+	return nil
+}
+
+// readUploadFile lee el campo "file" del formulario sin pasar de UploadMaxBytes; responde 400 o 413
+func (h *Handler) readUploadFile(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.UploadMaxBytes+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Expected a multipart/form-data body"})
+		return nil, false
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"message": "The form has no file field"})
+			return nil, false
+		}
+		if err != nil {
+			writeUploadReadError(w, err, h.UploadMaxBytes)
+			return nil, false
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(part, h.UploadMaxBytes+1))
+		part.Close()
+		if err != nil {
+			writeUploadReadError(w, err, h.UploadMaxBytes)
+			return nil, false
+		}
+		if int64(len(data)) > h.UploadMaxBytes {
+			writeUploadReadError(w, &http.MaxBytesError{Limit: h.UploadMaxBytes}, h.UploadMaxBytes)
+			return nil, false
+		}
+		if len(data) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"message": "The file is empty"})
+			return nil, false
+		}
+		return data, true
+	}
+}
+
+func writeUploadReadError(w http.ResponseWriter, err error, limit int64) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "the file cannot exceed " + strconv.FormatInt(limit, 10) + " bytes",
+		})
+		return
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Invalid multipart body"})
+}
+
+func writeQuotaExceeded(w http.ResponseWriter, used, quota int64) {
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(map[string]any{"message": "Upload quota exceeded", "used": used, "quota": quota})
+}
+
+// findUpload lee el upload de la ruta; responde 404 si no existe
+func (h *Handler) findUpload(w http.ResponseWriter, r *http.Request) (*models.Upload, bool) {
+	id := mux.Vars(r)["id"]
+	var upload *models.Upload
+	err := repositories.ErrNotFound
+	if uploads.ValidID(id) {
+		upload, err = h.Uploads.FindByID(r.Context(), id)
+	}
+	if err != nil {
+		writeMessage(w, http.StatusNotFound, "Upload not found")
+		return nil, false
+	}
+	h.resolveUpload(upload)
+	return upload, true
+}
+
+// uploadURL es la url estable de un upload
+func (h *Handler) uploadURL(id string) string {
+	return strings.TrimRight(h.UploadBaseURL, "/") + "/" + id
+}
+
+// resolveUpload completa la url del upload y la de cada variante
+func (h *Handler) resolveUpload(upload *models.Upload) {
+	upload.URL = h.uploadURL(upload.ID)
+	if upload.Variants == nil {
+		upload.Variants = []models.UploadVariant{}
+	}
+	for i := range upload.Variants {
+		upload.Variants[i].URL = upload.URL + "/" + upload.Variants[i].Name
+	}
+}
+
+// attachImages completa portrait_image e image_sets de cada proyecto con las variantes y el
+// placeholder de sus uploads. Las imagenes que no son uploads van solo con la url.
+func (h *Handler) attachImages(ctx context.Context, projects ...*models.Project) error {
+	var ids []string
+	for _, project := range projects {
+		for _, url := range append([]string{project.Portrait}, project.Images...) {
+			if id, ok := h.uploadID(url); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	found, err := h.Uploads.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	sets := make(map[string]models.ImageSet, len(found))
+	for i := range found {
+		h.resolveUpload(&found[i])
+		sets[found[i].URL] = found[i].ImageSet()
+	}
+	imageSet := func(url string) models.ImageSet {
+		if set, ok := sets[url]; ok {
+			return set
+		}
+		return models.ImageSet{URL: url}
+	}
+	for _, project := range projects {
+		project.PortraitImage = nil
+		if project.Portrait != "" {
+			set := imageSet(project.Portrait)
+			project.PortraitImage = &set
+		}
+		project.ImageSets = make([]models.ImageSet, len(project.Images))
+		for i, url := range project.Images {
+			project.ImageSets[i] = imageSet(url)
+		}
+	}
+	return nil
+}
+
+// attachImageList es attachImages para un slice de proyectos
+func (h *Handler) attachImageList(ctx context.Context, projects []models.Project) error {
+	ptrs := make([]*models.Project, len(projects))
+	for i := range projects {
+		ptrs[i] = &projects[i]
+	}
+	return h.attachImages(ctx, ptrs...)
+}
+
+// uploadID devuelve el id del upload de url, si url es la de un upload
+func (h *Handler) uploadID(url string) (string, bool) {
+	id, ok := strings.CutPrefix(url, strings.TrimRight(h.UploadBaseURL, "/")+"/")
+	return id, ok && uploads.ValidID(id)
+}
+
+// checkUploads controla que cada url de field sea la de un upload de alguno de owners. Las que ya
+// estaban guardadas (kept) se aceptan igual, para no romper las imagenes cargadas antes de /uploads.
+func (h *Handler) checkUploads(
+	ctx context.Context, errs filterErrors, field string, urls, kept []string, owners ...int64,
+) error {
+	for _, url := range urls {
+		if url == "" || slices.Contains(kept, url) {
+			continue
+		}
+		id, ok := h.uploadID(url)
+		if !ok {
+			errs.add(field, "%s must use URLs returned by POST /uploads", field)
+			continue
+		}
+		upload, err := h.Uploads.FindByID(ctx, id)
+		if errors.Is(err, repositories.ErrNotFound) || err == nil && !slices.Contains(owners, upload.UserID) {
+			errs.add(field, "upload %s does not exist", id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadOwners son los usuarios cuyos uploads se pueden usar en algo de ownerID: el dueño y quien
+// hace el pedido (un admin que edita un proyecto ajeno)
+func uploadOwners(r *http.Request, ownerID int64) []int64 {
+	owners := []int64{ownerID}
+	if user := currentUser(r); user != nil && user.ID != ownerID {
+		owners = append(owners, user.ID)
+	}
+	return owners
+}
+
+// validProjectImages controla el portrait y las imagenes de project; previous es el proyecto antes
+// de editarlo, o nil al crearlo. Responde 400 con los errores por campo.
+func (h *Handler) validProjectImages(
+	w http.ResponseWriter, r *http.Request, project, previous *models.Project,
+) bool {
+	var kept []string
+	if previous != nil {
+		kept = append([]string{previous.Portrait}, previous.Images...)
+	}
+	owners := uploadOwners(r, project.Owner)
+	errs := filterErrors{}
+	err := h.checkUploads(r.Context(), errs, "portrait", []string{project.Portrait}, kept, owners...)
+	if err == nil {
+		err = h.checkUploads(r.Context(), errs, "images", project.Images, kept, owners...)
+	}
+	if err != nil {
+		log.Printf("Error checking project images: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to save the project"})
+		return false
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]any{"message": "Invalid images", "errors": errs})
+		return false
+	}
+	return true
+}
@@ -3,59 +3,56 @@ package routes
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strconv"
 
-	"github.com/carpentry-hub/woodys-backend/db"
+	"github.com/carpentry-hub/woodys-backend/middlewares"
 	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/pagination"
+	"github.com/carpentry-hub/woodys-backend/policies"
+	"github.com/carpentry-hub/woodys-backend/repositories"
 	"github.com/gorilla/mux"
 )
 
 // GetUser obtiene un usuario - Requiere id
-func GetUser(w http.ResponseWriter, r *http.Request) {
-	var user models.User
-	params := mux.Vars(r)
-	db.DB.First(&user, params["id"])
-	if user.ID == 0 {
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	var user *models.User
+	if err == nil {
+		user, err = h.Users.FindByID(r.Context(), id)
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		if _, err := w.Write([]byte("404: User Not Found")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
 		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&user); err != nil {
-			log.Fatalf("Failed to encode: %v", err)
-		}
+		return
 	}
+	h.writeUser(w, r, user)
 }
 
 // GetUserByUID obtiene un usuario con firebase_uid - Requiere firebase_uid
-func GetUserByUID(w http.ResponseWriter, r *http.Request) {
-	var user models.User
-	params := mux.Vars(r)
-	uid := params["firebase_uid"]
-
-	db.DB.Where("firebase_uid = ?", uid).First(&user)
+func (h *Handler) GetUserByUID(w http.ResponseWriter, r *http.Request) {
+	uid := mux.Vars(r)["firebase_uid"]
 
-	if user.ID == 0 {
+	user, err := h.Users.FindByFirebaseUID(r.Context(), uid)
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		if _, err := w.Write([]byte("404: User Not Found")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
 		}
-	} else {
-		if err := json.NewEncoder(w).Encode(map[string]int8{"id": user.ID}); err != nil {
-			log.Fatalf("Failed to encode: %v", err)
-		}
+		return
+	}
+	if err := json.NewEncoder(w).Encode(map[string]int64{"id": user.ID}); err != nil {
+		log.Fatalf("Failed to encode: %v", err)
 	}
 }
 
 // GetUserProjects obtiene lista de todos los proyectos de un usuario - Requiere id
-func GetUserProjects(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-	userIDString := params["id"]
-
+func (h *Handler) GetUserProjects(w http.ResponseWriter, r *http.Request) {
 	// chequeo existencia del usuario
-	userID, err := strconv.Atoi(userIDString) // cambio de str a int para evitar errores
+	userID, err := pathID(r, "id")
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		if _, err := w.Write([]byte("User not found")); err != nil {
@@ -64,9 +61,14 @@ func GetUserProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := h.parsePage(w, r, userProjectsPage)
+	if !ok {
+		return
+	}
+
 	// realizacion de la query y manejo de errores
-	var projects []models.Project
-	if err := db.DB.Where("owner = ?", userID).Find(&projects).Error; err != nil {
+	projects, total, err := h.Projects.ListByOwner(r.Context(), userID, page)
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := w.Write([]byte("Error fetching projects")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
@@ -74,40 +76,57 @@ func GetUserProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&projects); err != nil {
-		log.Fatalf("Failed to encode: %v", err)
+	if err := h.attachImageList(r.Context(), projects); err != nil {
+		log.Printf("Error loading project images: %v", err)
 	}
+	writePage(w, pagination.NewPage(h.Cursors, page, projects, total, projectKey(page.Order)))
 }
 
-// PostUser postea un usuario
-func PostUser(w http.ResponseWriter, r *http.Request) {
-	var user models.User
+// PostUser postea un usuario - El firebase_uid se toma del token verificado
+func (h *Handler) PostUser(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middlewares.ClaimsFromContext(r.Context())
+	if !ok {
+		authorize(w, policies.ErrUnauthenticated)
+		return
+	}
 
+	var user models.User
 	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		log.Fatalf("Failed to decode: %v", err)
+		w.WriteHeader(http.StatusBadRequest) // status code 400
+		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid JSON format"})
+		return
+	}
+	user.FirebaseUID = claims.Subject
+	user.IsAdmin = false
+	// la foto propia solo se cambia con PUT /users/{id}/avatar
+	user.AvatarUpload = nil
+	if claims.Email != "" {
+		user.Email = claims.Email
 	}
 
-	createdUser := db.DB.Create(&user)
-	err := createdUser.Error
-	if err != nil {
+	if !h.validProfilePicture(w, r, user.ProfilePicture, 0) {
+		return
+	}
+
+	if err := h.Users.Create(r.Context(), &user); err != nil {
 		w.WriteHeader(http.StatusBadRequest) // status code 400
 		if _, err := w.Write([]byte(err.Error())); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
 		}
-	} else {
-		if err := json.NewEncoder(w).Encode(&user); err != nil {
-			log.Fatalf("Failed to encode: %v", err)
-		}
+		return
 	}
+	h.writeUser(w, r, &user)
 }
 
 // PutUser actualiza un usuario - Requiere id
-func PutUser(w http.ResponseWriter, r *http.Request) {
-	params := mux.Vars(r)
-
+func (h *Handler) PutUser(w http.ResponseWriter, r *http.Request) {
 	// chqueo que el usuario exista
-	var existing models.User
-	if err := db.DB.First(&existing, params["id"]).Error; err != nil {
+	id, err := pathID(r, "id")
+	var existing *models.User
+	if err == nil {
+		existing, err = h.Users.FindByID(r.Context(), id)
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound) // status code 404
 		if _, err := w.Write([]byte("User Not Found")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
@@ -115,6 +134,10 @@ func PutUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorize(w, policies.ManageUser(currentUser(r), existing)) {
+		return
+	}
+
 	// lee el usuario updated
 	var updated models.User
 	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
@@ -128,10 +151,13 @@ func PutUser(w http.ResponseWriter, r *http.Request) {
 	// actualizar campos
 	existing.Username = updated.Username
 	existing.Reputation = updated.Reputation
+	if !h.validProfilePicture(w, r, updated.ProfilePicture, existing.ProfilePicture) {
+		return
+	}
 	existing.ProfilePicture = updated.ProfilePicture
 
 	// guardar en DB
-	if err := db.DB.Save(&existing).Error; err != nil {
+	if err := h.Users.Update(r.Context(), existing); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := w.Write([]byte("Failed to save the user")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
@@ -139,23 +165,32 @@ func PutUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(&existing); err != nil {
-		log.Fatalf("Failed to encode: %v", err)
-	}
+	h.writeUser(w, r, existing)
 }
 
 // DeleteUser borra un usuario - Requiere id
-func DeleteUser(w http.ResponseWriter, r *http.Request) {
-	var user models.User
-	params := mux.Vars(r)
-	db.DB.First(&user, params["id"])
-
-	if user.ID == 0 {
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	var user *models.User
+	if err == nil {
+		user, err = h.Users.FindByID(r.Context(), id)
+	}
+	if err != nil {
 		w.WriteHeader(http.StatusNotFound) // status code 404
 		if _, err := w.Write([]byte("User Not Found")); err != nil {
 			log.Fatalf("Failed to write Response: %v", err)
 		}
-	} else {
-		db.DB.Unscoped().Delete(&user)
+		return
+	}
+
+	if !authorize(w, policies.ManageUser(currentUser(r), user)) {
+		return
+	}
+
+	if err := h.Users.Delete(r.Context(), user.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete the user"})
+		return
 	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "User deleted successfully"})
 }
@@ -0,0 +1,100 @@
+package shopping
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV escribe la lista como CSV, un item por fila con su seccion. Los proyectos van separados
+// por espacios.
+func WriteCSV(w io.Writer, list List) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{
+		"section", "name", "pieces", "board_feet", "cubic_metres", "projects", "unmeasured_projects", "owned",
+	})
+	for _, item := range list.items() {
+		row := []string{item.Section, item.Name, "", "", "", ids(item.Projects), ids(item.Unmeasured), strconv.FormatBool(item.Owned)}
+		if item.Volume != nil {
+			row[2] = strconv.Itoa(item.Pieces)
+			row[3] = strconv.FormatFloat(item.Volume.BoardFeet, 'f', -1, 64)
+			row[4] = strconv.FormatFloat(item.Volume.CubicMetres, 'f', -1, 64)
+		}
+		out.Write(row)
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteText escribe la lista para imprimir, con una casilla por item
+func WriteText(w io.Writer, list List) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Shopping list: %s\n", list.Name)
+	fmt.Fprintf(&b, "%d %s\n", list.Projects, plural(list.Projects, "project", "projects"))
+
+	sections := []struct {
+		title string
+		items []Item
+		mark  string
+	}{
+		{"TOOLS", list.Tools, "[ ]"},
+		{"MATERIALS", list.Materials, "[ ]"},
+		{"HARDWARE", list.Hardware, "[ ]"},
+		{"ALREADY IN YOUR INVENTORY", list.Owned, "[x]"},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", section.title)
+		for _, item := range section.items {
+			fmt.Fprintf(&b, "%s %s%s\n", section.mark, item.Name, describe(item))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// items devuelve todos los items en el orden de las secciones
+func (l List) items() []Item {
+	var out []Item
+	for _, section := range [][]Item{l.Tools, l.Materials, l.Hardware, l.Owned} {
+		out = append(out, section...)
+	}
+	return out
+}
+
+// describe es el detalle de un item en el texto: lo que miden las piezas o cuantos proyectos lo piden
+func describe(item Item) string {
+	if item.Volume == nil {
+		if n := len(item.Projects); n > 1 {
+			return fmt.Sprintf(" (%d projects)", n)
+		}
+		return ""
+	}
+	text := fmt.Sprintf(": %d %s, %s board feet / %s m³", item.Pieces, plural(item.Pieces, "piece", "pieces"),
+		strconv.FormatFloat(item.Volume.BoardFeet, 'f', -1, 64),
+		strconv.FormatFloat(item.Volume.CubicMetres, 'f', -1, 64))
+	if n := len(item.Unmeasured); n > 0 {
+		text += fmt.Sprintf(", plus what %d %s without a parts list need%s", n,
+			plural(n, "project", "projects"), plural(n, "s", ""))
+	}
+	return text
+}
+
+func ids(values []int64) string {
+	out := make([]string, len(values))
+	for i, id := range values {
+		out[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(out, " ")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
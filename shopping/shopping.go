@@ -0,0 +1,182 @@
+// Package shopping arma la lista de compras de una lista de proyectos: junta las herramientas,
+// materiales y herrajes de todos los proyectos, suma lo que miden sus listas de piezas y aparta lo
+// que el dueño ya tiene en su inventario.
+package shopping
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/carpentry-hub/woodys-backend/cutlist"
+	"github.com/carpentry-hub/woodys-backend/inventory"
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// Secciones de la lista de compras
+const (
+	Tools     = "tools"
+	Materials = "materials"
+	Hardware  = "hardware"
+)
+
+// Item es una herramienta, material o herraje que piden uno o mas proyectos de la lista
+type Item struct {
+	Name    string `json:"name"`
+	Section string `json:"section"`
+	// Projects son los proyectos que lo piden
+	Projects []int64 `json:"projects"`
+	// Pieces y Volume suman las listas de piezas de los proyectos que las tienen; Unmeasured son los
+	// proyectos que piden el material pero no tienen piezas de el
+	Pieces     int             `json:"pieces,omitempty"`
+	Volume     *cutlist.Volume `json:"volume,omitempty"`
+	Unmeasured []int64         `json:"unmeasured_projects,omitempty"`
+	Owned      bool            `json:"owned"`
+
+	// key es la clave del item en la taxonomia (inventory.Key)
+	key string
+}
+
+// List es la lista de compras. Owned son los items que ya estan en el inventario del dueño, que no
+// aparecen en las demas secciones.
+type List struct {
+	ListID           int64  `json:"list_id"`
+	Name             string `json:"name"`
+	Projects         int    `json:"projects"`
+	InventoryApplied bool   `json:"inventory_applied"`
+	Tools            []Item `json:"tools"`
+	Materials        []Item `json:"materials"`
+	Hardware         []Item `json:"hardware"`
+	Owned            []Item `json:"owned"`
+}
+
+// Input son los datos de la lista de compras
+type Input struct {
+	Projects []models.Project
+	Parts    []models.ProjectPart
+	// Inventory es el inventario del dueño de la lista; nil si no se conoce
+	Inventory []models.InventoryItem
+	// IsHardware indica si un material es un herraje (tornillos, bisagras)
+	IsHardware func(material string) bool
+	// Index es el indice de la taxonomia con que se resuelven los nombres
+	Index inventory.Resolver
+}
+
+// Build arma la lista de compras. Dos nombres con la misma clave en la taxonomia (inventory.Key) son
+// el mismo item y se muestra el primero que aparece. Cada seccion queda ordenada por nombre.
+func Build(list models.ProjectList, in Input) List {
+	out := List{
+		ListID:    list.ID,
+		Name:      list.Name,
+		Projects:  len(in.Projects),
+		Tools:     []Item{},
+		Materials: []Item{},
+		Hardware:  []Item{},
+		Owned:     []Item{},
+	}
+
+	var items []*Item
+	index := map[string]*Item{}
+	add := func(section, name string, projectID int64) *Item {
+		kind := models.TaxonomyTool
+		if section != Tools {
+			kind = models.TaxonomyMaterial
+		}
+		key := inventory.Key(in.Index, kind, name)
+		if key == "" {
+			return nil
+		}
+		item, ok := index[kind+":"+key]
+		if !ok {
+			if section != Tools && in.IsHardware != nil && in.IsHardware(name) {
+				section = Hardware
+			}
+			item = &Item{Name: name, Section: section, key: key}
+			index[kind+":"+key] = item
+			items = append(items, item)
+		}
+		if !slices.Contains(item.Projects, projectID) {
+			item.Projects = append(item.Projects, projectID)
+		}
+		return item
+	}
+
+	projects := append([]models.Project(nil), in.Projects...)
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	for _, project := range projects {
+		for _, tool := range project.Tools {
+			add(Tools, tool, project.ID)
+		}
+		for _, material := range append([]string{project.MainMaterial}, project.Materials...) {
+			add(Materials, material, project.ID)
+		}
+	}
+
+	// measured va de item a los proyectos que tienen piezas de ese material
+	measured := map[*Item]map[int64]bool{}
+	for _, part := range in.Parts {
+		item := add(Materials, part.Material, part.ProjectID)
+		if item == nil {
+			continue
+		}
+		if measured[item] == nil {
+			measured[item] = map[int64]bool{}
+			item.Volume = &cutlist.Volume{}
+		}
+		measured[item][part.ProjectID] = true
+		item.Pieces += part.Quantity
+		item.Volume.BoardFeet += cutlist.BoardFeet(part)
+		item.Volume.CubicMetres += cutlist.CubicMetres(part)
+	}
+
+	var tools, materials inventory.Set
+	if in.Inventory != nil {
+		out.InventoryApplied = true
+		var toolNames, materialNames []string
+		for _, owned := range in.Inventory {
+			if owned.Kind == models.InventoryTool {
+				toolNames = append(toolNames, owned.Name)
+			} else {
+				materialNames = append(materialNames, owned.Name)
+			}
+		}
+		tools = inventory.NewSet(in.Index, models.TaxonomyTool, toolNames...)
+		materials = inventory.NewSet(in.Index, models.TaxonomyMaterial, materialNames...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	for _, item := range items {
+		sort.Slice(item.Projects, func(i, j int) bool { return item.Projects[i] < item.Projects[j] })
+		if item.Volume != nil {
+			item.Volume.BoardFeet = round(item.Volume.BoardFeet, 3)
+			item.Volume.CubicMetres = round(item.Volume.CubicMetres, 6)
+			for _, id := range item.Projects {
+				if !measured[item][id] {
+					item.Unmeasured = append(item.Unmeasured, id)
+				}
+			}
+		}
+
+		owned := materials
+		if item.Section == Tools {
+			owned = tools
+		}
+		item.Owned = owned[item.key]
+		switch {
+		case item.Owned:
+			out.Owned = append(out.Owned, *item)
+		case item.Section == Tools:
+			out.Tools = append(out.Tools, *item)
+		case item.Section == Hardware:
+			out.Hardware = append(out.Hardware, *item)
+		default:
+			out.Materials = append(out.Materials, *item)
+		}
+	}
+	return out
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
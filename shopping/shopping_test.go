@@ -0,0 +1,142 @@
+package shopping
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/cutlist"
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/taxonomy"
+)
+
+func TestBuildResolvesThroughTaxonomy(t *testing.T) {
+	powerTools := "power-tools"
+	index := taxonomy.NewIndex([]models.TaxonomyTerm{
+		{Kind: models.TaxonomyTool, Slug: powerTools, LabelES: "Herramientas electricas", LabelEN: "Power tools"},
+		{Kind: models.TaxonomyTool, Slug: "jigsaw", Parent: &powerTools, LabelES: "Sierra caladora", LabelEN: "Jigsaw",
+			Synonyms: []string{"calador"}},
+		{Kind: models.TaxonomyTool, Slug: "drill", Parent: &powerTools, LabelES: "Taladro", LabelEN: "Drill"},
+		{Kind: models.TaxonomyMaterial, Slug: "pine", LabelES: "Pino", LabelEN: "Pine"},
+	})
+	list := Build(models.ProjectList{ID: 1, Name: "Finde"}, Input{
+		Projects: []models.Project{
+			{ID: 1, Tools: []string{"jigsaw", "drill"}, Materials: []string{"pine"}},
+			{ID: 2, Tools: []string{"Taladro", "power-tools"}, Materials: []string{"Pinos"}},
+		},
+		// "calador" es un sinonimo agregado a la taxonomia
+		Inventory: []models.InventoryItem{{Kind: models.InventoryTool, Name: "Calador"}},
+		Index:     index,
+	})
+
+	owned := map[string]bool{}
+	for _, item := range list.Owned {
+		owned[item.Name] = true
+	}
+	if len(list.Owned) != 2 || !owned["jigsaw"] || !owned["power-tools"] {
+		t.Errorf("owned = %+v, want jigsaw and power-tools (its parent)", list.Owned)
+	}
+	if len(list.Tools) != 1 || list.Tools[0].Name != "drill" || len(list.Tools[0].Projects) != 2 {
+		t.Errorf("tools = %+v, want drill once for both projects", list.Tools)
+	}
+	if len(list.Materials) != 1 || list.Materials[0].Name != "pine" || len(list.Materials[0].Projects) != 2 {
+		t.Errorf("materials = %+v, want pine once for both projects", list.Materials)
+	}
+}
+
+// testList es una lista de tres proyectos: el 1 y el 2 con piezas de pino, el 3 pide pino sin lista
+// de piezas, y el 1 usa ademas terciado medido y tornillos, que cuelgan de herrajes
+func testList(t *testing.T) List {
+	t.Helper()
+	hardware := "hardware"
+	index := taxonomy.NewIndex([]models.TaxonomyTerm{
+		{Kind: models.TaxonomyTool, Slug: "drill", LabelES: "Taladro", LabelEN: "Drill"},
+		{Kind: models.TaxonomyMaterial, Slug: "pine", LabelES: "Pino", LabelEN: "Pine"},
+		{Kind: models.TaxonomyMaterial, Slug: "plywood", LabelES: "Terciado", LabelEN: "Plywood"},
+		{Kind: models.TaxonomyMaterial, Slug: hardware, LabelES: "Herrajes", LabelEN: "Hardware"},
+		{Kind: models.TaxonomyMaterial, Slug: "screws", Parent: &hardware, LabelES: "Tornillos", LabelEN: "Screws"},
+	})
+	part := func(projectID int64, material string, quantity int, thickness, width, length float64) models.ProjectPart {
+		return models.ProjectPart{ProjectID: projectID, Name: "pieza", Material: material, Quantity: quantity,
+			ThicknessMM: thickness, WidthMM: width, LengthMM: length}
+	}
+	return Build(models.ProjectList{ID: 7, Name: "Finde"}, Input{
+		Projects: []models.Project{
+			{ID: 3, MainMaterial: "pine"},
+			{ID: 1, Tools: []string{"drill"}, MainMaterial: "pine", Materials: []string{"plywood", "screws"}},
+			{ID: 2, Tools: []string{"Taladro"}, Materials: []string{"Pino"}},
+		},
+		Parts: []models.ProjectPart{
+			part(1, "pine", 2, 18, 200, 900),
+			part(2, "pine", 1, 18, 300, 600),
+			part(1, "plywood", 1, 15, 400, 500),
+		},
+		IsHardware: func(material string) bool {
+			slug, ok := index.Resolve(models.TaxonomyMaterial, material)
+			return ok && slices.Contains(index.Ancestors(models.TaxonomyMaterial, slug), hardware)
+		},
+		Index: index,
+	})
+}
+
+func TestBuildSumsParts(t *testing.T) {
+	list := testList(t)
+	want := List{
+		ListID: 7, Name: "Finde", Projects: 3,
+		Tools: []Item{{Name: "drill", Section: Tools, Projects: []int64{1, 2}, key: "drill"}},
+		Materials: []Item{
+			{Name: "pine", Section: Materials, Projects: []int64{1, 2, 3}, Pieces: 3,
+				Volume: &cutlist.Volume{BoardFeet: 4.119, CubicMetres: 0.00972}, Unmeasured: []int64{3}, key: "pine"},
+			{Name: "plywood", Section: Materials, Projects: []int64{1}, Pieces: 1,
+				Volume: &cutlist.Volume{BoardFeet: 1.271, CubicMetres: 0.003}, key: "plywood"},
+		},
+		Hardware: []Item{{Name: "screws", Section: Hardware, Projects: []int64{1}, key: "screws"}},
+		Owned:    []Item{},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("list = %+v\nwant %+v", list, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteCSV(&out, testList(t)); err != nil {
+		t.Fatal(err)
+	}
+	want := "section,name,pieces,board_feet,cubic_metres,projects,unmeasured_projects,owned\n" +
+		"tools,drill,,,,1 2,,false\n" +
+		"materials,pine,3,4.119,0.00972,1 2 3,3,false\n" +
+		"materials,plywood,1,1.271,0.003,1,,false\n" +
+		"hardware,screws,,,,1,,false\n"
+	if out.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	list := testList(t)
+	list.Owned = []Item{list.Tools[0]}
+	list.Tools = []Item{}
+
+	var out bytes.Buffer
+	if err := WriteText(&out, list); err != nil {
+		t.Fatal(err)
+	}
+	want := `Shopping list: Finde
+3 projects
+
+MATERIALS
+[ ] pine: 3 pieces, 4.119 board feet / 0.00972 m³, plus what 1 project without a parts list needs
+[ ] plywood: 1 piece, 1.271 board feet / 0.003 m³
+
+HARDWARE
+[ ] screws
+
+ALREADY IN YOUR INVENTORY
+[x] drill (2 projects)
+`
+	if out.String() != want {
+		t.Errorf("text =\n%s\nwant\n%s", out.String(), want)
+	}
+}
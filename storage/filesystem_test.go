@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// traversalKeys son claves que intentan salir de la raiz o que no se pueden usar igual en S3
+var traversalKeys = []string{
+	"", ".", "..", "../secret", "../../secret", "a/../../secret", "a/..", "./a", "/secret", "/etc/passwd",
+	"a//b", "a/", `..\secret`, `a\..\..\secret`, "A/b", "a/b c", "a/%2e%2e/secret", "a/\x00",
+}
+
+func TestFilesystemRejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("no tocar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewFilesystem(filepath.Join(dir, "root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range traversalKeys {
+		if err := s.Put(ctx, key, []byte("pisado"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded", key)
+		}
+		if file, err := s.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			if file != nil {
+				file.Close()
+			}
+			t.Errorf("Get(%q) err = %v, want an invalid key error", key, err)
+		}
+		if err := s.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded", key)
+		}
+	}
+
+	if data, err := os.ReadFile(secret); err != nil || string(data) != "no tocar" {
+		t.Errorf("file outside the root = %q, %v; want it untouched", data, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("%d entries next to the root, want only root and secret", len(entries))
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "root")); len(entries) != 0 {
+		t.Errorf("root has %d entries after invalid keys, want none", len(entries))
+	}
+}
+
+func TestFilesystemRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewFilesystem(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	key := "ab12/original"
+
+	if err := s.Put(ctx, key, []byte("primera"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(ctx, key, []byte("segunda"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readObject(t, s, key); got != "segunda" {
+		t.Errorf("Get = %q, want the last Put", got)
+	}
+	// el temporal de Put no queda al lado del archivo
+	if entries, _ := os.ReadDir(filepath.Join(root, "ab12")); len(entries) != 1 {
+		t.Errorf("%d files in the key directory, want 1", len(entries))
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete err = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing key = %v, want nil", err)
+	}
+}
+
+// readObject lee entero el objeto de key
+func readObject(t *testing.T, s Storage, key string) string {
+	t.Helper()
+	file, err := s.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,43 @@
+// Package storage guarda los archivos subidos a la api detras de una interfaz, con una
+// implementacion en disco (Filesystem) y otra sobre cualquier servicio compatible con S3 (S3).
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound se devuelve cuando no hay ningun archivo con la clave pedida
+var ErrNotFound = errors.New("object not found")
+
+// Storage guarda archivos por clave. Las claves son rutas relativas con "/" como separador, por
+// ejemplo "abc123/original"; ValidKey dice cuales se aceptan.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get abre el archivo; quien lo llama lo tiene que cerrar
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete borra el archivo; borrar una clave que no existe no es un error
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidKey controla que key sea una ruta relativa de segmentos con letras minusculas, numeros, ".",
+// "-" y "_", sin segmentos vacios, "." ni "..". Asi la misma clave sirve en disco y en S3.
+func ValidKey(key string) error {
+	if key == "" || len(key) > 512 {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+		for _, c := range segment {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+				return fmt.Errorf("invalid storage key %q", key)
+			}
+		}
+	}
+	return nil
+}
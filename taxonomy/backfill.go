@@ -0,0 +1,101 @@
+package taxonomy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/carpentry-hub/woodys-backend/repositories"
+)
+
+// backfillBatch es la cantidad de proyectos que Backfill lee por consulta
+const backfillBatch = 500
+
+// BackfillReport resume una corrida de Backfill
+type BackfillReport struct {
+	Scanned int
+	Changed int
+	// Unmapped cuenta, por campo, en cuantos proyectos aparece cada valor que no esta en la taxonomia
+	Unmapped map[string]map[string]int
+}
+
+// Backfill pasa las herramientas, materiales, estilos y ambientes de todos los proyectos a slugs de
+// la taxonomia. Los valores que no se reconocen quedan como estan y se cuentan en el reporte, para
+// agregarlos como termino o sinonimo y volver a correrlo. Cada proyecto que cambia suma una revision
+// sin autor, con la retencion de revision. Con dryRun no escribe nada.
+func Backfill(
+	ctx context.Context, projects repositories.ProjectRepository, index *Index, revision repositories.Revision,
+	dryRun bool,
+) (*BackfillReport, error) {
+	report := &BackfillReport{Unmapped: map[string]map[string]int{}}
+	var after int64
+	for {
+		batch, err := projects.ListAfterID(ctx, after, backfillBatch)
+		if err != nil {
+			return report, err
+		}
+		if len(batch) == 0 {
+			return report, nil
+		}
+		for i := range batch {
+			project := &batch[i]
+			before := *project
+			for field, values := range index.CanonicalizeProject(project, true) {
+				if report.Unmapped[field] == nil {
+					report.Unmapped[field] = map[string]int{}
+				}
+				for _, value := range values {
+					report.Unmapped[field][value]++
+				}
+			}
+			report.Scanned++
+
+			if slices.Equal(before.Tools, project.Tools) && slices.Equal(before.Materials, project.Materials) &&
+				slices.Equal(before.Style, project.Style) && before.MainMaterial == project.MainMaterial &&
+				before.Environment == project.Environment {
+				continue
+			}
+			report.Changed++
+			if !dryRun {
+				if err := projects.UpdateTaxonomy(ctx, project, revision); err != nil {
+					return report, fmt.Errorf("project %d: %w", project.ID, err)
+				}
+			}
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+// Write imprime el reporte: totales y, por campo, los valores sin mapear de mas a menos frecuentes
+func (r *BackfillReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "projects scanned: %d\nprojects changed: %d\n", r.Scanned, r.Changed)
+	if len(r.Unmapped) == 0 {
+		fmt.Fprintln(w, "every value maps to a taxonomy term")
+		return
+	}
+
+	fields := make([]string, 0, len(r.Unmapped))
+	for field := range r.Unmapped {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		values := make([]string, 0, len(r.Unmapped[field]))
+		for value := range r.Unmapped[field] {
+			values = append(values, value)
+		}
+		counts := r.Unmapped[field]
+		sort.Slice(values, func(i, j int) bool {
+			if counts[values[i]] != counts[values[j]] {
+				return counts[values[i]] > counts[values[j]]
+			}
+			return values[i] < values[j]
+		})
+		fmt.Fprintf(w, "unmapped %s:\n", field)
+		for _, value := range values {
+			fmt.Fprintf(w, "  %6d  %q\n", counts[value], value)
+		}
+	}
+}
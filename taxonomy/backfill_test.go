@@ -0,0 +1,57 @@
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/repositories/memory"
+)
+
+// TestBackfillRecordsRevisions controla que el backfill deje en el historial una revision sin autor
+// por cada proyecto que cambia, y ninguna en los que ya estaban en slugs
+func TestBackfillRecordsRevisions(t *testing.T) {
+	ctx := context.Background()
+	repos, _ := memory.New()
+	owner := models.User{FirebaseUID: "owner", Username: "owner"}
+	if err := repos.Users.Create(ctx, &owner); err != nil {
+		t.Fatal(err)
+	}
+	index := NewIndex([]models.TaxonomyTerm{
+		{Kind: models.TaxonomyMaterial, Slug: "pine", LabelES: "Pino", LabelEN: "Pine"},
+	})
+	create := func(materials ...string) *models.Project {
+		project := models.Project{Owner: owner.ID, Title: "Estante", Materials: materials}
+		if err := repos.Projects.Create(ctx, &project, nil, repositories.Revision{AuthorID: &owner.ID}); err != nil {
+			t.Fatal(err)
+		}
+		return &project
+	}
+	legacy := create("Pino", "Roble")
+	migrated := create("pine")
+
+	report, err := Backfill(ctx, repos.Projects, index, repositories.Revision{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Scanned != 2 || report.Changed != 1 || report.Unmapped["materials"]["Roble"] != 1 {
+		t.Errorf("report = %+v, want 2 scanned, 1 changed and Roble unmapped", report)
+	}
+
+	revision, err := repos.Revisions.FindByNumber(ctx, legacy.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision.AuthorID != nil || !slices.Equal([]string(revision.Changed), []string{"materials"}) {
+		t.Errorf("revision 2 = %+v, want materials changed by nobody", revision)
+	}
+	if !slices.Equal([]string(revision.Snapshot.Materials), []string{"pine", "Roble"}) {
+		t.Errorf("revision 2 materials = %q, want [pine Roble]", revision.Snapshot.Materials)
+	}
+	if _, err := repos.Revisions.FindByNumber(ctx, migrated.ID, 2); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("unchanged project got a revision: %v", err)
+	}
+}
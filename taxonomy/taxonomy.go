@@ -0,0 +1,166 @@
+// Package taxonomy resuelve los nombres libres de herramientas, materiales, estilos y ambientes a
+// los slugs canonicos de la taxonomia.
+package taxonomy
+
+import (
+	"github.com/carpentry-hub/woodys-backend/inventory"
+	"github.com/carpentry-hub/woodys-backend/models"
+)
+
+// Index busca terminos por slug, etiqueta o sinonimo. Se arma una vez con todos los terminos y es
+// de solo lectura, asi se puede compartir entre requests.
+type Index struct {
+	terms map[string][]models.TaxonomyTerm
+	// names va de tipo y nombre normalizado (inventory.Fold) al slug
+	names map[string]map[string]string
+	// slugs va de tipo y slug al termino
+	slugs map[string]map[string]models.TaxonomyTerm
+}
+
+// NewIndex arma el indice. Si dos terminos de un mismo tipo comparten un nombre gana el primero.
+func NewIndex(terms []models.TaxonomyTerm) *Index {
+	idx := &Index{
+		terms: map[string][]models.TaxonomyTerm{},
+		names: map[string]map[string]string{},
+		slugs: map[string]map[string]models.TaxonomyTerm{},
+	}
+	for _, term := range terms {
+		if idx.names[term.Kind] == nil {
+			idx.names[term.Kind] = map[string]string{}
+			idx.slugs[term.Kind] = map[string]models.TaxonomyTerm{}
+		}
+		idx.terms[term.Kind] = append(idx.terms[term.Kind], term)
+		idx.slugs[term.Kind][term.Slug] = term
+	}
+	// primero los slugs, asi un sinonimo nunca tapa el slug de otro termino
+	for _, term := range terms {
+		idx.add(term.Kind, term.Slug, term.Slug)
+	}
+	for _, term := range terms {
+		for _, name := range Names(term) {
+			idx.add(term.Kind, name, term.Slug)
+		}
+	}
+	return idx
+}
+
+func (idx *Index) add(kind, name, slug string) {
+	key := inventory.Fold(name)
+	if _, taken := idx.names[kind][key]; key != "" && !taken {
+		idx.names[kind][key] = slug
+	}
+}
+
+// Names son los nombres con los que se reconoce un termino, sin contar el slug
+func Names(term models.TaxonomyTerm) []string {
+	return append([]string{term.LabelES, term.LabelEN}, term.Synonyms...)
+}
+
+// Terms devuelve los terminos de un tipo en el orden en que se armo el indice
+func (idx *Index) Terms(kind string) []models.TaxonomyTerm {
+	return idx.terms[kind]
+}
+
+// Managed indica si el tipo tiene terminos. Un tipo sin terminos no se valida: los valores quedan
+// como los escribio el usuario.
+func (idx *Index) Managed(kind string) bool {
+	return len(idx.terms[kind]) > 0
+}
+
+// Term devuelve el termino con ese slug
+func (idx *Index) Term(kind, slug string) (models.TaxonomyTerm, bool) {
+	term, ok := idx.slugs[kind][slug]
+	return term, ok
+}
+
+// Resolve devuelve el slug del termino que corresponde a un nombre libre
+func (idx *Index) Resolve(kind, name string) (string, bool) {
+	if _, ok := idx.slugs[kind][name]; ok {
+		return name, true
+	}
+	slug, ok := idx.names[kind][inventory.Fold(name)]
+	return slug, ok
+}
+
+// Lookup devuelve el slug que usa otro termino con el mismo nombre normalizado, o "" si el
+// nombre esta libre
+func (idx *Index) Lookup(kind, name string) string {
+	return idx.names[kind][inventory.Fold(name)]
+}
+
+// Canonicalize reemplaza cada valor por su slug, sin repetidos y en el orden original. Los valores
+// vacios se descartan y los que no estan en la taxonomia se devuelven aparte, tal como vinieron.
+// Si el tipo no tiene terminos solo se sacan los vacios y los repetidos.
+func (idx *Index) Canonicalize(kind string, values []string) (slugs, unknown []string) {
+	return idx.canonicalize(kind, values, false)
+}
+
+// canonicalize es Canonicalize; con keep los valores desconocidos tambien quedan en slugs
+func (idx *Index) canonicalize(kind string, values []string, keep bool) (slugs, unknown []string) {
+	slugs = make([]string, 0, len(values))
+	seen := map[string]bool{}
+	for _, value := range values {
+		if inventory.Fold(value) == "" {
+			continue
+		}
+		slug := value
+		if idx.Managed(kind) {
+			var ok bool
+			if slug, ok = idx.Resolve(kind, value); !ok {
+				unknown = append(unknown, value)
+				if !keep {
+					continue
+				}
+				slug = value
+			}
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs, unknown
+}
+
+// Unknown son los valores de un proyecto que no estan en la taxonomia, por campo (tools,
+// materials, style, main_material, environment)
+type Unknown map[string][]string
+
+// CanonicalizeProject pasa herramientas, materiales, estilos, material principal y ambiente del
+// proyecto a slugs. Con keep los valores desconocidos quedan en el proyecto; si no, se sacan.
+func (idx *Index) CanonicalizeProject(project *models.Project, keep bool) Unknown {
+	unknown := Unknown{}
+	list := func(field, kind string, values []string) []string {
+		slugs, missing := idx.canonicalize(kind, values, keep)
+		if len(missing) > 0 {
+			unknown[field] = missing
+		}
+		return slugs
+	}
+	single := func(field, kind, value string) string {
+		if slugs := list(field, kind, []string{value}); len(slugs) > 0 {
+			return slugs[0]
+		}
+		return ""
+	}
+
+	project.Tools = list("tools", models.TaxonomyTool, project.Tools)
+	project.Materials = list("materials", models.TaxonomyMaterial, project.Materials)
+	project.Style = list("style", models.TaxonomyStyle, project.Style)
+	project.MainMaterial = single("main_material", models.TaxonomyMaterial, project.MainMaterial)
+	project.Environment = single("environment", models.TaxonomyEnvironment, project.Environment)
+	return unknown
+}
+
+// Ancestors devuelve los slugs de los padres de un termino, del mas cercano al mas lejano
+func (idx *Index) Ancestors(kind, slug string) []string {
+	var out []string
+	seen := map[string]bool{slug: true}
+	term, ok := idx.slugs[kind][slug]
+	for ok && term.Parent != nil && !seen[*term.Parent] {
+		seen[*term.Parent] = true
+		out = append(out, *term.Parent)
+		term, ok = idx.slugs[kind][*term.Parent]
+	}
+	return out
+}
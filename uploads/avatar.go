@@ -0,0 +1,49 @@
+package uploads
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/draw"
+)
+
+// AvatarSize es el lado en pixeles de los avatares; los originales mas chicos no se agrandan
+const AvatarSize = 512
+
+// Avatar prepara una foto de perfil: la recorta al cuadrado del centro y la reduce a AvatarSize.
+// Sale en JPEG, o en PNG si tiene transparencia.
+func Avatar(data []byte) (*Image, error) {
+	prepared, err := Prepare(data)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decode(prepared.Data, prepared.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := decoded.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	offset := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+	draw.Draw(square, square.Bounds(), decoded, offset, draw.Src)
+	size := min(AvatarSize, side)
+	if size < side {
+		square = resize(square, size, size)
+	}
+
+	avatar := &Image{ContentType: JPEG, Width: size, Height: size}
+	format := JPEGFormat
+	if !square.Opaque() {
+		avatar.ContentType, format = PNG, PNGFormat
+	}
+	var out bytes.Buffer
+	if err := format.Encode(&out, square); err != nil {
+		return nil, err
+	}
+	avatar.Data = out.Bytes()
+	sum := sha256.Sum256(avatar.Data)
+	avatar.SHA256 = hex.EncodeToString(sum[:])
+	return avatar, nil
+}
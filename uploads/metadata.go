@@ -0,0 +1,241 @@
+package uploads
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// stripJPEG copia los segmentos de data salvo APP1 (EXIF y XMP), APP13 (IPTC) y los comentarios.
+// Deja APP0 (JFIF), APP2 (perfil de color) y APP14 (Adobe), que cambian como se ven los colores.
+// Devuelve tambien la orientacion EXIF (1 si no habia).
+func stripJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, ErrCorrupt
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	orientation := 1
+	for i := 2; ; {
+		if i >= len(data) || data[i] != 0xFF {
+			return nil, 0, ErrCorrupt
+		}
+		// un marcador puede venir precedido de bytes de relleno 0xFF
+		for i < len(data) && data[i] == 0xFF {
+			i++
+		}
+		if i >= len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		marker := data[i]
+		i++
+		switch {
+		case marker == 0xD9: // EOI
+			return append(out, 0xFF, 0xD9), orientation, nil
+		case marker == 0x01 || marker >= 0xD0 && marker <= 0xD7: // marcadores sin longitud
+			out = append(out, 0xFF, marker)
+			continue
+		}
+		if i+2 > len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		length := int(data[i])<<8 | int(data[i+1])
+		if length < 2 || i+length > len(data) {
+			return nil, 0, ErrCorrupt
+		}
+		switch marker {
+		case 0xE1:
+			if o := exifOrientation(data[i+2 : i+length]); o > 0 {
+				orientation = o
+			}
+		case 0xED, 0xFE:
+		case 0xDA: // SOS: lo que sigue son los datos comprimidos y se copia tal cual
+			out = append(out, 0xFF, marker)
+			return append(out, data[i:]...), orientation, nil
+		default:
+			out = append(out, 0xFF, marker)
+			out = append(out, data[i:i+length]...)
+		}
+		i += length
+	}
+}
+
+// exifOrientation lee la etiqueta Orientation (0x0112) del IFD0 de un segmento APP1; 0 si no esta
+func exifOrientation(segment []byte) int {
+	if !bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+		return 0
+	}
+	tiff := segment[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// pngDropped son los chunks de PNG con metadatos: EXIF, textos y fecha de modificacion
+var pngDropped = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// stripPNG copia los chunks de data salvo los de metadatos. Cada chunk tiene su propio CRC, asi que
+// los que quedan no cambian.
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, ErrCorrupt
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, signature...)
+	for i := len(signature); ; {
+		if i+12 > len(data) {
+			return nil, ErrCorrupt
+		}
+		length := binary.BigEndian.Uint32(data[i:])
+		if uint64(length) > uint64(len(data)-i-12) {
+			return nil, ErrCorrupt
+		}
+		end := i + 12 + int(length)
+		kind := string(data[i+4 : i+8])
+		if !pngDropped[kind] {
+			out = append(out, data[i:end]...)
+		}
+		if kind == "IEND" {
+			return out, nil
+		}
+		i = end
+	}
+}
+
+// stripWebP copia los chunks del contenedor RIFF salvo EXIF y XMP, apaga sus banderas en VP8X y
+// corrige el tamaño del RIFF. Devuelve tambien las dimensiones, que el paquete image no lee de WebP.
+func stripWebP(data []byte) ([]byte, int, int, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, 0, 0, ErrCorrupt
+	}
+	if size := binary.LittleEndian.Uint32(data[4:8]); uint64(size)+8 <= uint64(len(data)) {
+		data = data[:size+8]
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	width, height, vp8x := 0, 0, -1
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, 0, 0, ErrCorrupt
+		}
+		kind := string(data[i : i+4])
+		length := binary.LittleEndian.Uint32(data[i+4:])
+		if uint64(length) > uint64(len(data)-i-8) {
+			return nil, 0, 0, ErrCorrupt
+		}
+		payload := data[i+8 : i+8+int(length)]
+		// los chunks de largo impar llevan un byte de relleno
+		end := min(i+8+int(length)+int(length&1), len(data))
+
+		switch kind {
+		case "EXIF", "XMP ":
+			i = end
+			continue
+		case "VP8X":
+			if len(payload) < 10 {
+				return nil, 0, 0, ErrCorrupt
+			}
+			vp8x = len(out) + 8
+			width, height = 1+uint24(payload[4:]), 1+uint24(payload[7:])
+		case "VP8 ":
+			if width == 0 {
+				if len(payload) < 10 || !bytes.Equal(payload[3:6], []byte{0x9d, 0x01, 0x2a}) {
+					return nil, 0, 0, ErrCorrupt
+				}
+				width = int(binary.LittleEndian.Uint16(payload[6:]) & 0x3fff)
+				height = int(binary.LittleEndian.Uint16(payload[8:]) & 0x3fff)
+			}
+		case "VP8L":
+			if width == 0 {
+				if len(payload) < 5 || payload[0] != 0x2f {
+					return nil, 0, 0, ErrCorrupt
+				}
+				bits := binary.LittleEndian.Uint32(payload[1:])
+				width, height = int(bits&0x3fff)+1, int(bits>>14&0x3fff)+1
+			}
+		}
+		out = append(out, data[i:end]...)
+		i = end
+	}
+	if width <= 0 || height <= 0 {
+		return nil, 0, 0, ErrCorrupt
+	}
+	if vp8x >= 0 {
+		out[vp8x] &^= 0x08 | 0x04 // banderas de EXIF y XMP
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, width, height, nil
+}
+
+func uint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+// Orient devuelve src rotada o espejada para que se vea derecha segun la orientacion EXIF (1 a 8)
+func Orient(src image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // espejo horizontal
+				dx, dy = w-1-x, y
+			case 3: // 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // espejo vertical
+				dx, dy = x, h-1-y
+			case 5: // transpuesta
+				dx, dy = y, x
+			case 6: // 90° en sentido horario
+				dx, dy = h-1-y, x
+			case 7: // transversa
+				dx, dy = h-1-y, w-1-x
+			case 8: // 90° en sentido antihorario
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], rgba.Pix[rgba.PixOffset(x, y):rgba.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,168 @@
+package uploads
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+)
+
+// Placeholder es lo que se muestra mientras carga una imagen: un BlurHash (https://blurha.sh) y el
+// color dominante, para los clientes que no decodifican BlurHash
+type Placeholder struct {
+	Blurhash      string
+	DominantColor string
+}
+
+// Componentes del BlurHash: 4 a lo ancho y 3 a lo alto dan un hash de 28 caracteres
+const (
+	blurhashX = 4
+	blurhashY = 3
+)
+
+// placeholderWidth es el ancho al que se reduce la imagen antes de calcular el placeholder; el
+// resultado es casi igual y no depende del tamaño del original
+const placeholderWidth = 32
+
+func placeholder(src *image.RGBA) Placeholder {
+	width := min(placeholderWidth, src.Rect.Dx())
+	height := max(1, (src.Rect.Dy()*width+src.Rect.Dx()/2)/src.Rect.Dx())
+	small := src
+	if width < src.Rect.Dx() {
+		small = resize(src, width, height)
+	} else {
+		height = src.Rect.Dy()
+	}
+	pixels := make([][3]float64, 0, width*height)
+	for i := 0; i < len(small.Pix); i += 4 {
+		// lo transparente se ve sobre fondo blanco; Pix tiene alfa premultiplicado
+		white := 255 - float64(small.Pix[i+3])
+		pixels = append(pixels, [3]float64{
+			float64(small.Pix[i]) + white, float64(small.Pix[i+1]) + white, float64(small.Pix[i+2]) + white,
+		})
+	}
+	return Placeholder{
+		Blurhash:      blurhash(pixels, width, height),
+		DominantColor: dominantColor(pixels),
+	}
+}
+
+// blurhash codifica los pixeles sRGB (0-255) de una imagen de width x height segun el algoritmo de
+// referencia de BlurHash
+func blurhash(pixels [][3]float64, width, height int) string {
+	linear := make([][3]float64, len(pixels))
+	for i, p := range pixels {
+		linear[i] = [3]float64{srgbToLinear(p[0]), srgbToLinear(p[1]), srgbToLinear(p[2])}
+	}
+	factors := make([][3]float64, 0, blurhashX*blurhashY)
+	for j := 0; j < blurhashY; j++ {
+		for i := 0; i < blurhashX; i++ {
+			normalisation := 2.0
+			if i == 0 && j == 0 {
+				normalisation = 1
+			}
+			var factor [3]float64
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					basis := normalisation *
+						math.Cos(math.Pi*float64(i)*float64(x)/float64(width)) *
+						math.Cos(math.Pi*float64(j)*float64(y)/float64(height))
+					p := linear[y*width+x]
+					factor[0] += basis * p[0]
+					factor[1] += basis * p[1]
+					factor[2] += basis * p[2]
+				}
+			}
+			scale := 1 / float64(width*height)
+			factors = append(factors, [3]float64{factor[0] * scale, factor[1] * scale, factor[2] * scale})
+		}
+	}
+
+	var hash strings.Builder
+	hash.WriteString(base83((blurhashX-1)+(blurhashY-1)*9, 1))
+	maximum := 1.0
+	if ac := factors[1:]; len(ac) > 0 {
+		actual := 0.0
+		for _, f := range ac {
+			actual = max(actual, math.Abs(f[0]), math.Abs(f[1]), math.Abs(f[2]))
+		}
+		quantised := int(max(0, min(82, math.Floor(actual*166-0.5))))
+		maximum = float64(quantised+1) / 166
+		hash.WriteString(base83(quantised, 1))
+	} else {
+		hash.WriteString(base83(0, 1))
+	}
+	dc := factors[0]
+	hash.WriteString(base83(linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4))
+	for _, f := range factors[1:] {
+		quant := func(v float64) int {
+			return int(max(0, min(18, math.Floor(signPow(v/maximum, 0.5)*9+9.5))))
+		}
+		hash.WriteString(base83(quant(f[0])*19*19+quant(f[1])*19+quant(f[2]), 2))
+	}
+	return hash.String()
+}
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+func base83(value, length int) string {
+	out := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		out[i] = base83Chars[value%83]
+		value /= 83
+	}
+	return string(out)
+}
+
+func srgbToLinear(v float64) float64 {
+	v /= 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = max(0, min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
+
+// dominantColor agrupa los pixeles en 4096 colores (4 bits por canal) y devuelve el promedio del
+// grupo mas numeroso, como "#rrggbb". A diferencia del promedio de toda la imagen, no da un gris
+// cuando hay dos colores fuertes.
+func dominantColor(pixels [][3]float64) string {
+	type bucket struct {
+		count int
+		sum   [3]float64
+	}
+	buckets := map[int]*bucket{}
+	var best *bucket
+	for _, p := range pixels {
+		key := int(p[0])>>4<<8 | int(p[1])>>4<<4 | int(p[2])>>4
+		b := buckets[key]
+		if b == nil {
+			b = &bucket{}
+			buckets[key] = b
+		}
+		b.count++
+		b.sum[0] += p[0]
+		b.sum[1] += p[1]
+		b.sum[2] += p[2]
+		if best == nil || b.count > best.count {
+			best = b
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	n := float64(best.count)
+	return fmt.Sprintf("#%02x%02x%02x",
+		int(best.sum[0]/n+0.5), int(best.sum[1]/n+0.5), int(best.sum[2]/n+0.5))
+}
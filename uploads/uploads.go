@@ -0,0 +1,159 @@
+// Package uploads prepara las imagenes que suben los usuarios antes de guardarlas: reconoce el
+// formato por el contenido y no por lo que declara el cliente, saca los metadatos (EXIF, XMP, IPTC,
+// comentarios) sin recomprimir y lee las dimensiones.
+package uploads
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/jpeg"
+	_ "image/png" // registra el decodificador de PNG para image.DecodeConfig
+	"net/http"
+	"strings"
+)
+
+// Formatos aceptados
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	WebP = "image/webp"
+)
+
+// MaxPixels es el tamaño maximo de una imagen (ancho por alto). Acota la memoria que lleva
+// decodificarla, sea para rotarla o para generar miniaturas.
+const MaxPixels = 50_000_000
+
+var (
+	// ErrUnsupportedType se devuelve cuando el contenido no es JPEG, PNG ni WebP
+	ErrUnsupportedType = errors.New("only JPEG, PNG and WebP images are accepted")
+	// ErrCorrupt se devuelve cuando el archivo no se puede leer como la imagen que dice ser
+	ErrCorrupt = errors.New("the image is corrupt or truncated")
+	// ErrTooManyPixels se devuelve cuando la imagen pasa de MaxPixels
+	ErrTooManyPixels = errors.New("the image has too many pixels")
+)
+
+// Image es una imagen lista para guardar
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+	// SHA256 es el hash en hexa de Data
+	SHA256 string
+}
+
+// Prepare reconoce el formato de data, le saca los metadatos y lee sus dimensiones. Una foto JPEG
+// con orientacion EXIF se rota antes, porque sin el EXIF se veria de costado.
+func Prepare(data []byte) (*Image, error) {
+	img := &Image{ContentType: sniff(data)}
+	var err error
+	switch img.ContentType {
+	case JPEG:
+		var orientation int
+		if img.Data, orientation, err = stripJPEG(data); err != nil {
+			return nil, err
+		}
+		if err := img.readConfig(); err != nil {
+			return nil, err
+		}
+		if orientation > 1 {
+			if err := img.applyOrientation(orientation); err != nil {
+				return nil, err
+			}
+		}
+	case PNG:
+		if img.Data, err = stripPNG(data); err != nil {
+			return nil, err
+		}
+		if err := img.readConfig(); err != nil {
+			return nil, err
+		}
+	case WebP:
+		if img.Data, img.Width, img.Height, err = stripWebP(data); err != nil {
+			return nil, err
+		}
+		if img.Width*img.Height > MaxPixels {
+			return nil, ErrTooManyPixels
+		}
+	default:
+		return nil, ErrUnsupportedType
+	}
+	sum := sha256.Sum256(img.Data)
+	img.SHA256 = hex.EncodeToString(sum[:])
+	return img, nil
+}
+
+// sniff devuelve el tipo de contenido de data segun sus primeros bytes
+func sniff(data []byte) string {
+	contentType := http.DetectContentType(data)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return contentType
+}
+
+func (img *Image) readConfig() error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(img.Data))
+	if err != nil {
+		return ErrCorrupt
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return ErrCorrupt
+	}
+	if config.Width*config.Height > MaxPixels {
+		return ErrTooManyPixels
+	}
+	img.Width, img.Height = config.Width, config.Height
+	return nil
+}
+
+// applyOrientation rota o espeja la imagen segun la orientacion EXIF y la vuelve a comprimir
+func (img *Image) applyOrientation(orientation int) error {
+	decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		return ErrCorrupt
+	}
+	oriented := Orient(decoded, orientation)
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, oriented, &jpeg.Options{Quality: 90}); err != nil {
+		return err
+	}
+	img.Data = out.Bytes()
+	img.Width, img.Height = oriented.Bounds().Dx(), oriented.Bounds().Dy()
+	return nil
+}
+
+// NewID genera el id de un upload: 26 caracteres en base32 en minusculas, al azar
+func NewID() string {
+	var raw [16]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		panic(err)
+	}
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw[:]))
+}
+
+// ValidID indica si id tiene la forma de los que genera NewID
+func ValidID(id string) bool {
+	if len(id) != 26 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= '2' && c <= '7') {
+			return false
+		}
+	}
+	return true
+}
+
+// OriginalName es el nombre del archivo original de cada upload en el storage
+const OriginalName = "original"
+
+// Key es la clave de almacenamiento del archivo name de un upload: el original o una variante
+func Key(id, name string) string {
+	return id + "/" + name
+}
@@ -0,0 +1,174 @@
+package uploads
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/webp"
+)
+
+// Widths son los anchos en pixeles de las variantes. No se agranda: una imagen de 800px de ancho
+// tiene variantes de 160 y 480, y el original hace de la mas grande.
+var Widths = []int{160, 480, 1200}
+
+// ErrNoDecoder se devuelve cuando no hay con que decodificar el formato del original
+var ErrNoDecoder = errors.New("no decoder for the image format")
+
+// Format es un formato en el que se codifican las variantes
+type Format struct {
+	Ext         string
+	ContentType string
+	// Alpha indica si el formato guarda transparencia; si no, las imagenes con transparencia usan PNG
+	Alpha  bool
+	Encode func(w io.Writer, img image.Image) error
+}
+
+// Formatos de las variantes. Las WebP salen sin perdida (nativewebp solo escribe VP8L): en fotos
+// suelen pesar mas que la JPEG y en imagenes planas o con transparencia bastante menos.
+// models.Upload.ImageSet ofrece primero el tipo mas liviano.
+var (
+	JPEGFormat = Format{Ext: "jpg", ContentType: JPEG, Encode: func(w io.Writer, img image.Image) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 82})
+	}}
+	PNGFormat = Format{Ext: "png", ContentType: PNG, Alpha: true, Encode: func(w io.Writer, img image.Image) error {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		return encoder.Encode(w, img)
+	}}
+	WebPFormat = Format{Ext: "webp", ContentType: WebP, Alpha: true, Encode: func(w io.Writer, img image.Image) error {
+		return nativewebp.Encode(w, img, nil)
+	}}
+	DefaultFormats = []Format{JPEGFormat, WebPFormat}
+)
+
+// Variant es una version reducida lista para guardar
+type Variant struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Variants genera las variantes de la imagen data en cada formato de formats y su placeholder
+func Variants(data []byte, contentType string, formats []Format) ([]Variant, Placeholder, error) {
+	decoded, err := decode(data, contentType)
+	if err != nil {
+		return nil, Placeholder{}, err
+	}
+	bounds := decoded.Bounds()
+	if bounds.Dx()*bounds.Dy() > MaxPixels {
+		return nil, Placeholder{}, ErrTooManyPixels
+	}
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), decoded, bounds.Min, draw.Src)
+	opaque := src.Opaque()
+
+	var variants []Variant
+	for _, width := range Widths {
+		if width >= src.Rect.Dx() {
+			break
+		}
+		height := max(1, (src.Rect.Dy()*width+src.Rect.Dx()/2)/src.Rect.Dx())
+		resized := resize(src, width, height)
+		done := map[string]bool{}
+		for _, format := range formats {
+			if !opaque && !format.Alpha {
+				format = PNGFormat
+			}
+			if done[format.Ext] {
+				continue
+			}
+			done[format.Ext] = true
+			var out bytes.Buffer
+			if err := format.Encode(&out, resized); err != nil {
+				return nil, Placeholder{}, err
+			}
+			variants = append(variants, Variant{
+				Name:        fmt.Sprintf("w%d.%s", width, format.Ext),
+				Width:       width,
+				Height:      height,
+				ContentType: format.ContentType,
+				Data:        out.Bytes(),
+			})
+		}
+	}
+	return variants, placeholder(src), nil
+}
+
+// decode decodifica una imagen JPEG, PNG o WebP; ErrNoDecoder para otro tipo y ErrCorrupt si no se
+// puede leer
+func decode(data []byte, contentType string) (image.Image, error) {
+	var decoded image.Image
+	var err error
+	switch contentType {
+	case JPEG:
+		decoded, err = jpeg.Decode(bytes.NewReader(data))
+	case PNG:
+		decoded, err = png.Decode(bytes.NewReader(data))
+	case WebP:
+		decoded, err = webp.Decode(bytes.NewReader(data))
+	default:
+		return nil, ErrNoDecoder
+	}
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return decoded, nil
+}
+
+// resize reduce src a width x height promediando el area de cada pixel de destino (box filter).
+// Cada fila de destino junta las filas de origen que cubre, ya reducidas a lo ancho, asi la memoria
+// extra es de una fila. Trabaja con alfa premultiplicado, asi los bordes transparentes no se oscurecen.
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	columns := make([][]span, width)
+	for x := range columns {
+		columns[x] = coverage(float64(x)*float64(sw)/float64(width), float64(x+1)*float64(sw)/float64(width), sw)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	acc := make([]float64, width*4)
+	for y := 0; y < height; y++ {
+		clear(acc)
+		for _, r := range coverage(float64(y)*float64(sh)/float64(height), float64(y+1)*float64(sh)/float64(height), sh) {
+			row := src.Pix[r.index*src.Stride:]
+			for x, spans := range columns {
+				for _, s := range spans {
+					weight := s.weight * r.weight
+					for c := 0; c < 4; c++ {
+						acc[x*4+c] += float64(row[s.index*4+c]) * weight
+					}
+				}
+			}
+		}
+		for i, v := range acc {
+			dst.Pix[y*dst.Stride+i] = uint8(min(255, v+0.5))
+		}
+	}
+	return dst
+}
+
+type span struct {
+	index  int
+	weight float64
+}
+
+// coverage son los pixeles de origen que cubre el intervalo [from, to) y cuanto de cada uno,
+// normalizado para que los pesos sumen 1
+func coverage(from, to float64, limit int) []span {
+	var spans []span
+	total := to - from
+	for i := int(from); i < limit && float64(i) < to; i++ {
+		overlap := min(to, float64(i+1)) - max(from, float64(i))
+		if overlap > 0 {
+			spans = append(spans, span{index: i, weight: overlap / total})
+		}
+	}
+	return spans
+}
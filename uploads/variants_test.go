@@ -0,0 +1,143 @@
+package uploads
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"testing"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/webp"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories/memory"
+	"github.com/carpentry-hub/woodys-backend/storage"
+)
+
+// testImage es una imagen de width x height con un degradado; si alpha, la mitad derecha es
+// semitransparente
+func testImage(width, height int, alpha bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			a := uint8(255)
+			if alpha && x >= width/2 {
+				a = 128
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 90, A: a})
+		}
+	}
+	return img
+}
+
+func encodeWebP(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := nativewebp.Encode(&out, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestVariants(t *testing.T) {
+	var transparent bytes.Buffer
+	if err := png.Encode(&transparent, testImage(600, 300, true)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		want        []string
+	}{
+		{"webp original", encodeWebP(t, testImage(600, 300, false)), WebP,
+			[]string{"w160.jpg", "w160.webp", "w480.jpg", "w480.webp"}},
+		{"transparent png", transparent.Bytes(), PNG,
+			[]string{"w160.png", "w160.webp", "w480.png", "w480.webp"}},
+	}
+	for _, tt := range tests {
+		variants, placeholder, err := Variants(tt.data, tt.contentType, DefaultFormats)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var names []string
+		for _, variant := range variants {
+			names = append(names, variant.Name)
+			if variant.ContentType != WebP {
+				continue
+			}
+			decoded, err := webp.Decode(bytes.NewReader(variant.Data))
+			if err != nil {
+				t.Errorf("%s: %s does not decode: %v", tt.name, variant.Name, err)
+				continue
+			}
+			if size := decoded.Bounds().Size(); size.X != variant.Width || size.Y != variant.Height {
+				t.Errorf("%s: %s is %v, want %dx%d", tt.name, variant.Name, size, variant.Width, variant.Height)
+			}
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("%s: variants = %q, want %q", tt.name, names, tt.want)
+		}
+		if placeholder.Blurhash == "" || placeholder.DominantColor == "" {
+			t.Errorf("%s: placeholder = %+v, want a blurhash and a colour", tt.name, placeholder)
+		}
+	}
+
+	if _, _, err := Variants(encodeWebP(t, testImage(10, 10, false))[:20], WebP, DefaultFormats); err != ErrCorrupt {
+		t.Errorf("truncated webp err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestWorkerProcessesWebP(t *testing.T) {
+	ctx := context.Background()
+	repos, _ := memory.New()
+	store, err := storage.NewFilesystem(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{FirebaseUID: "maker", Username: "maker"}
+	if err := repos.Users.Create(ctx, &user); err != nil {
+		t.Fatal(err)
+	}
+	data := encodeWebP(t, testImage(600, 300, false))
+	upload := models.Upload{ID: "01webp", UserID: user.ID, ContentType: WebP, Size: int64(len(data)),
+		Width: 600, Height: 300}
+	if err := repos.Uploads.Create(ctx, &upload, 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, Key(upload.ID, OriginalName), data, WebP); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewWorker(repos.Uploads, store, DefaultFormats).Process(ctx, upload.ID); err != nil {
+		t.Fatal(err)
+	}
+	processed, err := repos.Uploads.FindByID(ctx, upload.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed.ProcessedAt == nil || processed.Blurhash == "" || len(processed.Variants) != 4 {
+		t.Fatalf("upload = %+v, want it processed with 4 variants and a placeholder", processed)
+	}
+	for _, variant := range processed.Variants {
+		file, err := store.Get(ctx, Key(upload.ID, variant.Name))
+		if err != nil {
+			t.Errorf("variant %s not stored: %v", variant.Name, err)
+			continue
+		}
+		file.Close()
+	}
+
+	// <picture> usa el primer <source> que entiende el navegador: el tipo mas liviano va primero
+	sizes := map[string]int64{}
+	for _, variant := range processed.Variants {
+		sizes[variant.ContentType] += variant.Size
+	}
+	sources := processed.ImageSet().Sources
+	if len(sources) != 2 || sizes[sources[0].Type] > sizes[sources[1].Type] {
+		t.Errorf("sources = %+v with sizes %v, want the lighter type first", sources, sizes)
+	}
+}
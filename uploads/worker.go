@@ -0,0 +1,183 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/carpentry-hub/woodys-backend/models"
+	"github.com/carpentry-hub/woodys-backend/repositories"
+	"github.com/carpentry-hub/woodys-backend/storage"
+)
+
+// rescanInterval es cada cuanto el worker busca uploads sin procesar: los que estaban antes de
+// arrancar, los que no entraron en la cola y los que fallaron por un error pasajero del storage
+const rescanInterval = time.Minute
+
+// queueSize es cuantos uploads pueden esperar en la cola; los que no entran los levanta el rescan
+const queueSize = 256
+
+// Worker genera en segundo plano las variantes y el placeholder de cada upload
+type Worker struct {
+	uploads repositories.UploadRepository
+	storage storage.Storage
+	formats []Format
+	queue   chan string
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// NewWorker crea el worker; no procesa nada hasta que se llama a Run
+func NewWorker(uploads repositories.UploadRepository, store storage.Storage, formats []Format) *Worker {
+	return &Worker{
+		uploads:  uploads,
+		storage:  store,
+		formats:  formats,
+		queue:    make(chan string, queueSize),
+		inFlight: map[string]bool{},
+	}
+}
+
+// Enqueue pide procesar el upload id sin esperar; si la cola esta llena lo toma el proximo rescan
+func (w *Worker) Enqueue(id string) {
+	select {
+	case w.queue <- id:
+	default:
+	}
+}
+
+// Run procesa la cola con workers goroutines hasta que se cancela ctx
+func (w *Worker) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for range max(1, workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-w.queue:
+					w.process(ctx, id)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(rescanInterval)
+	defer ticker.Stop()
+	for {
+		w.rescan(ctx)
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rescan encola los uploads sin procesar que entren en la cola
+func (w *Worker) rescan(ctx context.Context) {
+	pending, err := w.uploads.ListUnprocessed(ctx, queueSize)
+	if err != nil {
+		log.Printf("Error listing unprocessed uploads: %v", err)
+		return
+	}
+	for _, upload := range pending {
+		w.Enqueue(upload.ID)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, id string) {
+	w.mu.Lock()
+	if w.inFlight[id] {
+		w.mu.Unlock()
+		return
+	}
+	w.inFlight[id] = true
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.inFlight, id)
+		w.mu.Unlock()
+	}()
+
+	if err := w.Process(ctx, id); err != nil {
+		log.Printf("Error processing upload %s: %v", id, err)
+	}
+}
+
+// Process genera y guarda las variantes del upload id. Si el original falta o no se puede
+// decodificar el upload queda procesado sin variantes, para no reintentarlo; los errores del storage o de la base
+// se devuelven y el upload se reintenta en el proximo rescan.
+func (w *Worker) Process(ctx context.Context, id string) error {
+	upload, err := w.uploads.FindByID(ctx, id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if upload.ProcessedAt != nil {
+		return nil
+	}
+
+	body, err := w.storage.Get(ctx, Key(id, OriginalName))
+	if errors.Is(err, storage.ErrNotFound) {
+		return w.uploads.SaveVariants(ctx, id, nil, "", "")
+	}
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+
+	variants, placeholder, err := Variants(data, upload.ContentType, w.formats)
+	if errors.Is(err, ErrNoDecoder) || errors.Is(err, ErrCorrupt) || errors.Is(err, ErrTooManyPixels) {
+		return w.uploads.SaveVariants(ctx, id, nil, "", "")
+	}
+	if err != nil {
+		return err
+	}
+
+	saved := make([]models.UploadVariant, 0, len(variants))
+	for _, variant := range variants {
+		if err := w.storage.Put(ctx, Key(id, variant.Name), variant.Data, variant.ContentType); err != nil {
+			w.removeVariants(saved)
+			return err
+		}
+		saved = append(saved, models.UploadVariant{
+			UploadID:    id,
+			Name:        variant.Name,
+			Width:       variant.Width,
+			Height:      variant.Height,
+			ContentType: variant.ContentType,
+			Size:        int64(len(variant.Data)),
+		})
+	}
+	err = w.uploads.SaveVariants(ctx, id, saved, placeholder.Blurhash, placeholder.DominantColor)
+	if err != nil {
+		// si lo borraron mientras se procesaba, los archivos nuevos quedarian huerfanos
+		w.removeVariants(saved)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil
+		}
+	}
+	return err
+}
+
+func (w *Worker) removeVariants(variants []models.UploadVariant) {
+	for _, variant := range variants {
+		if err := w.storage.Delete(context.Background(), Key(variant.UploadID, variant.Name)); err != nil {
+			log.Printf("Error removing variant %s of upload %s: %v", variant.Name, variant.UploadID, err)
+		}
+	}
+}